@@ -3,16 +3,16 @@ package main
 import (
 	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/steipete/ordercli/internal/cli"
 )
 
 func run(args []string) int {
-	ctx := context.Background()
-	if err := cli.Run(ctx, args); err != nil {
-		return 1
-	}
-	return 0
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return cli.ExitCode(cli.Run(ctx, args))
 }
 
 func main() {
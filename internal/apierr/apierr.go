@@ -0,0 +1,168 @@
+// Package apierr classifies failed provider responses that need something
+// other than a plain retry: a bot-protection challenge, a firewall block, a
+// rate limit or an expired session. The provider clients wrap their HTTP
+// errors in an *Error so the CLI can tell the user what to do, while
+// errors.As still finds the provider's own error type underneath.
+package apierr
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/transport"
+)
+
+type Kind string
+
+const (
+	// Challenge: an interstitial (Cloudflare "Just a moment...", a
+	// PerimeterX captcha) that a real browser can pass.
+	Challenge Kind = "challenge"
+	// WAFBlock: a firewall rule rejected the request; a browser on the same
+	// network gets the same answer.
+	WAFBlock Kind = "waf_block"
+	// RateLimited: too many requests; waiting helps.
+	RateLimited Kind = "rate_limited"
+	// AuthExpired: the credentials sent were rejected.
+	AuthExpired Kind = "auth_expired"
+)
+
+// Vendors of the protection that answered.
+const (
+	Cloudflare = "Cloudflare"
+	PerimeterX = "PerimeterX"
+)
+
+// sniffLimit is how much of a body Classify looks at.
+const sniffLimit = 64 << 10
+
+type Error struct {
+	Kind     Kind
+	Provider string
+	Host     string
+	// Vendor is Cloudflare, PerimeterX or empty when unknown.
+	Vendor     string
+	StatusCode int
+	// RayID is Cloudflare's request id (cf-ray), useful in support requests.
+	RayID string
+	// RetryAfter is the wait the server asked for, if any.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	var what string
+	switch e.Kind {
+	case Challenge:
+		what = "blocked by a bot challenge"
+		if e.Vendor != "" {
+			what = "blocked by a " + e.Vendor + " challenge"
+		}
+	case WAFBlock:
+		what = "blocked by a firewall rule"
+		if e.Vendor != "" {
+			what = "blocked by a " + e.Vendor + " firewall rule"
+		}
+	case RateLimited:
+		what = "rate limited"
+	case AuthExpired:
+		what = "session expired or revoked"
+	}
+	details := []string{fmt.Sprintf("HTTP %d", e.StatusCode)}
+	if e.RayID != "" {
+		details = append(details, "ray "+e.RayID)
+	}
+	if e.RetryAfter > 0 {
+		details = append(details, "retry after "+e.RetryAfter.String())
+	}
+	msg := fmt.Sprintf("%s: %s at %s (%s)", e.Provider, what, e.Host, strings.Join(details, ", "))
+	// Challenge and block pages are HTML; only API errors are worth quoting.
+	if e.Kind == AuthExpired && e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Wrap returns err wrapped in an *Error when res (with the start of its
+// body) is one of the classified failures, else err unchanged. A 401 only
+// counts as AuthExpired when the request carried an Authorization header, so
+// a failed login stays a plain error. Cookies do not count: the stored
+// Cloudflare cookies go out with logins too.
+func Wrap(provider string, res *http.Response, body []byte, err error) error {
+	if res == nil {
+		return err
+	}
+	kind, vendor, ok := Classify(res.StatusCode, res.Header, body)
+	if !ok {
+		return err
+	}
+	if kind == AuthExpired && (res.Request == nil || res.Request.Header.Get("Authorization") == "") {
+		return err
+	}
+	e := &Error{
+		Kind:       kind,
+		Provider:   provider,
+		Vendor:     vendor,
+		StatusCode: res.StatusCode,
+		RayID:      res.Header.Get("Cf-Ray"),
+		Err:        err,
+	}
+	if res.Request != nil && res.Request.URL != nil {
+		e.Host = res.Request.URL.Hostname()
+	}
+	if d, ok := transport.RetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+		e.RetryAfter = d
+	}
+	return e
+}
+
+// Classify inspects a failed response. Cloudflare and PerimeterX pages are
+// recognised by their headers and markup; plain 429s and 401s by status.
+func Classify(status int, h http.Header, body []byte) (kind Kind, vendor string, ok bool) {
+	if status >= 200 && status < 300 {
+		return "", "", false
+	}
+	if len(body) > sniffLimit {
+		body = body[:sniffLimit]
+	}
+	page := string(bytes.ToLower(body))
+	has := func(markers ...string) bool {
+		for _, m := range markers {
+			if strings.Contains(page, m) {
+				return true
+			}
+		}
+		return false
+	}
+	cf := strings.EqualFold(h.Get("Server"), "cloudflare") || h.Get("Cf-Ray") != ""
+	html := strings.Contains(strings.ToLower(h.Get("Content-Type")), "text/html") || has("<html", "<!doctype html")
+
+	switch {
+	case strings.EqualFold(h.Get("Cf-Mitigated"), "challenge"):
+		return Challenge, Cloudflare, true
+	case cf && has("error code: 1015", "error code 1015", "you are being rate limited"):
+		return RateLimited, Cloudflare, true
+	case cf && has("error code: 1020", "error code 1020", "error code: 1010", "error code 1010", "error code: 1012", "error code 1012", "sorry, you have been blocked"):
+		return WAFBlock, Cloudflare, true
+	case has("challenge-platform", "cf_chl_", "cf-browser-verification", "<title>just a moment...</title>"):
+		return Challenge, Cloudflare, true
+	case has("px-captcha", "_pxappid", "perimeterx"):
+		return Challenge, PerimeterX, true
+	case status == http.StatusTooManyRequests && cf:
+		return RateLimited, Cloudflare, true
+	case status == http.StatusTooManyRequests:
+		return RateLimited, "", true
+	case cf && html && status == http.StatusForbidden:
+		return WAFBlock, Cloudflare, true
+	case status == http.StatusUnauthorized && !html:
+		return AuthExpired, "", true
+	case status == http.StatusForbidden && !html && has("expired", "invalid_token"):
+		return AuthExpired, "", true
+	}
+	return "", "", false
+}
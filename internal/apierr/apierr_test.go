@@ -0,0 +1,106 @@
+package apierr
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testHTTPError struct{ status int }
+
+func (e *testHTTPError) Error() string { return "HTTP error" }
+
+func TestClassify(t *testing.T) {
+	cf := http.Header{"Server": {"cloudflare"}, "Cf-Ray": {"8a1b2c3d4e5f-VIE"}, "Content-Type": {"text/html; charset=UTF-8"}}
+	cases := []struct {
+		name   string
+		status int
+		header http.Header
+		body   string
+		kind   Kind
+		vendor string
+		ok     bool
+	}{
+		{"cf managed challenge header", 403, http.Header{"Cf-Mitigated": {"challenge"}}, "", Challenge, Cloudflare, true},
+		{"cf just a moment", 403, cf, `<!DOCTYPE html><html><head><title>Just a moment...</title><script src="/cdn-cgi/challenge-platform/h/g/orchestrate/chl_page/v1"></script>`, Challenge, Cloudflare, true},
+		{"cf 1020", 403, cf, `<html><title>Access denied | mj.fd-api.com used Cloudflare to restrict access</title><span>Error code: 1020</span>`, WAFBlock, Cloudflare, true},
+		{"cf blocked", 403, cf, `<html><h1>Sorry, you have been blocked</h1>`, WAFBlock, Cloudflare, true},
+		{"cf unknown 403 page", 403, cf, `<html><body>Forbidden</body></html>`, WAFBlock, Cloudflare, true},
+		{"cf 1015", 429, cf, `<html><span>Error code: 1015</span> You are being rate limited`, RateLimited, Cloudflare, true},
+		{"plain 429", 429, http.Header{"Content-Type": {"application/json"}}, `{"error":"too_many_requests"}`, RateLimited, "", true},
+		{"perimeterx", 403, http.Header{"Content-Type": {"text/html"}}, `<div id="px-captcha"></div><script>window._pxAppId="PXabc"</script>`, Challenge, PerimeterX, true},
+		{"401 json", 401, http.Header{"Content-Type": {"application/json"}}, `{"code":"unauthorized"}`, AuthExpired, "", true},
+		{"403 expired token", 403, nil, `{"error":"token_expired"}`, AuthExpired, "", true},
+		{"403 other json", 403, nil, `{"error":"forbidden"}`, "", "", false},
+		{"500", 500, nil, `oops`, "", "", false},
+		{"200 with markers", 200, cf, `Just a moment...`, "", "", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			kind, vendor, ok := Classify(tc.status, tc.header, []byte(tc.body))
+			if kind != tc.kind || vendor != tc.vendor || ok != tc.ok {
+				t.Fatalf("got (%q, %q, %v), want (%q, %q, %v)", kind, vendor, ok, tc.kind, tc.vendor, tc.ok)
+			}
+		})
+	}
+}
+
+func response(status int, header http.Header, withAuth bool) *http.Response {
+	req, _ := http.NewRequest(http.MethodGet, "https://mj.fd-api.com/api/v5/orders", nil)
+	if withAuth {
+		req.Header.Set("Authorization", "Bearer access")
+	}
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: status, Header: header, Request: req}
+}
+
+func TestWrap(t *testing.T) {
+	herr := &testHTTPError{status: 429}
+	err := Wrap("foodora", response(429, http.Header{"Retry-After": {"30"}, "Cf-Ray": {"8a1b"}}, true), nil, herr)
+
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("want *Error, got %T", err)
+	}
+	if e.Kind != RateLimited || e.Host != "mj.fd-api.com" || e.RetryAfter != 30*time.Second || e.RayID != "8a1b" {
+		t.Fatalf("unexpected: %+v", e)
+	}
+	var inner *testHTTPError
+	if !errors.As(err, &inner) || inner != herr {
+		t.Fatalf("underlying error lost: %v", err)
+	}
+	if got := err.Error(); got != "foodora: rate limited at mj.fd-api.com (HTTP 429, ray 8a1b, retry after 30s)" {
+		t.Fatalf("message: %q", got)
+	}
+}
+
+func TestWrap_AuthExpiredNeedsCredentials(t *testing.T) {
+	herr := &testHTTPError{status: 401}
+	if err := Wrap("foodora", response(401, nil, false), []byte(`{"error":"invalid_grant"}`), herr); err != herr {
+		t.Fatalf("login failure must stay unwrapped, got %v", err)
+	}
+	withCookie := response(401, nil, false)
+	withCookie.Request.Header.Set("Cookie", "cf_clearance=x")
+	if err := Wrap("foodora", withCookie, []byte(`{"error":"invalid_grant"}`), herr); err != herr {
+		t.Fatalf("cookies alone are no session, got %v", err)
+	}
+	err := Wrap("glovo", response(401, nil, true), []byte(`{"error":"expired"}`), herr)
+	var e *Error
+	if !errors.As(err, &e) || e.Kind != AuthExpired {
+		t.Fatalf("want AuthExpired, got %v", err)
+	}
+	if !strings.HasSuffix(err.Error(), ": HTTP error") {
+		t.Fatalf("auth errors should quote the API error: %q", err.Error())
+	}
+}
+
+func TestWrap_Unclassified(t *testing.T) {
+	herr := &testHTTPError{status: 500}
+	if err := Wrap("deliveroo", response(500, nil, true), []byte("oops"), herr); err != herr {
+		t.Fatalf("want unchanged error, got %v", err)
+	}
+}
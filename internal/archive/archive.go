@@ -0,0 +1,185 @@
+// Package archive keeps a local copy of past orders from every provider, so
+// they stay queryable after the provider stops returning them.
+//
+// The archive is a single JSON file written atomically; each record keeps the
+// normalized order next to the provider's raw payload.
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+const fileVersion = 1
+
+// Record is one archived order.
+type Record struct {
+	provider.Order
+	// Account identifies which login the order was fetched with.
+	Account   string    `json:"account,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type file struct {
+	Version int               `json:"version"`
+	Orders  []Record          `json:"orders"`
+	Cursors map[string]Cursor `json:"cursors,omitempty"`
+}
+
+// Archive is safe for concurrent use.
+type Archive struct {
+	mu    sync.Mutex
+	path  string
+	data  file
+	index map[string]int
+	dirty bool
+}
+
+// DefaultPath is archive.json in the ordercli data directory.
+func DefaultPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "archive.json"), nil
+}
+
+// Open reads the archive at path. A missing file yields an empty archive.
+func Open(path string) (*Archive, error) {
+	a := &Archive{path: path, data: file{Version: fileVersion}, index: map[string]int{}}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return a, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &a.data); err != nil {
+		return nil, fmt.Errorf("archive %s: %w", path, err)
+	}
+	if a.data.Version > fileVersion {
+		return nil, fmt.Errorf("archive %s: unsupported version %d", path, a.data.Version)
+	}
+	a.data.Version = fileVersion
+	for i, r := range a.data.Orders {
+		a.index[key(r.Provider, r.ID)] = i
+	}
+	return a, nil
+}
+
+func (a *Archive) Path() string { return a.path }
+
+// Len is the number of archived orders.
+func (a *Archive) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.data.Orders)
+}
+
+// Upsert stores o, replacing an earlier copy of the same order. It reports
+// whether the order was new to the archive.
+func (a *Archive) Upsert(account string, o provider.Order, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.dirty = true
+	k := key(o.Provider, o.ID)
+	if i, ok := a.index[k]; ok {
+		r := &a.data.Orders[i]
+		raw := r.Raw
+		r.Order = o
+		if len(r.Raw) == 0 {
+			r.Raw = raw
+		}
+		if account != "" {
+			r.Account = account
+		}
+		r.LastSeen = now
+		return false
+	}
+	a.index[k] = len(a.data.Orders)
+	a.data.Orders = append(a.data.Orders, Record{Order: o, Account: account, FirstSeen: now, LastSeen: now})
+	return true
+}
+
+// Has reports whether the order is archived.
+func (a *Archive) Has(providerName, id string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.index[key(providerName, id)]
+	return ok
+}
+
+// Filter narrows Orders. Zero values match everything; Until is exclusive.
+type Filter struct {
+	Providers []string
+	Since     time.Time
+	Until     time.Time
+}
+
+// Orders returns matching records, newest first; undated orders go last.
+func (a *Archive) Orders(f Filter) []Record {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var out []Record
+	for _, r := range a.data.Orders {
+		if len(f.Providers) > 0 && !slices.Contains(f.Providers, r.Provider) {
+			continue
+		}
+		if !f.Since.IsZero() && (r.Time.IsZero() || r.Time.Before(f.Since)) {
+			continue
+		}
+		if !f.Until.IsZero() && (r.Time.IsZero() || !r.Time.Before(f.Until)) {
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ti, tj := out[i].Time, out[j].Time
+		if ti.IsZero() != tj.IsZero() {
+			return !ti.IsZero()
+		}
+		return ti.After(tj)
+	})
+	return out
+}
+
+// Save writes the archive if it changed since Open.
+func (a *Archive) Save() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o700); err != nil {
+		return err
+	}
+	// Not indented: MarshalIndent would also re-indent every raw payload.
+	b, err := json.Marshal(a.data)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, a.path); err != nil {
+		return err
+	}
+	a.dirty = false
+	return nil
+}
+
+func key(providerName, id string) string { return providerName + "/" + id }
@@ -0,0 +1,122 @@
+package archive
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func TestOpenMissingAndRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "archive.json")
+	a, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if a.Len() != 0 {
+		t.Fatalf("len=%d", a.Len())
+	}
+	if err := a.Save(); err != nil {
+		t.Fatalf("save clean: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("clean archive should not be written, stat err=%v", err)
+	}
+
+	now := time.Date(2025, 12, 20, 12, 0, 0, 0, time.UTC)
+	o := provider.Order{
+		Provider: provider.Foodora,
+		ID:       "A1",
+		Vendor:   "Pizza",
+		Total:    12.5,
+		Currency: "EUR",
+		Time:     now.Add(-time.Hour),
+		Raw:      json.RawMessage(`{"order_code":"A1"}`),
+	}
+	if !a.Upsert("acct", o, now) {
+		t.Fatalf("expected new")
+	}
+	if err := a.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if st.Mode().Perm() != 0o600 {
+		t.Fatalf("mode=%v", st.Mode().Perm())
+	}
+
+	b, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	recs := b.Orders(Filter{})
+	if len(recs) != 1 || recs[0].Vendor != "Pizza" || recs[0].Account != "acct" || string(recs[0].Raw) != `{"order_code":"A1"}` {
+		t.Fatalf("recs=%+v", recs)
+	}
+	if !recs[0].FirstSeen.Equal(now) || !b.Has(provider.Foodora, "A1") {
+		t.Fatalf("recs=%+v", recs)
+	}
+}
+
+func TestUpsertKeepsFirstSeenAndRaw(t *testing.T) {
+	a, _ := Open(filepath.Join(t.TempDir(), "a.json"))
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	a.Upsert("", provider.Order{Provider: "glovo", ID: "1", Status: "active", Raw: json.RawMessage(`{}`)}, t0)
+	if a.Upsert("", provider.Order{Provider: "glovo", ID: "1", Status: "delivered"}, t0.Add(time.Hour)) {
+		t.Fatalf("expected existing")
+	}
+	r := a.Orders(Filter{})[0]
+	if r.Status != "delivered" || string(r.Raw) != `{}` || !r.FirstSeen.Equal(t0) || !r.LastSeen.Equal(t0.Add(time.Hour)) {
+		t.Fatalf("r=%+v", r)
+	}
+}
+
+func TestOrdersFilterAndSort(t *testing.T) {
+	a, _ := Open(filepath.Join(t.TempDir(), "a.json"))
+	base := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	a.Upsert("", provider.Order{Provider: "foodora", ID: "old", Time: base}, base)
+	a.Upsert("", provider.Order{Provider: "glovo", ID: "new", Time: base.Add(48 * time.Hour)}, base)
+	a.Upsert("", provider.Order{Provider: "glovo", ID: "undated"}, base)
+
+	all := a.Orders(Filter{})
+	if len(all) != 3 || all[0].ID != "new" || all[1].ID != "old" || all[2].ID != "undated" {
+		t.Fatalf("all=%v", all)
+	}
+	if got := a.Orders(Filter{Providers: []string{"foodora"}}); len(got) != 1 || got[0].ID != "old" {
+		t.Fatalf("got=%v", got)
+	}
+	if got := a.Orders(Filter{Since: base.Add(time.Hour)}); len(got) != 1 || got[0].ID != "new" {
+		t.Fatalf("got=%v", got)
+	}
+	if got := a.Orders(Filter{Until: base.Add(48 * time.Hour)}); len(got) != 1 || got[0].ID != "old" {
+		t.Fatalf("got=%v", got)
+	}
+}
+
+func TestOpenRejectsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.json")
+	_ = os.WriteFile(bad, []byte("{"), 0o600)
+	if _, err := Open(bad); err == nil {
+		t.Fatalf("expected parse error")
+	}
+	future := filepath.Join(dir, "future.json")
+	_ = os.WriteFile(future, []byte(`{"version":99}`), 0o600)
+	if _, err := Open(future); err == nil {
+		t.Fatalf("expected version error")
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	p, err := DefaultPath()
+	if err != nil || p != filepath.Join(dir, "ordercli", "archive.json") {
+		t.Fatalf("p=%q err=%v", p, err)
+	}
+}
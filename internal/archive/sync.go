@@ -0,0 +1,178 @@
+package archive
+
+import (
+	"context"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// Cursor is the sync state of one provider account.
+//
+// LastID/LastTime are the high-water mark: the newest order archived so far
+// (foodora order code and time, Glovo/Deliveroo order id). An incremental
+// sync pages from the newest order down and stops once it reaches them.
+//
+// Resume is where an unfinished backfill of older orders continues: the
+// provider's opaque history cursor, i.e. the offset for foodora and
+// Deliveroo and Pagination.Next for Glovo. Complete is set once a backfill
+// reached the end of the history.
+type Cursor struct {
+	LastID   string    `json:"last_id,omitempty"`
+	LastTime time.Time `json:"last_time,omitzero"`
+	Resume   string    `json:"resume,omitempty"`
+	Complete bool      `json:"complete,omitempty"`
+	SyncedAt time.Time `json:"synced_at,omitzero"`
+}
+
+// Cursor returns the stored sync state for a provider account.
+func (a *Archive) Cursor(providerName, account string) Cursor {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.data.Cursors[key(providerName, account)]
+}
+
+func (a *Archive) setCursor(providerName, account string, c Cursor) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.data.Cursors == nil {
+		a.data.Cursors = map[string]Cursor{}
+	}
+	a.data.Cursors[key(providerName, account)] = c
+	a.dirty = true
+}
+
+type SyncOptions struct {
+	// Full ignores the stored cursor and rescans the whole history.
+	Full bool
+	// Limit caps how many orders are fetched; <= 0 means no cap. A capped
+	// backfill resumes on the next sync.
+	Limit    int
+	PageSize int
+	Now      func() time.Time
+}
+
+type SyncResult struct {
+	Fetched int
+	Added   int
+	// Incremental is true when the sync stopped at already archived orders.
+	Incremental bool
+}
+
+// Sync pages p's history into the archive and advances the account's cursor.
+// Orders fetched before an error are kept, and backfill progress is saved so
+// the next sync continues where this one stopped.
+func (a *Archive) Sync(ctx context.Context, p provider.Provider, account string, opts SyncOptions) (SyncResult, error) {
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	name := p.Name()
+	cur := a.Cursor(name, account)
+	if opts.Full {
+		cur = Cursor{}
+	}
+
+	var res SyncResult
+	var newest provider.Order
+	store := func(orders []provider.Order) {
+		for _, o := range orders {
+			res.Fetched++
+			if a.Upsert(account, o, now().UTC()) {
+				res.Added++
+			}
+			if newest.ID == "" || o.Time.After(newest.Time) {
+				newest = o
+			}
+		}
+	}
+	capped := func() bool { return opts.Limit > 0 && res.Fetched >= opts.Limit }
+	fetch := func(cursor string) (provider.HistoryPage, error) {
+		limit := pageSize
+		if opts.Limit > 0 {
+			limit = min(pageSize, opts.Limit-res.Fetched)
+		}
+		return p.History(ctx, provider.HistoryRequest{Cursor: cursor, Limit: limit})
+	}
+	done := func(page provider.HistoryPage, cursor string) bool {
+		return page.Next == "" || page.Next == cursor || len(page.Orders) == 0
+	}
+
+	// Head: newest orders until we reach the high-water mark.
+	if cur.LastID != "" {
+		cursor := ""
+		reached := false
+		for !reached && !capped() {
+			page, err := fetch(cursor)
+			if err != nil {
+				return res, err
+			}
+			for _, o := range page.Orders {
+				if cur.known(o) || a.Has(name, o.ID) {
+					reached = true
+				}
+			}
+			store(page.Orders)
+			if done(page, cursor) {
+				reached = true
+				break
+			}
+			cursor = page.Next
+		}
+		if !reached {
+			// Capped before closing the gap; keep the old mark so the
+			// next run fetches the rest.
+			return res, nil
+		}
+		res.Incremental = true
+		cur.advance(newest)
+	}
+
+	// Backfill: older orders a previous run has not reached yet.
+	if !cur.Complete {
+		cursor := cur.Resume
+		for !capped() {
+			page, err := fetch(cursor)
+			if err != nil {
+				cur.advance(newest)
+				a.setCursor(name, account, cur)
+				return res, err
+			}
+			store(page.Orders)
+			if done(page, cursor) {
+				cur.Complete = true
+				cur.Resume = ""
+				break
+			}
+			cursor = page.Next
+			cur.Resume = cursor
+		}
+		// advance only moves forward, so older backfill pages never lower it.
+		cur.advance(newest)
+	}
+
+	cur.SyncedAt = now().UTC()
+	a.setCursor(name, account, cur)
+	return res, nil
+}
+
+func (c Cursor) known(o provider.Order) bool {
+	if o.ID == c.LastID {
+		return true
+	}
+	return !c.LastTime.IsZero() && !o.Time.IsZero() && !o.Time.After(c.LastTime)
+}
+
+func (c *Cursor) advance(o provider.Order) {
+	if o.ID == "" {
+		return
+	}
+	if c.LastID == "" || o.Time.After(c.LastTime) {
+		c.LastID = o.ID
+		c.LastTime = o.Time
+	}
+}
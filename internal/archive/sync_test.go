@@ -0,0 +1,147 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// pagedProvider serves orders newest first with numeric offset cursors.
+type pagedProvider struct {
+	orders  []provider.Order
+	failAt  string
+	cursors []string
+}
+
+func (p *pagedProvider) Name() string                      { return "fake" }
+func (p *pagedProvider) Capabilities() provider.Capability { return provider.CapHistory }
+
+func (p *pagedProvider) History(_ context.Context, req provider.HistoryRequest) (provider.HistoryPage, error) {
+	p.cursors = append(p.cursors, req.Cursor)
+	if p.failAt != "" && req.Cursor == p.failAt {
+		return provider.HistoryPage{}, errors.New("boom")
+	}
+	off, _ := strconv.Atoi(req.Cursor)
+	end := min(off+req.Limit, len(p.orders))
+	page := provider.HistoryPage{Orders: p.orders[off:end]}
+	if end < len(p.orders) {
+		page.Next = strconv.Itoa(end)
+	}
+	return page, nil
+}
+
+func (p *pagedProvider) ActiveOrders(context.Context) (provider.ActiveOrders, error) {
+	return provider.ActiveOrders{}, provider.ErrUnsupported
+}
+
+func (p *pagedProvider) OrderDetail(context.Context, string) (provider.Order, error) {
+	return provider.Order{}, provider.ErrUnsupported
+}
+
+func (p *pagedProvider) Me(context.Context) (provider.Account, error) {
+	return provider.Account{}, provider.ErrUnsupported
+}
+
+var syncBase = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// makeOrders returns ids n-1..0, newest first.
+func makeOrders(n int) []provider.Order {
+	out := make([]provider.Order, 0, n)
+	for i := n - 1; i >= 0; i-- {
+		out = append(out, provider.Order{Provider: "fake", ID: "o" + strconv.Itoa(i), Time: syncBase.Add(time.Duration(i) * time.Hour)})
+	}
+	return out
+}
+
+func TestSyncIncrementalStopsAtKnownOrders(t *testing.T) {
+	a, _ := Open(filepath.Join(t.TempDir(), "a.json"))
+	p := &pagedProvider{orders: makeOrders(10)}
+	opts := SyncOptions{PageSize: 3}
+
+	res, err := a.Sync(context.Background(), p, "acct", opts)
+	if err != nil || res.Fetched != 10 || res.Added != 10 || res.Incremental {
+		t.Fatalf("first sync res=%+v err=%v", res, err)
+	}
+	c := a.Cursor("fake", "acct")
+	if c.LastID != "o9" || !c.Complete || c.Resume != "" || c.SyncedAt.IsZero() {
+		t.Fatalf("cursor=%+v", c)
+	}
+
+	// Two new orders on top: only the first page is fetched.
+	p.orders = makeOrders(12)
+	p.cursors = nil
+	res, err = a.Sync(context.Background(), p, "acct", opts)
+	if err != nil || res.Added != 2 || !res.Incremental || len(p.cursors) != 1 {
+		t.Fatalf("incremental res=%+v err=%v cursors=%v", res, err, p.cursors)
+	}
+	if c := a.Cursor("fake", "acct"); c.LastID != "o11" {
+		t.Fatalf("cursor=%+v", c)
+	}
+
+	// --full rescans everything.
+	p.cursors = nil
+	res, err = a.Sync(context.Background(), p, "acct", SyncOptions{PageSize: 3, Full: true})
+	if err != nil || res.Fetched != 12 || res.Added != 0 || len(p.cursors) != 4 {
+		t.Fatalf("full res=%+v err=%v cursors=%v", res, err, p.cursors)
+	}
+}
+
+func TestSyncResumesBackfillAfterLimitAndError(t *testing.T) {
+	a, _ := Open(filepath.Join(t.TempDir(), "a.json"))
+	p := &pagedProvider{orders: makeOrders(10)}
+
+	res, err := a.Sync(context.Background(), p, "", SyncOptions{PageSize: 2, Limit: 4})
+	if err != nil || res.Fetched != 4 {
+		t.Fatalf("res=%+v err=%v", res, err)
+	}
+	c := a.Cursor("fake", "")
+	if c.Complete || c.Resume != "4" || c.LastID != "o9" {
+		t.Fatalf("cursor=%+v", c)
+	}
+
+	p.failAt = "6"
+	p.cursors = nil
+	if _, err := a.Sync(context.Background(), p, "", SyncOptions{PageSize: 2}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if c := a.Cursor("fake", ""); c.Resume != "6" || c.Complete {
+		t.Fatalf("cursor=%+v", c)
+	}
+
+	p.failAt = ""
+	p.cursors = nil
+	res, err = a.Sync(context.Background(), p, "", SyncOptions{PageSize: 2})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	// Head page hits known orders, then the backfill continues at offset 6.
+	if len(p.cursors) != 3 || p.cursors[0] != "" || p.cursors[1] != "6" || p.cursors[2] != "8" {
+		t.Fatalf("cursors=%v", p.cursors)
+	}
+	if a.Len() != 10 || !a.Cursor("fake", "").Complete {
+		t.Fatalf("len=%d cursor=%+v", a.Len(), a.Cursor("fake", ""))
+	}
+}
+
+func TestSyncCursorPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.json")
+	a, _ := Open(path)
+	if _, err := a.Sync(context.Background(), &pagedProvider{orders: makeOrders(3)}, "x", SyncOptions{}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if err := a.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	b, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if c := b.Cursor("fake", "x"); c.LastID != "o2" || !c.LastTime.Equal(syncBase.Add(2*time.Hour)) || !c.Complete {
+		t.Fatalf("cursor=%+v", c)
+	}
+}
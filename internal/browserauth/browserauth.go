@@ -18,6 +18,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/steipete/ordercli/internal/apierr"
+	"github.com/steipete/ordercli/internal/browserpage"
 	"github.com/steipete/ordercli/internal/foodora"
 )
 
@@ -37,6 +39,8 @@ type PasswordOptions struct {
 	LogWriter  io.Writer
 	Playwright string
 	ProfileDir string
+	// Proxy is a proxy URL for the browser and the Playwright install.
+	Proxy string
 }
 
 func OAuthTokenPassword(ctx context.Context, req foodora.OAuthPasswordRequest, opts PasswordOptions) (foodora.AuthToken, *foodora.MfaChallenge, Session, error) {
@@ -89,6 +93,7 @@ func OAuthTokenPassword(ctx context.Context, req foodora.OAuthPasswordRequest, o
 		MfaToken:      req.MfaToken,
 		TimeoutMillis: int(timeout.Milliseconds()),
 		ProfileDir:    strings.TrimSpace(opts.ProfileDir),
+		Proxy:         browserpage.PlaywrightProxy(opts.Proxy),
 	}
 	b, _ := json.Marshal(in)
 
@@ -119,26 +124,37 @@ func OAuthTokenPassword(ctx context.Context, req foodora.OAuthPasswordRequest, o
 		return foodora.AuthToken{}, &ch, sess, nil
 	}
 
-	return foodora.AuthToken{}, nil, sess, &foodora.HTTPError{
+	herr := &foodora.HTTPError{
 		Method:     http.MethodPost,
 		URL:        newOAuthTokenURL(opts.BaseURL),
 		StatusCode: out.Status,
 		Body:       body,
 	}
+	// A challenge the browser could not pass (e.g. closed before solving)
+	// gets the same classification as one hit by the API client.
+	res := &http.Response{StatusCode: out.Status, Header: http.Header{}}
+	for k, v := range out.Headers {
+		res.Header.Set(k, v)
+	}
+	if req, err := http.NewRequest(http.MethodPost, herr.URL, nil); err == nil {
+		res.Request = req
+	}
+	return foodora.AuthToken{}, nil, sess, apierr.Wrap("foodora", res, body, herr)
 }
 
 type scriptInput struct {
-	BaseURL       string `json:"base_url"`
-	DeviceID      string `json:"device_id"`
-	Email         string `json:"email"`
-	Password      string `json:"password"`
-	ClientSecret  string `json:"client_secret"`
-	ClientID      string `json:"client_id"`
-	OTPMethod     string `json:"otp_method"`
-	OTPCode       string `json:"otp_code"`
-	MfaToken      string `json:"mfa_token"`
-	TimeoutMillis int    `json:"timeout_millis"`
-	ProfileDir    string `json:"profile_dir"`
+	BaseURL       string             `json:"base_url"`
+	DeviceID      string             `json:"device_id"`
+	Email         string             `json:"email"`
+	Password      string             `json:"password"`
+	ClientSecret  string             `json:"client_secret"`
+	ClientID      string             `json:"client_id"`
+	OTPMethod     string             `json:"otp_method"`
+	OTPCode       string             `json:"otp_code"`
+	MfaToken      string             `json:"mfa_token"`
+	TimeoutMillis int                `json:"timeout_millis"`
+	ProfileDir    string             `json:"profile_dir"`
+	Proxy         *browserpage.Proxy `json:"proxy,omitempty"`
 }
 
 type scriptOutput struct {
@@ -224,7 +240,7 @@ func runAuthScriptReal(ctx context.Context, td, scriptPath, outPath string, inpu
 	}
 	install.Env = append(
 		os.Environ(),
-		"npm_config_loglevel=error",
+		append([]string{"npm_config_loglevel=error"}, browserpage.ProxyEnv(opts.Proxy)...)...,
 	)
 	if err := install.Run(); err != nil {
 		return scriptOutput{}, fmt.Errorf("browserauth: npm install %s: %w", playwright, err)
@@ -244,7 +260,7 @@ func runAuthScriptReal(ctx context.Context, td, scriptPath, outPath string, inpu
 	}
 	installBrowsers.Env = append(
 		os.Environ(),
-		"npm_config_loglevel=error",
+		append([]string{"npm_config_loglevel=error"}, browserpage.ProxyEnv(opts.Proxy)...)...,
 	)
 	if err := installBrowsers.Run(); err != nil {
 		return scriptOutput{}, fmt.Errorf("browserauth: playwright install chromium: %w", err)
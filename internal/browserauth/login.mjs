@@ -105,13 +105,14 @@ const input = await readStdinJSON();
 const timeoutMillis = Math.max(10_000, Number(input.timeout_millis || 0));
 const deadline = Date.now() + timeoutMillis;
 
+const proxy = input.proxy ? { proxy: input.proxy } : {};
 let browser = null;
 let context = null;
 if (input.profile_dir) {
   // Persistent profile: keeps cookies/storage between runs.
-  context = await chromium.launchPersistentContext(input.profile_dir, { headless: false });
+  context = await chromium.launchPersistentContext(input.profile_dir, { headless: false, ...proxy });
 } else {
-  browser = await chromium.launch({ headless: false });
+  browser = await chromium.launch({ headless: false, ...proxy });
   context = await browser.newContext();
 }
 const page = await context.newPage();
@@ -24,6 +24,8 @@ type Options struct {
 	Headless   bool
 	LogWriter  io.Writer
 	Playwright string
+	// Proxy is a proxy URL for the browser and the Playwright install.
+	Proxy string
 }
 
 type Result struct {
@@ -36,6 +38,7 @@ type scriptInput struct {
 	URL           string `json:"url"`
 	TimeoutMillis int    `json:"timeout_millis"`
 	Headless      bool   `json:"headless"`
+	Proxy         *Proxy `json:"proxy,omitempty"`
 }
 
 var runFetchScriptFunc = runFetchScript
@@ -69,6 +72,7 @@ func ReadText(ctx context.Context, targetURL string, opts Options) (Result, erro
 		URL:           targetURL,
 		TimeoutMillis: int(opts.Timeout.Milliseconds()),
 		Headless:      opts.Headless,
+		Proxy:         PlaywrightProxy(opts.Proxy),
 	}
 	b, _ := json.Marshal(in)
 
@@ -103,7 +107,7 @@ func runFetchScript(ctx context.Context, td, scriptPath, outPath string, input [
 	} else {
 		install.Stderr = io.Discard
 	}
-	install.Env = append(os.Environ(), "npm_config_loglevel=error")
+	install.Env = append(append(os.Environ(), "npm_config_loglevel=error"), ProxyEnv(opts.Proxy)...)
 	if err := install.Run(); err != nil {
 		return nil, fmt.Errorf("browserpage: npm install %s: %w", playwright, err)
 	}
@@ -120,7 +124,7 @@ func runFetchScript(ctx context.Context, td, scriptPath, outPath string, input [
 	} else {
 		installBrowsers.Stderr = io.Discard
 	}
-	installBrowsers.Env = append(os.Environ(), "npm_config_loglevel=error")
+	installBrowsers.Env = append(append(os.Environ(), "npm_config_loglevel=error"), ProxyEnv(opts.Proxy)...)
 	if err := installBrowsers.Run(); err != nil {
 		return nil, fmt.Errorf("browserpage: playwright install chromium: %w", err)
 	}
@@ -16,7 +16,10 @@ async function readStdinJSON() {
 }
 
 const input = await readStdinJSON();
-const browser = await chromium.launch({ headless: input.headless !== false });
+const browser = await chromium.launch({
+  headless: input.headless !== false,
+  ...(input.proxy ? { proxy: input.proxy } : {}),
+});
 const page = await browser.newPage();
 
 try {
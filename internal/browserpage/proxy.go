@@ -0,0 +1,43 @@
+package browserpage
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Proxy is the proxy option of Playwright's chromium.launch.
+type Proxy struct {
+	Server   string `json:"server"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// PlaywrightProxy converts a proxy URL (http, https, socks5 or socks5h,
+// credentials in the userinfo) into Playwright's launch option. It returns
+// nil for an empty or unparsable URL.
+func PlaywrightProxy(raw string) *Proxy {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil
+	}
+	scheme := u.Scheme
+	if scheme == "socks5h" {
+		scheme = "socks5" // Chromium resolves names through SOCKS proxies anyway
+	}
+	p := &Proxy{Server: scheme + "://" + u.Host}
+	if u.User != nil {
+		p.Username = u.User.Username()
+		p.Password, _ = u.User.Password()
+	}
+	return p
+}
+
+// ProxyEnv points npm (which installs Playwright) at an HTTP(S) proxy. npm
+// cannot use SOCKS proxies; those get no variables.
+func ProxyEnv(raw string) []string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil
+	}
+	return []string{"HTTPS_PROXY=" + raw, "HTTP_PROXY=" + raw}
+}
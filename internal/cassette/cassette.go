@@ -0,0 +1,272 @@
+// Package cassette records HTTP interactions to a directory and replays them
+// later, for offline debugging and regression fixtures built from real
+// sessions. Everything written is scrubbed first: credentials, cookies,
+// e-mail addresses, phone numbers and street addresses never reach disk.
+//
+// Each interaction is one JSON file named <seq>-<method>-<host>.json. A
+// replay serves them in recording order per method and URL; when a URL runs
+// out, its last response repeats (polling loops keep working).
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxBody caps what is recorded of a single body.
+const maxBody = 8 << 20
+
+type Interaction struct {
+	Request    Request   `json:"request"`
+	Response   Response  `json:"response"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder sends requests through Next and writes every interaction to Dir.
+type Recorder struct {
+	Dir string
+	// Next sends the request (default: http.DefaultTransport at call time).
+	Next http.RoundTripper
+
+	mu  sync.Mutex
+	seq int
+}
+
+func NewRecorder(dir string, next http.RoundTripper) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	names, err := files(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Continue numbering so several runs can record into one directory.
+	return &Recorder{Dir: dir, Next: next, seq: len(names)}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	req, reqBody, err := peekRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := peekBody(&res.Body)
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	in := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    ScrubURL(req.URL.String()),
+			Header: scrubHeader(req.Header),
+			Body:   ScrubBody(reqBody),
+		},
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Header:     scrubHeader(res.Header),
+			Body:       ScrubBody(resBody),
+		},
+		RecordedAt: time.Now().UTC(),
+	}
+	if err := r.write(in, req.URL.Hostname()); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *Recorder) write(in Interaction, host string) error {
+	b, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	name := fmt.Sprintf("%06d-%s-%s.json", r.seq, strings.ToLower(in.Request.Method), slug(host))
+	return os.WriteFile(filepath.Join(r.Dir, name), append(b, '\n'), 0o600)
+}
+
+// peekRequest reads the request body without modifying req, which a
+// RoundTripper must not do: from GetBody when it is set, else from a clone
+// that is sent instead.
+func peekRequest(req *http.Request) (*http.Request, []byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer body.Close()
+		b, err := peekBody(&body)
+		return req, b, err
+	}
+	clone := req.Clone(req.Context())
+	b, err := peekBody(&clone.Body)
+	return clone, b, err
+}
+
+// peekBody reads *body fully (up to maxBody) and puts an equivalent reader back.
+func peekBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	b, err := io.ReadAll(io.LimitReader(*body, maxBody+1))
+	if err != nil {
+		return nil, err
+	}
+	rest := *body
+	*body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), rest), rest}
+	if len(b) > maxBody {
+		b = b[:maxBody]
+	}
+	return b, nil
+}
+
+// Player answers requests from the interactions recorded in a directory.
+type Player struct {
+	mu     sync.Mutex
+	byKey  map[string][]Interaction
+	served map[string]int
+}
+
+// NewPlayer loads every interaction of dir.
+func NewPlayer(dir string) (*Player, error) {
+	names, err := files(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("cassette: no recordings in %s", dir)
+	}
+	p := &Player{byKey: map[string][]Interaction{}, served: map[string]int{}}
+	for _, name := range names {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var in Interaction
+		if err := json.Unmarshal(b, &in); err != nil {
+			return nil, fmt.Errorf("cassette: %s: %w", name, err)
+		}
+		k := key(in.Request.Method, in.Request.URL)
+		p.byKey[k] = append(p.byKey[k], in)
+	}
+	return p, nil
+}
+
+// MissError is returned for requests that were never recorded.
+type MissError struct {
+	Method string
+	URL    string
+}
+
+func (e *MissError) Error() string {
+	return fmt.Sprintf("cassette: no recorded response for %s %s", e.Method, e.URL)
+}
+
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	u := ScrubURL(req.URL.String())
+	k := key(req.Method, u)
+
+	p.mu.Lock()
+	list := p.byKey[k]
+	i := p.served[k]
+	if i < len(list) {
+		p.served[k] = i + 1
+	}
+	p.mu.Unlock()
+	if len(list) == 0 {
+		return nil, &MissError{Method: req.Method, URL: u}
+	}
+	in := list[min(i, len(list)-1)]
+
+	h := in.Response.Header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+	// Bodies are stored decoded and scrubbed, so their length changed.
+	h.Del("Content-Encoding")
+	h.Del("Content-Length")
+	body := in.Response.Body
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+		StatusCode:    in.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func key(method, scrubbedURL string) string {
+	return strings.ToUpper(method) + " " + scrubbedURL
+}
+
+func files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9.]+`)
+
+func slug(s string) string {
+	s = nonSlug.ReplaceAllString(strings.ToLower(s), "_")
+	if s == "" {
+		return "host"
+	}
+	return s
+}
@@ -0,0 +1,213 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces every scrubbed value.
+const Redacted = "REDACTED"
+
+var sensitiveHeaders = map[string]bool{
+	"Authorization":                      true,
+	"Proxy-Authorization":                true,
+	"Cookie":                             true,
+	"Set-Cookie":                         true,
+	"X-Mfa-Token":                        true,
+	"X-Fp-Api-Key":                       true,
+	"X-Api-Key":                          true,
+	"X-Goog-Api-Key":                     true,
+	"X-Goog-Firebase-Installations-Auth": true,
+	"X-Device":                           true,
+	"Device-Id":                          true,
+}
+
+// sensitiveFields are JSON keys and form/query parameters whose values are
+// dropped, compared after lowercasing and removing "_" and "-".
+var sensitiveFields = map[string]bool{
+	// credentials
+	"accesstoken": true, "refreshtoken": true, "idtoken": true, "token": true,
+	"authtoken": true, "mfatoken": true, "clientsecret": true, "password": true,
+	"secret": true, "clientsecrets": true, "key": true, "apikey": true, "fid": true,
+	"appinstanceid": true,
+	// contact details
+	"email": true, "username": true, "phone": true, "phonenumber": true, "mobile": true,
+	"firstname": true, "lastname": true, "fullname": true, "customername": true,
+	// addresses
+	"address": true, "street": true, "streetname": true, "streetnumber": true,
+	"building": true, "housenumber": true, "postcode": true, "postalcode": true,
+	"zipcode": true, "formattedaddress": true, "addressline1": true,
+	"addressline2": true, "line1": true, "line2": true, "deliveryinstructions": true,
+}
+
+// coordinates are zeroed rather than redacted so they still decode as numbers.
+var coordinates = map[string]bool{"latitude": true, "longitude": true, "lat": true, "lng": true, "lon": true}
+
+var (
+	emailRE  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	jwtRE    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	bearerRE = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`)
+)
+
+func normField(k string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(k))
+}
+
+func scrubHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(http.Header, len(h))
+	for k, vs := range h {
+		if vs == nil {
+			continue // marker headers that are never sent
+		}
+		if sensitiveHeaders[http.CanonicalHeaderKey(k)] {
+			out[k] = []string{Redacted}
+			continue
+		}
+		for _, v := range vs {
+			out[k] = append(out[k], scrubText(v))
+		}
+	}
+	return out
+}
+
+// ScrubURL redacts sensitive query parameters.
+func ScrubURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return raw
+	}
+	q := u.Query()
+	scrubValues(q)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func scrubValues(q url.Values) {
+	for k, vs := range q {
+		switch f := normField(k); {
+		case sensitiveFields[f]:
+			q[k] = []string{Redacted}
+		case coordinates[f]:
+			q[k] = []string{"0"}
+		default:
+			for i, v := range vs {
+				vs[i] = scrubText(v)
+			}
+		}
+	}
+}
+
+// ScrubBody redacts a JSON or form-encoded body; anything else only has
+// e-mail addresses and bearer tokens removed.
+func ScrubBody(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		if v, ok := decodeJSON(trimmed); ok {
+			if out, err := json.Marshal(scrubJSON(v, false)); err == nil {
+				return string(out)
+			}
+		}
+	}
+	if looksLikeForm(trimmed) {
+		if q, err := url.ParseQuery(string(trimmed)); err == nil {
+			scrubValues(q)
+			return q.Encode()
+		}
+	}
+	return scrubText(string(b))
+}
+
+func looksLikeForm(b []byte) bool {
+	return bytes.Contains(b, []byte("=")) && !bytes.ContainsAny(b, " \n<{")
+}
+
+// scrubJSON scrubs v in place. Below a sensitive key every leaf is dropped
+// (redactAll), but objects and lists keep their shape so replays decode.
+// Strings holding JSON themselves (Firebase Remote Config values) are
+// scrubbed recursively.
+func scrubJSON(v any, redactAll bool) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			f := normField(k)
+			switch {
+			case coordinates[f]:
+				t[k] = zero(val)
+			default:
+				t[k] = scrubJSON(val, redactAll || sensitiveFields[f])
+			}
+		}
+		return t
+	case []any:
+		for i := range t {
+			t[i] = scrubJSON(t[i], redactAll)
+		}
+		return t
+	case string:
+		if nested, ok := scrubNestedJSON(t, redactAll); ok {
+			return nested
+		}
+		if redactAll {
+			return Redacted
+		}
+		return scrubText(t)
+	case json.Number:
+		if redactAll {
+			return json.Number("0")
+		}
+	}
+	return v
+}
+
+func zero(v any) any {
+	switch v.(type) {
+	case json.Number:
+		return json.Number("0")
+	case string:
+		return "0"
+	}
+	return v
+}
+
+func scrubNestedJSON(s string, redactAll bool) (string, bool) {
+	t := strings.TrimSpace(s)
+	if len(t) < 2 || (t[0] != '{' && t[0] != '[') {
+		return "", false
+	}
+	v, ok := decodeJSON([]byte(t))
+	if !ok {
+		return "", false
+	}
+	out, err := json.Marshal(scrubJSON(v, redactAll))
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
+
+func decodeJSON(b []byte) (any, bool) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// scrubText removes tokens and e-mail addresses embedded in free text.
+func scrubText(s string) string {
+	s = jwtRE.ReplaceAllString(s, Redacted)
+	s = bearerRE.ReplaceAllString(s, "${1}"+Redacted)
+	return emailRE.ReplaceAllString(s, "redacted@example.com")
+}
@@ -3,7 +3,9 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -22,37 +24,78 @@ func newConfigShowCmd(st *state) *cobra.Command {
 	return &cobra.Command{
 		Use:   "show",
 		Short: "Print current config (redacts tokens)",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := st.foodora()
-			fmt.Fprintf(cmd.OutOrStdout(), "base_url=%s\n", cfg.BaseURL)
-			fmt.Fprintf(cmd.OutOrStdout(), "global_entity_id=%s\n", cfg.GlobalEntityID)
-			fmt.Fprintf(cmd.OutOrStdout(), "target_country_iso=%s\n", cfg.TargetCountryISO)
-			fmt.Fprintf(cmd.OutOrStdout(), "device_id=%s\n", cfg.DeviceID)
-			if cfg.AccessToken != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "access_token=***\n")
-			}
-			if cfg.RefreshToken != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "refresh_token=***\n")
-			}
-			if cfg.ClientSecret != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "client_secret=*** (stored)\n")
-			}
-			if cfg.OAuthClientID != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "oauth_client_id=%s\n", cfg.OAuthClientID)
-			}
-			if cfg.HTTPUserAgent != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "http_user_agent=%s\n", cfg.HTTPUserAgent)
-			}
-			if len(cfg.CookiesByHost) > 0 {
-				fmt.Fprintf(cmd.OutOrStdout(), "cookies_by_host=*** (%d)\n", len(cfg.CookiesByHost))
+			view := foodoraConfigView{
+				BaseURL:          cfg.BaseURL,
+				GlobalEntityID:   cfg.GlobalEntityID,
+				TargetCountryISO: cfg.TargetCountryISO,
+				DeviceID:         cfg.DeviceID,
+				AccessToken:      cfg.AccessToken != "",
+				RefreshToken:     cfg.RefreshToken != "",
+				ClientSecret:     cfg.ClientSecret != "",
+				OAuthClientID:    cfg.OAuthClientID,
+				HTTPUserAgent:    cfg.HTTPUserAgent,
+				CookieHosts:      len(cfg.CookiesByHost),
+				ExpiredCookies:   expiredCookies(cfg, time.Now()),
 			}
 			if cfg.PendingMfaToken != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "pending_mfa=*** (%s, %s)\n", cfg.PendingMfaChannel, cfg.PendingMfaEmail)
+				view.PendingMfaChannel = cfg.PendingMfaChannel
+				view.PendingMfaEmail = cfg.PendingMfaEmail
 			}
+			return st.emit(cmd, false, view, func(out io.Writer) {
+				fmt.Fprintf(out, "base_url=%s\n", cfg.BaseURL)
+				fmt.Fprintf(out, "global_entity_id=%s\n", cfg.GlobalEntityID)
+				fmt.Fprintf(out, "target_country_iso=%s\n", cfg.TargetCountryISO)
+				fmt.Fprintf(out, "device_id=%s\n", cfg.DeviceID)
+				if cfg.AccessToken != "" {
+					fmt.Fprintf(out, "access_token=***\n")
+				}
+				if cfg.RefreshToken != "" {
+					fmt.Fprintf(out, "refresh_token=***\n")
+				}
+				if cfg.ClientSecret != "" {
+					fmt.Fprintf(out, "client_secret=*** (stored)\n")
+				}
+				if cfg.OAuthClientID != "" {
+					fmt.Fprintf(out, "oauth_client_id=%s\n", cfg.OAuthClientID)
+				}
+				if cfg.HTTPUserAgent != "" {
+					fmt.Fprintf(out, "http_user_agent=%s\n", cfg.HTTPUserAgent)
+				}
+				if len(cfg.CookiesByHost) > 0 {
+					fmt.Fprintf(out, "cookies_by_host=*** (%d)\n", len(cfg.CookiesByHost))
+				}
+				for _, c := range view.ExpiredCookies {
+					fmt.Fprintf(out, "cookie_expired=%s (refresh: ordercli foodora cookies chrome)\n", c)
+				}
+				if cfg.PendingMfaToken != "" {
+					fmt.Fprintf(out, "pending_mfa=*** (%s, %s)\n", cfg.PendingMfaChannel, cfg.PendingMfaEmail)
+				}
+			})
 		},
 	}
 }
 
+// foodoraConfigView is the machine-readable config; secrets only report
+// whether they are set.
+type foodoraConfigView struct {
+	BaseURL           string `json:"base_url"`
+	GlobalEntityID    string `json:"global_entity_id"`
+	TargetCountryISO  string `json:"target_country_iso"`
+	DeviceID          string `json:"device_id"`
+	AccessToken       bool   `json:"access_token_set"`
+	RefreshToken      bool   `json:"refresh_token_set"`
+	ClientSecret      bool   `json:"client_secret_set"`
+	OAuthClientID     string `json:"oauth_client_id,omitempty"`
+	HTTPUserAgent     string `json:"http_user_agent,omitempty"`
+	CookieHosts       int    `json:"cookie_hosts"`
+	PendingMfaChannel string `json:"pending_mfa_channel,omitempty"`
+	PendingMfaEmail   string `json:"pending_mfa_email,omitempty"`
+	// ExpiredCookies are stored Cloudflare cookies past their usual lifetime.
+	ExpiredCookies []storedCookie `json:"expired_cookies,omitempty"`
+}
+
 func newConfigSetCmd(st *state) *cobra.Command {
 	var country string
 	var baseURL string
@@ -74,7 +117,7 @@ func newConfigSetCmd(st *state) *cobra.Command {
 				cfg.GlobalEntityID = p.GlobalEntityID
 				cfg.TargetCountryISO = p.TargetISO
 				st.markDirty()
-				return nil
+				return st.emitOK(cmd, "")
 			}
 
 			if baseURL == "" && globalEntityID == "" && targetISO == "" {
@@ -90,7 +133,7 @@ func newConfigSetCmd(st *state) *cobra.Command {
 				cfg.TargetCountryISO = targetISO
 			}
 			st.markDirty()
-			return nil
+			return st.emitOK(cmd, "")
 		},
 	}
 
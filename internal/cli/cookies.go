@@ -3,6 +3,7 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"path/filepath"
 	"strings"
@@ -69,14 +70,16 @@ func newCookiesChromeCmd(st *state) *cobra.Command {
 				return errors.New("no cookies found (are you logged in in Chrome? try --profile \"Default\" / \"Profile 1\" or --cookie-path)")
 			}
 
-			if cfg.CookiesByHost == nil {
-				cfg.CookiesByHost = map[string]string{}
-			}
-			cfg.CookiesByHost[strings.ToLower(host)] = res.CookieHeader
-			st.markDirty()
+			st.storeCookies(host, res.CookieHeader)
 
-			fmt.Fprintf(cmd.OutOrStdout(), "ok host=%s cookies=%d\n", host, res.CookieCount)
-			return nil
+			view := struct {
+				OK      bool   `json:"ok"`
+				Host    string `json:"host"`
+				Cookies int    `json:"cookies"`
+			}{true, host, res.CookieCount}
+			return st.emit(cmd, false, view, func(out io.Writer) {
+				fmt.Fprintf(out, "ok host=%s cookies=%d\n", host, res.CookieCount)
+			})
 		},
 	}
 
@@ -2,34 +2,39 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 type countryPreset struct {
-	Code           string
-	BaseURL        string
-	GlobalEntityID string
-	TargetISO      string
+	Code           string `json:"code"`
+	BaseURL        string `json:"base_url"`
+	GlobalEntityID string `json:"global_entity_id"`
+	TargetISO      string `json:"target_iso"`
+	Currency       string `json:"currency"`
 }
 
 var presets = []countryPreset{
-	{Code: "HU", BaseURL: "https://hu.fd-api.com/api/v5/", GlobalEntityID: "NP_HU", TargetISO: "HU"},
-	{Code: "SK", BaseURL: "https://sk.fd-api.com/api/v5/", GlobalEntityID: "FP_SK", TargetISO: "SK"},
-	{Code: "DL", BaseURL: "https://dl.fd-api.com/api/v5/", GlobalEntityID: "FP_DE", TargetISO: "DE"},
-	{Code: "AT", BaseURL: "https://mj.fd-api.com/api/v5/", GlobalEntityID: "MJM_AT", TargetISO: "AT"},
-	{Code: "CZ", BaseURL: "https://cz.fd-api.com/api/v5/", GlobalEntityID: "DJ_CZ", TargetISO: "CZ"},
-	{Code: "SE", BaseURL: "https://se.fd-api.com/api/v5/", GlobalEntityID: "OP_SE", TargetISO: "SE"},
+	{Code: "HU", BaseURL: "https://hu.fd-api.com/api/v5/", GlobalEntityID: "NP_HU", TargetISO: "HU", Currency: "HUF"},
+	{Code: "SK", BaseURL: "https://sk.fd-api.com/api/v5/", GlobalEntityID: "FP_SK", TargetISO: "SK", Currency: "EUR"},
+	{Code: "DL", BaseURL: "https://dl.fd-api.com/api/v5/", GlobalEntityID: "FP_DE", TargetISO: "DE", Currency: "EUR"},
+	{Code: "AT", BaseURL: "https://mj.fd-api.com/api/v5/", GlobalEntityID: "MJM_AT", TargetISO: "AT", Currency: "EUR"},
+	{Code: "CZ", BaseURL: "https://cz.fd-api.com/api/v5/", GlobalEntityID: "DJ_CZ", TargetISO: "CZ", Currency: "CZK"},
+	{Code: "SE", BaseURL: "https://se.fd-api.com/api/v5/", GlobalEntityID: "OP_SE", TargetISO: "SE", Currency: "SEK"},
 }
 
 func newCountriesCmd(st *state) *cobra.Command {
 	return &cobra.Command{
 		Use:   "countries",
 		Short: "List bundled country presets (from the APK)",
-		Run: func(cmd *cobra.Command, args []string) {
-			for _, p := range presets {
-				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\n", p.Code, p.GlobalEntityID, p.BaseURL)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return st.emit(cmd, false, presets, func(out io.Writer) {
+				for _, p := range presets {
+					fmt.Fprintf(out, "%s\t%s\t%s\n", p.Code, p.GlobalEntityID, p.BaseURL)
+				}
+			})
 		},
 	}
 }
@@ -42,3 +47,50 @@ func findPreset(code string) (countryPreset, bool) {
 	}
 	return countryPreset{}, false
 }
+
+// foodoraCurrency infers the ISO currency for the configured foodora country.
+func foodoraCurrency(st *state) string {
+	cfg := st.foodora()
+	for _, p := range presets {
+		if strings.EqualFold(p.TargetISO, cfg.TargetCountryISO) || strings.EqualFold(p.BaseURL, cfg.BaseURL) {
+			return p.Currency
+		}
+	}
+	return ""
+}
+
+// Currencies of Deliveroo markets and Glovo countries, for orders whose
+// payload carries no currency.
+var (
+	deliverooMarketCurrency = map[string]string{
+		"uk": "GBP", "ie": "EUR", "fr": "EUR", "it": "EUR", "be": "EUR", "nl": "EUR",
+		"ae": "AED", "kw": "KWD", "qa": "QAR", "sg": "SGD", "hk": "HKD",
+	}
+	glovoCountryCurrency = map[string]string{
+		"ES": "EUR", "IT": "EUR", "PT": "EUR", "FR": "EUR", "HR": "EUR", "SI": "EUR", "AD": "EUR",
+		"PL": "PLN", "RO": "RON", "BG": "BGN", "RS": "RSD", "BA": "BAM", "ME": "EUR",
+		"UA": "UAH", "GE": "GEL", "AM": "AMD", "KZ": "KZT", "KG": "KGS", "MA": "MAD",
+		"KE": "KES", "NG": "NGN", "UG": "UGX", "GH": "GHS", "CI": "XOF", "TN": "TND",
+	}
+)
+
+// defaultCurrency is the currency assumed for orders of providerName when the
+// provider does not report one: the foodora country preset, the Deliveroo
+// market, or the Glovo country.
+func defaultCurrency(st *state, providerName string) string {
+	switch providerName {
+	case "foodora":
+		if st.cfg.Providers.Foodora != nil {
+			return foodoraCurrency(st)
+		}
+	case "deliveroo":
+		if cfg := st.cfg.Providers.Deliveroo; cfg != nil {
+			return deliverooMarketCurrency[strings.ToLower(strings.TrimSpace(cfg.Market))]
+		}
+	case "glovo":
+		if cfg := st.cfg.Providers.Glovo; cfg != nil {
+			return glovoCountryCurrency[strings.ToUpper(strings.TrimSpace(cfg.CountryCode))]
+		}
+	}
+	return ""
+}
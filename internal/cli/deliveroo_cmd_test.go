@@ -76,7 +76,7 @@ func TestDeliverooCLI_OrdersFallbackToPublicStatus(t *testing.T) {
 		}
 		return "https://deliveroo.example/orders/123/status", nil
 	}
-	deliverooFetchPublicStatus = func(_ context.Context, targetURL string, timeout time.Duration) (deliveroo.PublicStatus, error) {
+	deliverooFetchPublicStatus = func(_ context.Context, targetURL string, timeout time.Duration, _ string) (deliveroo.PublicStatus, error) {
 		if targetURL != "https://deliveroo.example/orders/123/status" {
 			t.Fatalf("url=%q", targetURL)
 		}
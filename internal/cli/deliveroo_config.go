@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -21,10 +22,12 @@ func newDeliverooConfigShowCmd(st *state) *cobra.Command {
 	return &cobra.Command{
 		Use:   "show",
 		Short: "Print current Deliveroo config",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := st.deliveroo()
-			fmt.Fprintf(cmd.OutOrStdout(), "market=%s\n", cfg.Market)
-			fmt.Fprintf(cmd.OutOrStdout(), "base_url=%s\n", cfg.BaseURL)
+			return st.emit(cmd, false, cfg, func(out io.Writer) {
+				fmt.Fprintf(out, "market=%s\n", cfg.Market)
+				fmt.Fprintf(out, "base_url=%s\n", cfg.BaseURL)
+			})
 		},
 	}
 }
@@ -48,7 +51,7 @@ func newDeliverooConfigSetCmd(st *state) *cobra.Command {
 				cfg.BaseURL = strings.TrimSpace(baseURL)
 			}
 			st.markDirty()
-			return nil
+			return st.emitOK(cmd, "")
 		},
 	}
 
@@ -1,9 +1,9 @@
 package cli
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -11,6 +11,8 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/steipete/ordercli/internal/deliveroo"
+	"github.com/steipete/ordercli/internal/provider"
+	"github.com/steipete/ordercli/internal/transport"
 )
 
 func newDeliverooHistoryCmd(st *state) *cobra.Command {
@@ -28,35 +30,11 @@ func newDeliverooHistoryCmd(st *state) *cobra.Command {
 		Use:   "history",
 		Short: "List past orders (requires DELIVEROO_BEARER_TOKEN)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := st.deliveroo()
-
-			m := strings.TrimSpace(market)
-			if m == "" {
-				m = strings.TrimSpace(cfg.Market)
-			}
-			b := strings.TrimSpace(bearerToken)
-			if b == "" {
-				b = strings.TrimSpace(os.Getenv("DELIVEROO_BEARER_TOKEN"))
-			}
-			if b == "" {
-				return errors.New("missing bearer token (set DELIVEROO_BEARER_TOKEN or pass --bearer-token)")
-			}
-			c := strings.TrimSpace(cookie)
-			if c == "" {
-				c = strings.TrimSpace(os.Getenv("DELIVEROO_COOKIE"))
-			}
-
-			u := strings.TrimSpace(baseURL)
-			if u == "" {
-				u = strings.TrimSpace(cfg.BaseURL)
-			}
-
-			cl, err := deliveroo.NewClient(deliveroo.ClientOptions{
-				BaseURL:     u,
-				Market:      m,
-				BearerToken: b,
-				Cookie:      c,
-				Timeout:     20 * time.Second,
+			cl, err := newDeliverooClient(st, deliverooClientFlags{
+				Market:      market,
+				BaseURL:     baseURL,
+				BearerToken: bearerToken,
+				Cookie:      cookie,
 			})
 			if err != nil {
 				return err
@@ -72,21 +50,7 @@ func newDeliverooHistoryCmd(st *state) *cobra.Command {
 				return err
 			}
 
-			if asJSON {
-				enc := json.NewEncoder(cmd.OutOrStdout())
-				enc.SetIndent("", "  ")
-				return enc.Encode(resp)
-			}
-
-			out := cmd.OutOrStdout()
-			if len(resp.Orders) == 0 {
-				fmt.Fprintln(out, "no orders")
-				return nil
-			}
-			for _, o := range resp.Orders {
-				fmt.Fprintln(out, o.Summary())
-			}
-			return nil
+			return st.emit(cmd, asJSON, resp, func(out io.Writer) { printDeliverooOrders(out, resp.Orders) })
 		},
 	}
 
@@ -102,6 +66,47 @@ func newDeliverooHistoryCmd(st *state) *cobra.Command {
 	return cmd
 }
 
+type deliverooClientFlags struct {
+	Market      string
+	BaseURL     string
+	BearerToken string
+	Cookie      string
+}
+
+// newDeliverooClient builds a client from flags, falling back to env and config.
+func newDeliverooClient(st *state, f deliverooClientFlags) (*deliveroo.Client, error) {
+	cfg := st.deliveroo()
+
+	m := strings.TrimSpace(f.Market)
+	if m == "" {
+		m = strings.TrimSpace(cfg.Market)
+	}
+	b := strings.TrimSpace(f.BearerToken)
+	if b == "" {
+		b = strings.TrimSpace(os.Getenv("DELIVEROO_BEARER_TOKEN"))
+	}
+	if b == "" {
+		return nil, errors.New("missing bearer token (set DELIVEROO_BEARER_TOKEN or pass --bearer-token)")
+	}
+	c := strings.TrimSpace(f.Cookie)
+	if c == "" {
+		c = strings.TrimSpace(os.Getenv("DELIVEROO_COOKIE"))
+	}
+
+	u := strings.TrimSpace(f.BaseURL)
+	if u == "" {
+		u = strings.TrimSpace(cfg.BaseURL)
+	}
+
+	return deliveroo.NewClient(deliveroo.ClientOptions{
+		BaseURL:     u,
+		Market:      m,
+		BearerToken: b,
+		Cookie:      c,
+		HTTPClient:  st.httpClient(provider.Deliveroo, 20*time.Second),
+	})
+}
+
 func newDeliverooOrdersCmd(st *state) *cobra.Command {
 	var interval time.Duration
 	var once bool
@@ -114,49 +119,62 @@ func newDeliverooOrdersCmd(st *state) *cobra.Command {
 		Aliases: []string{"active"},
 		Short:   "List active orders",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			runOnce := func() error {
+			// runOnce prints the active orders and returns them normalized,
+			// for status notifications.
+			runOnce := func() ([]provider.Order, error) {
 				if strings.TrimSpace(os.Getenv("DELIVEROO_BEARER_TOKEN")) != "" {
-					h := newDeliverooHistoryCmd(st)
-					h.SetArgs([]string{"--state", "active"})
-					h.SetOut(cmd.OutOrStdout())
-					h.SetErr(cmd.ErrOrStderr())
-					h.SetContext(cmd.Context())
-					return h.Execute()
+					cl, err := newDeliverooClient(st, deliverooClientFlags{})
+					if err != nil {
+						return nil, err
+					}
+					resp, err := cl.OrderHistory(cmd.Context(), deliveroo.OrderHistoryParams{Limit: 10, State: "active"})
+					if err != nil {
+						return nil, err
+					}
+					orders := make([]provider.Order, 0, len(resp.Orders))
+					for _, o := range resp.Orders {
+						orders = append(orders, provider.DeliverooOrder(o))
+					}
+					return orders, st.emit(cmd, asJSON, resp, func(out io.Writer) { printDeliverooOrders(out, resp.Orders) })
 				}
 
 				targetURL := strings.TrimSpace(statusURL)
 				if targetURL == "" {
 					u, err := deliverooResolveLatestStatusURL(cmd.Context(), browser)
 					if err != nil {
-						return err
+						return nil, err
 					}
 					targetURL = u
 				}
 
-				status, err := deliverooFetchPublicStatus(cmd.Context(), targetURL, 2*time.Minute)
+				status, err := deliverooFetchPublicStatus(cmd.Context(), targetURL, 2*time.Minute, st.browserProxy())
 				if err != nil {
-					return err
+					return nil, err
 				}
 
-				if asJSON {
-					enc := json.NewEncoder(cmd.OutOrStdout())
-					enc.SetIndent("", "  ")
-					return enc.Encode(status)
-				}
-
-				fmt.Fprintln(cmd.OutOrStdout(), status.DetailsString())
-				return nil
+				return []provider.Order{deliverooStatusOrder(targetURL, status)}, st.emit(cmd, asJSON, status, func(out io.Writer) {
+					fmt.Fprintln(out, status.DetailsString())
+				})
 			}
 
 			if interval <= 0 || once {
-				return runOnce()
+				_, err := runOnce()
+				return err
 			}
 
+			feed, err := st.newStatusFeed(cmd)
+			if err != nil {
+				return err
+			}
 			for {
-				if err := runOnce(); err != nil {
+				orders, err := runOnce()
+				if err != nil {
+					return err
+				}
+				feed.update(cmd.Context(), provider.Deliveroo, orders, time.Now())
+				if err := transport.Sleep(cmd.Context(), interval); err != nil {
 					return err
 				}
-				time.Sleep(interval)
 			}
 		},
 	}
@@ -168,3 +186,31 @@ func newDeliverooOrdersCmd(st *state) *cobra.Command {
 	cmd.Flags().BoolVar(&asJSON, "json", false, "print raw JSON")
 	return cmd
 }
+
+func printDeliverooOrders(out io.Writer, orders []deliveroo.Order) {
+	if len(orders) == 0 {
+		fmt.Fprintln(out, "no orders")
+		return
+	}
+	for _, o := range orders {
+		fmt.Fprintln(out, o.Summary())
+	}
+}
+
+// deliverooStatusOrder normalizes a scraped status page; the page URL stands
+// in for the order id when the page shows no order number.
+func deliverooStatusOrder(url string, s deliveroo.PublicStatus) provider.Order {
+	id := s.OrderNumber
+	if id == "" {
+		id = url
+	}
+	delivered := strings.Contains(strings.ToLower(s.Status), "delivered")
+	return provider.Order{
+		Provider:  provider.Deliveroo,
+		ID:        id,
+		Vendor:    s.Restaurant,
+		Status:    s.Status,
+		Active:    !delivered,
+		Delivered: delivered,
+	}
+}
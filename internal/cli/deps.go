@@ -19,6 +19,6 @@ var browserOAuthTokenPassword = func(ctx context.Context, req foodora.OAuthPassw
 
 var deliverooResolveLatestStatusURL = browserhistory.ResolveLatestDeliverooStatusURL
 
-var deliverooFetchPublicStatus = func(ctx context.Context, targetURL string, timeout time.Duration) (deliveroo.PublicStatus, error) {
-	return deliveroo.FetchPublicStatus(ctx, targetURL, timeout)
+var deliverooFetchPublicStatus = func(ctx context.Context, targetURL string, timeout time.Duration, proxy string) (deliveroo.PublicStatus, error) {
+	return deliveroo.FetchPublicStatus(ctx, targetURL, timeout, proxy)
 }
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/schema"
+)
+
+// schemaPath is where response drift is kept, next to the config.
+func (s *state) schemaPath() string {
+	return filepath.Join(filepath.Dir(s.configPath), "schema-drift.json")
+}
+
+// schemaRecorder is shared by every client of the process, so `serve` and
+// the watch loops add to one report.
+func (s *state) schemaRecorder() *schema.Recorder {
+	s.schemaOnce.Do(func() {
+		s.schema = schema.NewRecorder(s.schemaPath(), s.logOut)
+	})
+	return s.schema
+}
+
+func newDoctorCmd(st *state) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnostics",
+	}
+	cmd.AddCommand(newDoctorSchemaCmd(st))
+	return cmd
+}
+
+func newDoctorSchemaCmd(st *state) *cobra.Command {
+	var providerName string
+	var kinds []string
+	var reset bool
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Report how API responses drifted from the models (unknown fields, type mismatches, missing fields)",
+		Long: `Report how API responses drifted from the models.
+
+Every foodora and Glovo response is compared with the model it is decoded
+into while commands run; findings are kept per endpoint in
+schema-drift.json next to the config. type_mismatch and missing findings
+usually mean a model needs updating; unknown lists fields the API sends
+that no model reads.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if reset {
+				if err := schema.Reset(st.schemaPath()); err != nil {
+					return err
+				}
+				return st.emitOK(cmd, "schema drift report cleared")
+			}
+			rep, err := schema.Load(st.schemaPath())
+			if err != nil {
+				return err
+			}
+			for _, k := range kinds {
+				switch schema.Kind(k) {
+				case schema.Unknown, schema.Mismatch, schema.Missing:
+				default:
+					return fmt.Errorf("invalid --kind %q (use unknown, type_mismatch or missing)", k)
+				}
+			}
+
+			endpoints := []schema.Endpoint{}
+			for _, e := range rep.Endpoints {
+				if providerName != "" && e.Provider != providerName {
+					continue
+				}
+				var keep []schema.Finding
+				for _, f := range e.Findings {
+					if len(kinds) == 0 || slices.Contains(kinds, string(f.Kind)) {
+						keep = append(keep, f)
+					}
+				}
+				if len(keep) > 0 {
+					e.Findings = keep
+					endpoints = append(endpoints, e)
+				}
+			}
+			return st.emit(cmd, false, endpoints, func(out io.Writer) {
+				if len(endpoints) == 0 {
+					fmt.Fprintln(out, "no schema drift recorded")
+					return
+				}
+				for i, e := range endpoints {
+					if i > 0 {
+						fmt.Fprintln(out)
+					}
+					fmt.Fprintf(out, "%s %s (%s)\n", e.Provider, e.Name, e.Type)
+					for _, f := range e.Findings {
+						detail := ""
+						if f.Kind == schema.Mismatch {
+							detail = fmt.Sprintf("\twant %s, got %s", f.Want, f.Got)
+						}
+						fmt.Fprintf(out, "  %s\t%s%s\tseen %dx, last %s\n", f.Kind, f.Path, detail, f.Count, f.LastSeen.Local().Format("2006-01-02 15:04"))
+					}
+				}
+			})
+		},
+	}
+	cmd.Flags().StringVar(&providerName, "provider", "", "only this provider (foodora or glovo)")
+	cmd.Flags().StringSliceVar(&kinds, "kind", nil, "only these kinds: unknown, type_mismatch, missing (repeatable)")
+	cmd.Flags().BoolVar(&reset, "reset", false, "forget every recorded finding")
+	return cmd
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/steipete/ordercli/internal/schema"
+)
+
+func TestDoctorSchema_ReportsDrift(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":200,"data":{"count":1,"active_orders":[{"code":"OC-1","is_delivered":"no","vendor":{"code":"V","name":"Vendor"},"courier_eta":7,"status_messages":{"subtitle":"Cooking","titles":[]}}]}}`))
+	}))
+	defer srv.Close()
+	cfgPath := writeMultiProviderConfig(t, srv.URL, "")
+
+	out, _, err := runCLI(cfgPath, []string{"doctor", "schema"}, "")
+	if err != nil || !strings.Contains(out, "no schema drift recorded") {
+		t.Fatalf("empty report: out=%q err=%v", out, err)
+	}
+
+	// The type mismatch fails the command, but the drift is still recorded.
+	_, _, _ = runCLI(cfgPath, []string{"foodora", "orders"}, "")
+
+	out, _, err = runCLI(cfgPath, []string{"-o", "json", "doctor", "schema", "--provider", "foodora"}, "")
+	if err != nil {
+		t.Fatalf("doctor schema: %v", err)
+	}
+	var endpoints []schema.Endpoint
+	if err := json.Unmarshal([]byte(out), &endpoints); err != nil {
+		t.Fatalf("decode: %v\n%s", err, out)
+	}
+	if len(endpoints) != 1 || endpoints[0].Name != "GET tracking/active-orders" || endpoints[0].Type != "foodora.ActiveOrdersResponse" {
+		t.Fatalf("endpoints=%+v", endpoints)
+	}
+	var mismatch, unknown bool
+	for _, f := range endpoints[0].Findings {
+		switch {
+		case f.Kind == schema.Mismatch && f.Path == "data.active_orders[].is_delivered" && f.Want == "boolean" && f.Got == "string":
+			mismatch = true
+		case f.Kind == schema.Unknown && f.Path == "data.active_orders[].courier_eta":
+			unknown = true
+		}
+	}
+	if !mismatch || !unknown {
+		t.Fatalf("findings=%+v", endpoints[0].Findings)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"doctor", "schema", "--kind", "type_mismatch"}, "")
+	if err != nil {
+		t.Fatalf("doctor schema --kind: %v", err)
+	}
+	if !strings.Contains(out, "foodora GET tracking/active-orders (foodora.ActiveOrdersResponse)") ||
+		!strings.Contains(out, "type_mismatch\tdata.active_orders[].is_delivered\twant boolean, got string\tseen 1x") ||
+		strings.Contains(out, "courier_eta") {
+		t.Fatalf("unexpected table:\n%s", out)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"doctor", "schema", "--kind", "bogus"}, ""); err == nil {
+		t.Fatalf("expected --kind validation error")
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"doctor", "schema", "--reset"}, ""); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	out, _, _ = runCLI(cfgPath, []string{"doctor", "schema"}, "")
+	if !strings.Contains(out, "no schema drift recorded") {
+		t.Fatalf("after reset: %q", out)
+	}
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"context"
+	"errors"
+)
+
+// Process exit codes besides 0 (success) and 1 (error).
+const (
+	// ExitUndelivered: a watch ended because its orders left the active list
+	// without being reported delivered (e.g. cancelled).
+	ExitUndelivered = 3
+	// ExitInterrupted: stopped by Ctrl-C / SIGTERM (128 + SIGINT).
+	ExitInterrupted = 130
+)
+
+// ExitError makes the process exit with Code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// ExitCode maps an error returned by Run to a process exit code.
+func ExitCode(err error) int {
+	var ee *ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &ee):
+		return ee.Code
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	default:
+		return 1
+	}
+}
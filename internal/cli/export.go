@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/export"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func newExportCmd(st *state) *cobra.Command {
+	var format string
+	var items bool
+	var expenseAccount, fundingAccount string
+	var src orderSource
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export past orders as CSV/NDJSON/JSON rows or ledger/hledger/beancount journals",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("format") {
+				// --output json/ndjson/template stand in for --format.
+				switch st.format(false) {
+				case outputJSON:
+					format = string(export.JSON)
+				case outputNDJSON:
+					format = string(export.NDJSON)
+				}
+			}
+			f, err := export.ParseFormat(format)
+			if err != nil {
+				return err
+			}
+			orders, err := src.load(cmd, st)
+			if err != nil {
+				return err
+			}
+			acct := accountingConfig(st)
+			if expenseAccount != "" {
+				acct.ExpenseAccount = expenseAccount
+			}
+			if fundingAccount != "" {
+				acct.FundingAccount = fundingAccount
+			}
+			opts := export.Options{
+				Items:      items,
+				Accounting: &acct,
+				Skipped: func(o provider.Order, reason string) {
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipped %s %s: %s\n", o.Provider, o.ID, reason)
+				},
+			}
+			if st.format(false) == outputTemplate && !cmd.Flags().Changed("format") {
+				return st.emit(cmd, false, export.Rows(orders, opts), nil)
+			}
+			return export.Write(cmd.OutOrStdout(), f, orders, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "csv", "csv, ndjson, json, ledger, hledger or beancount")
+	cmd.Flags().BoolVar(&items, "items", false, "one row per line item (order_products) instead of per order")
+	cmd.Flags().StringVar(&expenseAccount, "expense-account", "", "journal expense account when no rule matches (default: accounting.expense_account or "+export.DefaultExpenseAccount+")")
+	cmd.Flags().StringVar(&fundingAccount, "funding-account", "", "journal funding account when no rule matches (default: accounting.funding_account or "+export.DefaultFundingAccount+")")
+	src.addFlags(cmd)
+	return cmd
+}
+
+// accountingConfig returns a copy of the journal account rules.
+func accountingConfig(st *state) config.AccountingConfig {
+	if st.cfg.Accounting == nil {
+		return config.AccountingConfig{}
+	}
+	c := *st.cfg.Accounting
+	c.Rules = append([]config.AccountRule(nil), c.Rules...)
+	return c
+}
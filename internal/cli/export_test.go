@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/archive"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func TestExport_Formats(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+
+	fd := newFoodoraTestServer(t)
+	defer fd.Close()
+	cfgPath := writeMultiProviderConfig(t, fd.URL, "")
+
+	out, errOut, err := runCLI(cfgPath, []string{"export"}, "")
+	if err != nil {
+		t.Fatalf("export: %v err=%s", err, errOut)
+	}
+	want := "provider,order_id,time,vendor,status,delivered,total,currency,item_count\n" +
+		"foodora,HIST-1,2025-12-20T00:00:00Z,Test Vendor,delivered,true,12.30,EUR,0\n"
+	if out != want {
+		t.Fatalf("out=%q", out)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"export", "--format", "json", "--since", "2026-01-01"}, "")
+	if err != nil || strings.TrimSpace(out) != "[]" {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"export", "--format", "xlsx"}, ""); err == nil {
+		t.Fatalf("expected format error")
+	}
+}
+
+func TestExport_ArchivedItems(t *testing.T) {
+	arcPath := filepath.Join(t.TempDir(), "archive.json")
+	arc, _ := archive.Open(arcPath)
+	arc.Upsert("", provider.Order{
+		Provider: "foodora", ID: "A1", Vendor: "Pizza", Total: 21, Currency: "EUR",
+		Time:  time.Date(2025, 12, 20, 18, 0, 0, 0, time.UTC),
+		Items: []provider.Item{{Name: "Margherita", Quantity: 2, Price: 18}, {Name: "Cola", Quantity: 1, Price: 3}},
+	}, time.Now())
+	arc.Upsert("", provider.Order{Provider: "glovo", ID: "G1", Vendor: "Burger", Total: 9}, time.Now())
+	if err := arc.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	cfgPath := writeMultiProviderConfig(t, "", "")
+	out, errOut, err := runCLI(cfgPath, []string{"--archive", arcPath, "export", "--archived", "--format", "ndjson", "--items", "--provider", "foodora"}, "")
+	if err != nil {
+		t.Fatalf("export: %v err=%s", err, errOut)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines=%q", lines)
+	}
+	var row map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &row); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if row["item_name"] != "Margherita" || row["order_id"] != "A1" || row["item_count"] != float64(3) {
+		t.Fatalf("row=%v", row)
+	}
+}
+
+func TestExport_LedgerUsesConfigRulesAndCurrencyPreset(t *testing.T) {
+	arcPath := filepath.Join(t.TempDir(), "archive.json")
+	arc, _ := archive.Open(arcPath)
+	arc.Upsert("", provider.Order{Provider: "deliveroo", ID: "D1", Vendor: "Thai Place", Total: 15, Time: time.Date(2025, 12, 18, 12, 0, 0, 0, time.Local)}, time.Now())
+	arc.Upsert("", provider.Order{Provider: "deliveroo", ID: "D0", Vendor: "Gone", Total: 5, Status: "cancelled", Time: time.Date(2025, 12, 17, 12, 0, 0, 0, time.Local)}, time.Now())
+	if err := arc.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.New()
+	cfg.Providers.Deliveroo = &config.DeliverooConfig{Market: "uk"}
+	cfg.Accounting = &config.AccountingConfig{Rules: []config.AccountRule{{Vendor: "thai", ExpenseAccount: "Expenses:Food:Thai"}}}
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	out, errOut, err := runCLI(cfgPath, []string{"--archive", arcPath, "export", "--archived", "--format", "hledger", "--funding-account", "Liabilities:Amex"}, "")
+	if err != nil {
+		t.Fatalf("export: %v err=%s", err, errOut)
+	}
+	want := "2025-12-18 * Thai Place\n    ; provider: deliveroo\n    ; order_id: D1\n    Expenses:Food:Thai  15.00 GBP\n    Liabilities:Amex\n\n"
+	if out != want {
+		t.Fatalf("out=%q", out)
+	}
+	if !strings.Contains(errOut, "warning: skipped deliveroo D0: cancelled") {
+		t.Fatalf("err=%q", errOut)
+	}
+}
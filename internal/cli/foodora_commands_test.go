@@ -9,8 +9,10 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/foodora"
 )
 
 func TestFoodoraCLI_Flow_Login_History_Reorder(t *testing.T) {
@@ -69,8 +71,15 @@ func TestFoodoraCLI_Flow_Login_History_Reorder(t *testing.T) {
 		if err != nil {
 			t.Fatalf("order: %v", err)
 		}
-		if !strings.Contains(out, "status=200") {
-			t.Fatalf("unexpected out=%s", out)
+		for _, want := range []string{"order=OC-1", "vendor=Vendor", "status=Cooking", "rider=Max bicycle", "rider_location=48.2,16.37", "delivery_address=Main 1, 1010 Vienna"} {
+			if !strings.Contains(out, want) {
+				t.Fatalf("missing %q in out=%s", want, out)
+			}
+		}
+
+		out, _, err = runCLI(cfgPath, []string{"foodora", "order", "OC-1", "--json"}, "")
+		if err != nil || !strings.Contains(out, `"order_code": "OC-1"`) || !strings.Contains(out, `"vehicle_type": "bicycle"`) || !strings.Contains(out, `"pickup_code": "42"`) {
+			t.Fatalf("json out=%s err=%v", out, err)
 		}
 	}
 
@@ -241,7 +250,7 @@ func newFoodoraTestServer(t *testing.T) *httptest.Server {
 
 	mux.HandleFunc("/tracking/orders/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"status":200,"data":{"status_messages":{"subtitle":"Cooking"}}}`))
+		_, _ = w.Write([]byte(`{"status":200,"data":{"order_code":"OC-1","status_messages":{"subtitle":"Cooking"},"vendor":{"name":"Vendor"},"rider":{"name":"Max","vehicle_type":"bicycle","location":{"latitude":48.2,"longitude":16.37}},"delivery_address":{"formatted_address":"Main 1, 1010 Vienna"},"pickup_code":"42"}}`))
 	})
 
 	mux.HandleFunc("/orders/order_history", func(w http.ResponseWriter, r *http.Request) {
@@ -306,3 +315,38 @@ func TestDefaultWebURLForConfig(t *testing.T) {
 		t.Fatalf("expected false")
 	}
 }
+
+func TestPrintOrderTracking(t *testing.T) {
+	now := time.Date(2025, 12, 20, 18, 28, 0, 0, time.UTC)
+	tr := foodora.OrderTracking{
+		Code:   "OC-9",
+		Status: foodora.StatusMessages{Titles: []foodora.StatusTitle{{Name: "Received", Filled: true}, {Name: "Cooking", Active: true}, {Name: "On the way"}}},
+		Timeline: []foodora.TrackingEvent{
+			{Code: "1", Time: foodora.FlexibleTime{Time: now.Add(-20 * time.Minute)}},
+			{Message: "Cooking"},
+		},
+		ETA:     &foodora.TrackingETA{From: foodora.FlexibleTime{Time: now.Add(12 * time.Minute)}, To: foodora.FlexibleTime{Time: now.Add(27 * time.Minute)}},
+		Vendor:  &foodora.TrackingVendor{Name: "V", Address: "Ring 1", Phone: "+43 1"},
+		Address: &foodora.TrackingPlace{Street: "Main", City: "Vienna", Instructions: "ring twice"},
+	}
+	var buf bytes.Buffer
+	printOrderTracking(&buf, tr, now)
+	out := buf.String()
+	for _, want := range []string{
+		"status=Cooking\n",
+		"delivered=false\n",
+		"(in 12-27 min)\n",
+		"- [x] Received\n- [>] Cooking\n- [ ] On the way\n",
+		"timeline:\n- " + now.Add(-20*time.Minute).Local().Format("15:04") + " 1\n- --:-- Cooking\n",
+		"vendor_address=Ring 1 +43 1\n",
+		"delivery_address=Main, Vienna\n",
+		"delivery_instructions=ring twice\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+	if formatETA(nil, now) != "" || formatETA(&foodora.TrackingETA{To: foodora.FlexibleTime{Time: now.Add(-time.Minute)}}, now) != now.Add(-time.Minute).Local().Format("15:04")+" (in 0 min)" {
+		t.Fatalf("formatETA edge cases")
+	}
+}
@@ -1,14 +1,15 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/steipete/ordercli/internal/glovo"
+	"github.com/steipete/ordercli/internal/provider"
 )
 
 func newGlovoCmd(st *state) *cobra.Command {
@@ -33,6 +34,10 @@ func newGlovoClient(st *state) (*glovo.Client, error) {
 		cfg.DeviceURN = glovo.NewDeviceURN()
 		st.markDirty()
 	}
+	prof, err := st.headerProfile(provider.Glovo)
+	if err != nil {
+		return nil, err
+	}
 	return glovo.New(glovo.Options{
 		BaseURL:     cfg.BaseURL,
 		AccessToken: cfg.AccessToken,
@@ -42,6 +47,11 @@ func newGlovoClient(st *state) (*glovo.Client, error) {
 		Language:    cfg.Language,
 		Latitude:    cfg.Latitude,
 		Longitude:   cfg.Longitude,
+		AppVersion:  prof.AppVersion,
+		ClientInfo:  prof.ClientInfo,
+		Headers:     prof.Headers,
+		HTTPClient:  st.httpClient(provider.Glovo, 20*time.Second),
+		Schema:      st.schemaRecorder(),
 	})
 }
 
@@ -61,26 +71,51 @@ func newGlovoConfigShowCmd(st *state) *cobra.Command {
 	return &cobra.Command{
 		Use:   "show",
 		Short: "Print current Glovo config",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := st.glovo()
-			fmt.Fprintf(cmd.OutOrStdout(), "base_url=%s\n", cfg.BaseURL)
-			fmt.Fprintf(cmd.OutOrStdout(), "city_code=%s\n", cfg.CityCode)
-			fmt.Fprintf(cmd.OutOrStdout(), "country_code=%s\n", cfg.CountryCode)
-			fmt.Fprintf(cmd.OutOrStdout(), "language=%s\n", cfg.Language)
-			fmt.Fprintf(cmd.OutOrStdout(), "latitude=%v\n", cfg.Latitude)
-			fmt.Fprintf(cmd.OutOrStdout(), "longitude=%v\n", cfg.Longitude)
-			if cfg.AccessToken != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "access_token=***\n")
-			} else {
-				fmt.Fprintf(cmd.OutOrStdout(), "access_token=(not set)\n")
-			}
-			if cfg.DeviceURN != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "device_urn=*** (stored)\n")
-			}
+			view := glovoConfigView{
+				BaseURL:     cfg.BaseURL,
+				CityCode:    cfg.CityCode,
+				CountryCode: cfg.CountryCode,
+				Language:    cfg.Language,
+				Latitude:    cfg.Latitude,
+				Longitude:   cfg.Longitude,
+				AccessToken: cfg.AccessToken != "",
+				DeviceURN:   cfg.DeviceURN != "",
+			}
+			return st.emit(cmd, false, view, func(out io.Writer) {
+				fmt.Fprintf(out, "base_url=%s\n", cfg.BaseURL)
+				fmt.Fprintf(out, "city_code=%s\n", cfg.CityCode)
+				fmt.Fprintf(out, "country_code=%s\n", cfg.CountryCode)
+				fmt.Fprintf(out, "language=%s\n", cfg.Language)
+				fmt.Fprintf(out, "latitude=%v\n", cfg.Latitude)
+				fmt.Fprintf(out, "longitude=%v\n", cfg.Longitude)
+				if cfg.AccessToken != "" {
+					fmt.Fprintf(out, "access_token=***\n")
+				} else {
+					fmt.Fprintf(out, "access_token=(not set)\n")
+				}
+				if cfg.DeviceURN != "" {
+					fmt.Fprintf(out, "device_urn=*** (stored)\n")
+				}
+			})
 		},
 	}
 }
 
+// glovoConfigView is the machine-readable config; secrets only report
+// whether they are set.
+type glovoConfigView struct {
+	BaseURL     string  `json:"base_url"`
+	CityCode    string  `json:"city_code"`
+	CountryCode string  `json:"country_code"`
+	Language    string  `json:"language"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	AccessToken bool    `json:"access_token_set"`
+	DeviceURN   bool    `json:"device_urn_set"`
+}
+
 func newGlovoConfigSetCmd(st *state) *cobra.Command {
 	var cityCode, countryCode, language, baseURL string
 	var lat, lon float64
@@ -121,8 +156,7 @@ func newGlovoConfigSetCmd(st *state) *cobra.Command {
 				return fmt.Errorf("nothing to set (use --city-code, --country-code, --language, --lat, --lon, or --base-url)")
 			}
 			st.markDirty()
-			fmt.Fprintln(cmd.OutOrStdout(), "config updated")
-			return nil
+			return st.emitOK(cmd, "config updated")
 		},
 	}
 
@@ -151,9 +185,7 @@ func newGlovoSessionCmd(st *state) *cobra.Command {
 			cfg := st.glovo()
 			cfg.AccessToken = token
 			st.markDirty()
-
-			fmt.Fprintln(cmd.OutOrStdout(), "access token saved")
-			return nil
+			return st.emitOK(cmd, "access token saved")
 		},
 	}
 }
@@ -164,12 +196,12 @@ func newGlovoLogoutCmd(st *state) *cobra.Command {
 	return &cobra.Command{
 		Use:   "logout",
 		Short: "Clear stored access token",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := st.glovo()
 			cfg.AccessToken = ""
 			cfg.DeviceURN = ""
 			st.markDirty()
-			fmt.Fprintln(cmd.OutOrStdout(), "logged out")
+			return st.emitOK(cmd, "logged out")
 		},
 	}
 }
@@ -194,45 +226,38 @@ func newGlovoHistoryCmd(st *state) *cobra.Command {
 				return err
 			}
 
-			if asJSON {
-				enc := json.NewEncoder(cmd.OutOrStdout())
-				enc.SetIndent("", "  ")
-				return enc.Encode(resp)
-			}
-
-			out := cmd.OutOrStdout()
-			if len(resp.Orders) == 0 {
-				fmt.Fprintln(out, "no orders")
-				return nil
-			}
-
-			for _, o := range resp.Orders {
-				title := o.Content.Title
-				price := ""
-				if o.Footer.Left != nil {
-					price = o.Footer.Left.DataString()
+			return st.emit(cmd, asJSON, resp, func(out io.Writer) {
+				if len(resp.Orders) == 0 {
+					fmt.Fprintln(out, "no orders")
+					return
 				}
 
-				items := ""
-				if len(o.Content.Body) > 0 {
-					// Get first few items
-					itemText := o.Content.Body[0].Data
-					lines := strings.Split(itemText, "\n")
-					if len(lines) > 3 {
-						items = strings.Join(lines[:3], ", ") + "..."
-					} else {
-						items = strings.Join(lines, ", ")
+				for _, o := range resp.Orders {
+					title := o.Content.Title
+					price := ""
+					if o.Footer.Left != nil {
+						price = o.Footer.Left.DataString()
 					}
-				}
 
-				fmt.Fprintf(out, "[%d] %s - %s\n", o.OrderID, title, price)
-				if items != "" {
-					fmt.Fprintf(out, "    %s\n", items)
-				}
-				fmt.Fprintln(out)
-			}
+					items := ""
+					if len(o.Content.Body) > 0 {
+						// Get first few items
+						itemText := o.Content.Body[0].Data
+						lines := strings.Split(itemText, "\n")
+						if len(lines) > 3 {
+							items = strings.Join(lines[:3], ", ") + "..."
+						} else {
+							items = strings.Join(lines, ", ")
+						}
+					}
 
-			return nil
+					fmt.Fprintf(out, "[%d] %s - %s\n", o.OrderID, title, price)
+					if items != "" {
+						fmt.Fprintf(out, "    %s\n", items)
+					}
+					fmt.Fprintln(out)
+				}
+			})
 		},
 	}
 
@@ -267,36 +292,29 @@ func newGlovoOrderCmd(st *state) *cobra.Command {
 				return err
 			}
 
-			if asJSON {
-				enc := json.NewEncoder(cmd.OutOrStdout())
-				enc.SetIndent("", "  ")
-				return enc.Encode(order)
-			}
-
-			out := cmd.OutOrStdout()
-			fmt.Fprintf(out, "Order ID: %d\n", order.OrderID)
-			fmt.Fprintf(out, "Store: %s\n", order.Content.Title)
-			fmt.Fprintf(out, "Status: %s\n", order.LayoutType)
-			if order.Footer.Left != nil {
-				fmt.Fprintf(out, "Total: %s\n", order.Footer.Left.DataString())
-			}
-			if order.CourierName != nil {
-				fmt.Fprintf(out, "Courier: %s\n", *order.CourierName)
-			}
+			return st.emit(cmd, asJSON, order, func(out io.Writer) {
+				fmt.Fprintf(out, "Order ID: %d\n", order.OrderID)
+				fmt.Fprintf(out, "Store: %s\n", order.Content.Title)
+				fmt.Fprintf(out, "Status: %s\n", order.LayoutType)
+				if order.Footer.Left != nil {
+					fmt.Fprintf(out, "Total: %s\n", order.Footer.Left.DataString())
+				}
+				if order.CourierName != nil {
+					fmt.Fprintf(out, "Courier: %s\n", *order.CourierName)
+				}
 
-			if len(order.Content.Body) > 0 {
-				fmt.Fprintln(out, "\nItems:")
-				for _, b := range order.Content.Body {
-					lines := strings.Split(b.Data, "\n")
-					for _, line := range lines {
-						if line = strings.TrimSpace(line); line != "" {
-							fmt.Fprintf(out, "  - %s\n", line)
+				if len(order.Content.Body) > 0 {
+					fmt.Fprintln(out, "\nItems:")
+					for _, b := range order.Content.Body {
+						lines := strings.Split(b.Data, "\n")
+						for _, line := range lines {
+							if line = strings.TrimSpace(line); line != "" {
+								fmt.Fprintf(out, "  - %s\n", line)
+							}
 						}
 					}
 				}
-			}
-
-			return nil
+			})
 		},
 	}
 
@@ -322,44 +340,57 @@ func newGlovoOrdersCmd(st *state) *cobra.Command {
 
 			out := cmd.OutOrStdout()
 
+			var feed *statusFeed
 			printOrders := func() error {
 				orders, err := cl.ActiveOrders(cmd.Context())
 				if err != nil {
 					return err
 				}
-
-				if asJSON {
-					enc := json.NewEncoder(out)
-					enc.SetIndent("", "  ")
-					return enc.Encode(orders)
+				if feed != nil {
+					normalized := make([]provider.Order, 0, len(orders))
+					for _, o := range orders {
+						normalized = append(normalized, provider.GlovoOrder(o))
+					}
+					feed.update(cmd.Context(), provider.Glovo, normalized, time.Now())
 				}
 
-				if len(orders) == 0 {
-					fmt.Fprintln(out, "no active orders")
-					return nil
-				}
+				return st.emit(cmd, asJSON, orders, func(out io.Writer) {
+					if len(orders) == 0 {
+						fmt.Fprintln(out, "no active orders")
+						return
+					}
 
-				for _, o := range orders {
-					title := o.Content.Title
-					status := o.LayoutType
+					for _, o := range orders {
+						title := o.Content.Title
+						status := o.LayoutType
 
-					fmt.Fprintf(out, "[%d] %s\n", o.OrderID, title)
-					fmt.Fprintf(out, "    Status: %s\n", status)
-					if o.CourierName != nil {
-						fmt.Fprintf(out, "    Courier: %s\n", *o.CourierName)
+						fmt.Fprintf(out, "[%d] %s\n", o.OrderID, title)
+						fmt.Fprintf(out, "    Status: %s\n", status)
+						if o.CourierName != nil {
+							fmt.Fprintf(out, "    Courier: %s\n", *o.CourierName)
+						}
+						fmt.Fprintln(out)
 					}
-					fmt.Fprintln(out)
-				}
-				return nil
+				})
 			}
 
 			if watch {
+				if feed, err = st.newStatusFeed(cmd); err != nil {
+					return err
+				}
+				structured := st.structured(asJSON)
 				for {
-					// Clear screen for fresh output
-					fmt.Fprint(out, "\033[2J\033[H")
-					fmt.Fprintf(out, "Active Orders (refreshing every %ds, Ctrl+C to stop)\n\n", interval)
+					if !structured {
+						// Clear screen for fresh output
+						fmt.Fprint(out, "\033[2J\033[H")
+						fmt.Fprintf(out, "Active Orders (refreshing every %ds, Ctrl+C to stop)\n\n", interval)
+					}
 					if err := printOrders(); err != nil {
-						fmt.Fprintf(out, "Error: %v\n", err)
+						if structured {
+							fmt.Fprintf(cmd.ErrOrStderr(), "warning: glovo: %v\n", err)
+						} else {
+							fmt.Fprintf(out, "Error: %v\n", err)
+						}
 					}
 					select {
 					case <-cmd.Context().Done():
@@ -405,39 +436,32 @@ func newGlovoCartCmd(st *state) *cobra.Command {
 				return err
 			}
 
-			if asJSON {
-				enc := json.NewEncoder(cmd.OutOrStdout())
-				enc.SetIndent("", "  ")
-				return enc.Encode(baskets)
-			}
-
-			out := cmd.OutOrStdout()
-			if len(baskets) == 0 {
-				fmt.Fprintln(out, "cart is empty")
-				return nil
-			}
-
-			for _, b := range baskets {
-				fmt.Fprintf(out, "Store: %s (ID: %d)\n", b.StoreName, b.StoreID)
-				fmt.Fprintf(out, "  Items:\n")
-				for _, p := range b.Products {
-					fmt.Fprintf(out, "    %dx %s - %.2f %s\n", p.Quantity, p.Name, p.TotalPrice, b.Currency)
+			return st.emit(cmd, asJSON, baskets, func(out io.Writer) {
+				if len(baskets) == 0 {
+					fmt.Fprintln(out, "cart is empty")
+					return
 				}
-				fmt.Fprintf(out, "  Subtotal: %.2f %s\n", b.SubTotal, b.Currency)
-				if b.DeliveryFee > 0 {
-					fmt.Fprintf(out, "  Delivery: %.2f %s\n", b.DeliveryFee, b.Currency)
-				}
-				if b.ServiceFee > 0 {
-					fmt.Fprintf(out, "  Service: %.2f %s\n", b.ServiceFee, b.Currency)
-				}
-				fmt.Fprintf(out, "  Total: %.2f %s\n", b.Total, b.Currency)
-				if !b.IsMinOrderMet {
-					fmt.Fprintf(out, "  ! Min order: %.2f %s\n", b.MinOrderValue, b.Currency)
-				}
-				fmt.Fprintln(out)
-			}
 
-			return nil
+				for _, b := range baskets {
+					fmt.Fprintf(out, "Store: %s (ID: %d)\n", b.StoreName, b.StoreID)
+					fmt.Fprintf(out, "  Items:\n")
+					for _, p := range b.Products {
+						fmt.Fprintf(out, "    %dx %s - %.2f %s\n", p.Quantity, p.Name, p.TotalPrice, b.Currency)
+					}
+					fmt.Fprintf(out, "  Subtotal: %.2f %s\n", b.SubTotal, b.Currency)
+					if b.DeliveryFee > 0 {
+						fmt.Fprintf(out, "  Delivery: %.2f %s\n", b.DeliveryFee, b.Currency)
+					}
+					if b.ServiceFee > 0 {
+						fmt.Fprintf(out, "  Service: %.2f %s\n", b.ServiceFee, b.Currency)
+					}
+					fmt.Fprintf(out, "  Total: %.2f %s\n", b.Total, b.Currency)
+					if !b.IsMinOrderMet {
+						fmt.Fprintf(out, "  ! Min order: %.2f %s\n", b.MinOrderValue, b.Currency)
+					}
+					fmt.Fprintln(out)
+				}
+			})
 		},
 	}
 
@@ -464,24 +488,17 @@ func newGlovoMeCmd(st *state) *cobra.Command {
 				return err
 			}
 
-			if asJSON {
-				enc := json.NewEncoder(cmd.OutOrStdout())
-				enc.SetIndent("", "  ")
-				return enc.Encode(user)
-			}
-
-			out := cmd.OutOrStdout()
-			fmt.Fprintf(out, "ID: %d\n", user.ID)
-			fmt.Fprintf(out, "Name: %s\n", user.Name)
-			fmt.Fprintf(out, "Email: %s\n", user.Email)
-			if user.PhoneNumber != nil {
-				fmt.Fprintf(out, "Phone: %s\n", user.PhoneNumber.Number)
-			}
-			fmt.Fprintf(out, "City: %s\n", user.PreferredCityCode)
-			fmt.Fprintf(out, "Language: %s\n", user.PreferredLanguage)
-			fmt.Fprintf(out, "Orders: %d\n", user.DeliveredOrdersCount)
-
-			return nil
+			return st.emit(cmd, asJSON, user, func(out io.Writer) {
+				fmt.Fprintf(out, "ID: %d\n", user.ID)
+				fmt.Fprintf(out, "Name: %s\n", user.Name)
+				fmt.Fprintf(out, "Email: %s\n", user.Email)
+				if user.PhoneNumber != nil {
+					fmt.Fprintf(out, "Phone: %s\n", user.PhoneNumber.Number)
+				}
+				fmt.Fprintf(out, "City: %s\n", user.PreferredCityCode)
+				fmt.Fprintf(out, "Language: %s\n", user.PreferredLanguage)
+				fmt.Fprintf(out, "Orders: %d\n", user.DeliveredOrdersCount)
+			})
 		},
 	}
 
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -36,13 +37,12 @@ func newHistoryCmd(st *state) *cobra.Command {
 				ps = 100
 			}
 
-			out := cmd.OutOrStdout()
 			ctx := cmd.Context()
 
+			items := []foodora.OrderHistoryItem{}
 			offset := 0
-			printed := 0
-			for printed < limit {
-				reqLimit := min(ps, limit-printed)
+			for len(items) < limit {
+				reqLimit := min(ps, limit-len(items))
 				resp, err := c.OrderHistory(ctx, foodora.OrderHistoryRequest{
 					Include:        include,
 					Offset:         offset,
@@ -52,36 +52,34 @@ func newHistoryCmd(st *state) *cobra.Command {
 				if err != nil {
 					return err
 				}
-
 				if len(resp.Data.Items) == 0 {
-					if printed == 0 {
-						fmt.Fprintln(out, "no past orders")
-					}
-					return nil
+					break
+				}
+				items = append(items, resp.Data.Items[:min(len(resp.Data.Items), limit-len(items))]...)
+
+				offset += len(resp.Data.Items)
+				if resp.Data.TotalCount > 0 && offset >= int(resp.Data.TotalCount) {
+					break
+				}
+				if len(resp.Data.Items) < reqLimit {
+					break
 				}
+			}
 
-				for _, o := range resp.Data.Items {
-					if printed >= limit {
-						return nil
-					}
+			return st.emit(cmd, false, items, func(out io.Writer) {
+				if len(items) == 0 {
+					fmt.Fprintln(out, "no past orders")
+					return
+				}
+				for _, o := range items {
 					fmt.Fprintf(out, "%s\t%s\t%s\t%s\n",
 						o.OrderCode,
 						historyVendor(o.Vendor),
 						historyStatus(o.CurrentStatus),
 						historyTime(o.ConfirmedDeliveryTime),
 					)
-					printed++
-				}
-
-				offset += len(resp.Data.Items)
-				if resp.Data.TotalCount > 0 && offset >= int(resp.Data.TotalCount) {
-					return nil
-				}
-				if len(resp.Data.Items) < reqLimit {
-					return nil
 				}
-			}
-			return nil
+			})
 		},
 	}
 
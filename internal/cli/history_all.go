@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/archive"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func newAllHistoryCmd(st *state) *cobra.Command {
+	var limit int
+	var only []string
+	var asJSON bool
+	var archived bool
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List past orders from every configured provider (newest first)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var orders []provider.Order
+			if archived {
+				var err error
+				if orders, err = archivedHistory(st, only, limit); err != nil {
+					return err
+				}
+			} else {
+				names, err := st.selectProviders(only)
+				if err != nil {
+					return err
+				}
+				if orders, err = fetchAllHistory(cmd, st, names, limit); err != nil {
+					return err
+				}
+			}
+
+			for i := range orders {
+				orders[i].Raw = nil
+			}
+			return st.emit(cmd, asJSON, orders, func(out io.Writer) {
+				if len(orders) == 0 {
+					fmt.Fprintln(out, "no past orders")
+					return
+				}
+				printOrderRows(out, orders)
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "max orders per provider")
+	cmd.Flags().StringSliceVar(&only, "provider", nil, "only these providers (repeatable; default: all configured)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print normalized JSON")
+	cmd.Flags().BoolVar(&archived, "archived", false, "read from the local archive (see ordercli sync) instead of the APIs")
+	return cmd
+}
+
+// archivedHistory reads orders from the local archive. Providers need not be
+// configured, so orders from old logins remain listable.
+func archivedHistory(st *state, only []string, limit int) ([]provider.Order, error) {
+	var filter archive.Filter
+	for _, name := range only {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(provider.Names, name) {
+			return nil, unknownProviderError(name)
+		}
+		filter.Providers = append(filter.Providers, name)
+	}
+	arc, err := st.openArchive()
+	if err != nil {
+		return nil, err
+	}
+	perProvider := map[string]int{}
+	var out []provider.Order
+	for _, r := range arc.Orders(filter) {
+		if limit > 0 && perProvider[r.Provider] >= limit {
+			continue
+		}
+		perProvider[r.Provider]++
+		out = append(out, r.Order)
+	}
+	return out, nil
+}
+
+// fetchAllHistory collects history from each provider concurrently and merges
+// it newest first. Failing providers are reported as warnings on stderr; only
+// when every provider fails is an error returned.
+func fetchAllHistory(cmd *cobra.Command, st *state, names []string, limit int) ([]provider.Order, error) {
+	type result struct {
+		orders []provider.Order
+		err    error
+	}
+	results := make([]result, len(names))
+
+	ctx := cmd.Context()
+	var wg sync.WaitGroup
+	for i, name := range names {
+		// Opening may refresh tokens and touch state, so it stays sequential.
+		p, err := openProvider(st, name)
+		if err != nil {
+			results[i].err = err
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			orders, err := provider.Collect(ctx, p, limit, 20)
+			results[i] = result{orders: orders, err: err}
+		}()
+	}
+	wg.Wait()
+
+	var merged []provider.Order
+	failed := 0
+	for i, r := range results {
+		if r.err != nil {
+			failed++
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %v\n", names[i], r.err)
+		}
+		merged = append(merged, r.orders...)
+	}
+	if failed == len(names) && len(merged) == 0 {
+		return nil, errors.New("history: every provider failed")
+	}
+	sortOrdersNewestFirst(merged)
+	return merged, nil
+}
+
+// sortOrdersNewestFirst orders by time descending; undated orders go last.
+func sortOrdersNewestFirst(orders []provider.Order) {
+	sort.SliceStable(orders, func(i, j int) bool {
+		a, b := orders[i].Time, orders[j].Time
+		if a.IsZero() != b.IsZero() {
+			return !a.IsZero()
+		}
+		return a.After(b)
+	})
+}
+
+func printOrderRows(out io.Writer, orders []provider.Order) {
+	for _, o := range orders {
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			orderTime(o.Time),
+			o.Provider,
+			o.ID,
+			o.Vendor,
+			o.Status,
+			formatAmount(o.Total, o.Currency),
+		)
+	}
+}
+
+func orderTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.In(time.Local).Format(time.RFC3339)
+}
+
+func formatAmount(v float64, currency string) string {
+	if v == 0 {
+		return ""
+	}
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	if currency != "" {
+		s += " " + currency
+	}
+	return s
+}
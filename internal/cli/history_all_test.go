@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func writeMultiProviderConfig(t *testing.T, foodoraURL, glovoURL string) string {
+	t.Helper()
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.New()
+	if foodoraURL != "" {
+		cfg.Providers.Foodora = &config.FoodoraConfig{
+			BaseURL:          foodoraURL + "/",
+			TargetCountryISO: "AT",
+			AccessToken:      "access",
+			RefreshToken:     "refresh",
+			ExpiresAt:        time.Now().Add(time.Hour),
+		}
+	}
+	if glovoURL != "" {
+		cfg.Providers.Glovo = &config.GlovoConfig{BaseURL: glovoURL, AccessToken: "glovo-token"}
+	}
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	return cfgPath
+}
+
+func TestAllHistory_MergesAndWarns(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+
+	fd := newFoodoraTestServer(t)
+	defer fd.Close()
+	gl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "expired", http.StatusUnauthorized)
+	}))
+	defer gl.Close()
+
+	cfgPath := writeMultiProviderConfig(t, fd.URL, gl.URL)
+	out, errOut, err := runCLI(cfgPath, []string{"history", "--limit", "5"}, "")
+	if err != nil {
+		t.Fatalf("history: %v err=%s", err, errOut)
+	}
+	if !strings.Contains(out, "\tfoodora\tHIST-1\tTest Vendor\tdelivered\t12.30 EUR") {
+		t.Fatalf("unexpected out=%q", out)
+	}
+	if !strings.Contains(errOut, "warning: glovo:") || !strings.Contains(errOut, "401") {
+		t.Fatalf("expected glovo warning, err=%q", errOut)
+	}
+}
+
+func TestAllHistory_AllFailOrNoneConfigured(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+
+	_, _, err := runCLI(writeMultiProviderConfig(t, "", ""), []string{"history"}, "")
+	if err == nil || !strings.Contains(err.Error(), "no providers configured") {
+		t.Fatalf("err=%v", err)
+	}
+
+	gl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer gl.Close()
+	_, _, err = runCLI(writeMultiProviderConfig(t, "", gl.URL), []string{"history"}, "")
+	if err == nil || !strings.Contains(err.Error(), "every provider failed") {
+		t.Fatalf("err=%v", err)
+	}
+
+	_, _, err = runCLI(writeMultiProviderConfig(t, "", gl.URL), []string{"history", "--provider", "foodora"}, "")
+	if err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestSortOrdersNewestFirst(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	orders := []provider.Order{
+		{ID: "a", Time: base.Add(time.Hour)},
+		{ID: "b"},
+		{ID: "c", Time: base.Add(3 * time.Hour)},
+	}
+	sortOrdersNewestFirst(orders)
+	if orders[0].ID != "c" || orders[1].ID != "a" || orders[2].ID != "b" {
+		t.Fatalf("order=%v,%v,%v", orders[0].ID, orders[1].ID, orders[2].ID)
+	}
+}
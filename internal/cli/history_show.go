@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -42,17 +41,7 @@ func newHistoryShowCmd(st *state) *cobra.Command {
 			}
 
 			item := resp.Data.Items[0]
-			out := cmd.OutOrStdout()
-
-			if asJSON {
-				b, _ := json.MarshalIndent(item, "", "  ")
-				b = append(b, '\n')
-				_, _ = out.Write(b)
-				return nil
-			}
-
-			printHistoryDetail(out, item)
-			return nil
+			return st.emit(cmd, asJSON, item, func(out io.Writer) { printHistoryDetail(out, item) })
 		},
 	}
 
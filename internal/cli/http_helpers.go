@@ -1,15 +1,14 @@
 package cli
 
 import (
+	"fmt"
 	"net/url"
 	"strings"
-)
+	"time"
 
-type appHeaderProfile struct {
-	FPAPIKey  string
-	AppName   string
-	UserAgent string
-}
+	"github.com/steipete/ordercli/internal/profiles"
+	"github.com/steipete/ordercli/internal/provider"
+)
 
 func cookieHost(baseURL string) string {
 	u, err := url.Parse(baseURL)
@@ -28,15 +27,39 @@ func (s *state) cookieHeaderForBaseURL() (host string, cookie string) {
 	return host, strings.TrimSpace(cfg.CookiesByHost[host])
 }
 
-func (s *state) appHeaders() appHeaderProfile {
+// storeCookies saves the cookie header captured for host and when.
+func (s *state) storeCookies(host, header string) {
 	cfg := s.foodora()
-	p := appHeaderProfile{
-		FPAPIKey: "android",
+	host = strings.ToLower(host)
+	if cfg.CookiesByHost == nil {
+		cfg.CookiesByHost = map[string]string{}
 	}
-	if strings.EqualFold(cfg.TargetCountryISO, "AT") || strings.HasPrefix(strings.ToUpper(cfg.GlobalEntityID), "MJM_") || strings.Contains(strings.ToLower(cfg.BaseURL), "mj.fd-api.com") {
-		p.AppName = "at.mjam"
-		// From the provided at.mjam APKM (v25.3.0 / build 250300134).
-		p.UserAgent = "Android-app-25.3.0(250300134)"
+	if cfg.CookiesSavedAt == nil {
+		cfg.CookiesSavedAt = map[string]time.Time{}
+	}
+	cfg.CookiesByHost[host] = header
+	cfg.CookiesSavedAt[host] = time.Now().UTC()
+	s.markDirty()
+}
+
+// headerProfile is the header profile a provider client uses: the first
+// matching header_profiles rule, else the built-in choice (mjam in Austria).
+func (s *state) headerProfile(name string) (profiles.Profile, error) {
+	t := profiles.Target{Provider: name, Account: accountKey(s, name)}
+	var fallback string
+	switch name {
+	case provider.Foodora:
+		cfg := s.foodora()
+		t.Country = cfg.TargetCountryISO
+		fallback = profiles.FoodoraAndroid
+		if strings.EqualFold(cfg.TargetCountryISO, "AT") || strings.HasPrefix(strings.ToUpper(cfg.GlobalEntityID), "MJM_") || strings.Contains(strings.ToLower(cfg.BaseURL), "mj.fd-api.com") {
+			fallback = profiles.MjamAndroid
+		}
+	case provider.Glovo:
+		t.Country = s.glovo().CountryCode
+		fallback = profiles.GlovoWeb
+	default:
+		return profiles.Profile{}, fmt.Errorf("no header profiles for %s", name)
 	}
-	return p
+	return profiles.Select(s.cfg.HeaderProfiles, t, fallback)
 }
@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/glovo"
+	"github.com/steipete/ordercli/internal/profiles"
+	"github.com/steipete/ordercli/internal/provider"
 )
 
 func TestCookieHost(t *testing.T) {
@@ -15,13 +19,56 @@ func TestCookieHost(t *testing.T) {
 	}
 }
 
-func TestAppHeaders_AT(t *testing.T) {
+func TestHeaderProfile_AT(t *testing.T) {
 	st := &state{}
 	st.cfg = config.New()
 	cfg := st.foodora()
 	cfg.TargetCountryISO = "AT"
-	p := st.appHeaders()
-	if p.AppName != "at.mjam" || p.FPAPIKey == "" || p.UserAgent == "" {
+	p, err := st.headerProfile(provider.Foodora)
+	if err != nil {
+		t.Fatalf("profile: %v", err)
+	}
+	if p.Name != profiles.MjamAndroid || p.AppName != "at.mjam" || p.FPAPIKey == "" || p.UserAgent == "" {
 		t.Fatalf("unexpected: %#v", p)
 	}
 }
+
+func TestHeaderProfile_Rules(t *testing.T) {
+	st := &state{}
+	st.cfg = config.New()
+	st.foodora().TargetCountryISO = "HU"
+	st.glovo().CountryCode = "ES"
+	st.cfg.HeaderProfiles = &config.HeaderProfilesConfig{
+		Profiles: map[string]config.HeaderProfile{
+			"glovo-next":      {Provider: provider.Glovo, AppVersion: "v1.1800.0"},
+			profiles.GlovoWeb: {ClientInfo: "custom"},
+		},
+		Rules: []config.HeaderProfileRule{
+			{Country: "es", Profile: "glovo-next"},
+			{Provider: provider.Foodora, Country: "AT", Profile: profiles.MjamAndroid},
+		},
+	}
+	if err := profiles.Validate(st.cfg.HeaderProfiles); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if p, _ := st.headerProfile(provider.Glovo); p.Name != "glovo-next" || p.AppVersion != "v1.1800.0" {
+		t.Fatalf("glovo: %#v", p)
+	}
+	// The ES rule names a glovo profile, so foodora falls through to the default.
+	if p, _ := st.headerProfile(provider.Foodora); p.Name != profiles.FoodoraAndroid || p.AppName != "" {
+		t.Fatalf("foodora: %#v", p)
+	}
+	st.glovo().CountryCode = "IT"
+	if p, _ := st.headerProfile(provider.Glovo); p.Name != profiles.GlovoWeb || p.Source != profiles.SourceOverride || p.ClientInfo != "custom" || p.AppVersion != glovo.DefaultAppVersion {
+		t.Fatalf("glovo override: %#v", p)
+	}
+
+	st.cfg.HeaderProfiles.Rules = append(st.cfg.HeaderProfiles.Rules, config.HeaderProfileRule{Profile: "missing"})
+	if err := profiles.Validate(st.cfg.HeaderProfiles); err == nil || !strings.Contains(err.Error(), `unknown profile "missing"`) {
+		t.Fatalf("validate: %v", err)
+	}
+	st.cfg.HeaderProfiles.Rules = []config.HeaderProfileRule{{Provider: provider.Glovo, Profile: profiles.MjamAndroid}}
+	if err := profiles.Validate(st.cfg.HeaderProfiles); err == nil {
+		t.Fatalf("expected provider mismatch error")
+	}
+}
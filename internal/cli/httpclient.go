@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/notify"
+	"github.com/steipete/ordercli/internal/transport"
+	"github.com/steipete/ordercli/internal/vault"
+)
+
+// httpClient is the client every provider API client is built with, so
+// transport-level behaviour is configured in one place. The base transport
+// stays nil (http.DefaultTransport at call time) unless something wraps it.
+//
+// Layers, outermost first: retries, the per-host rate limiter (so every
+// attempt takes a token), metrics (which see every attempt), the
+// --debug-http trace, then the --record/--replay cassette. Replays skip the
+// rate limiter; nothing goes over the network.
+func (s *state) httpClient(provider string, timeout time.Duration) *http.Client {
+	rt := s.base
+	if s.cassette != nil {
+		rt = s.cassette
+	}
+	if s.debugOut != nil {
+		rt = &transport.Debug{Next: rt, Out: s.debugOut}
+	}
+	rt = s.metrics.transport(provider, rt)
+	if !s.replaying {
+		rt = &transport.RateLimited{
+			Next:    rt,
+			Limiter: s.rateLimiter(),
+			OnDelay: func(req *http.Request, waited time.Duration) {
+				s.logf("rate limit: %s %s delayed %s", req.Method, req.URL.Host, waited.Round(time.Millisecond))
+			},
+		}
+	}
+	rt = &transport.Retry{
+		Next:   rt,
+		Policy: s.retryPolicy(provider),
+		OnRetry: func(req *http.Request, attempt int, wait time.Duration, reason string) {
+			s.logf("retry: %s %s%s failed (%s); attempt %d in %s", req.Method, req.URL.Host, req.URL.Path, reason, attempt+1, wait.Round(time.Millisecond))
+		},
+	}
+	if s.timeout > 0 {
+		timeout = s.timeout
+	}
+	return &http.Client{Timeout: timeout, Transport: rt}
+}
+
+// notifier sends to the configured sinks over the same proxy, CA bundle,
+// timeout and interface as the provider clients. Retries, rate limits and
+// cassettes are left out: they are about provider APIs, not sink deliveries.
+func (s *state) notifier() (*notify.Notifier, error) {
+	var rt http.RoundTripper = s.base
+	if s.debugOut != nil {
+		rt = &transport.Debug{Next: rt, Out: s.debugOut}
+	}
+	return notify.New(s.cfg.Notify, notify.Options{
+		HTTPClient: &http.Client{Transport: rt},
+		Timeout:    s.timeout,
+	})
+}
+
+// netFlags are the connection settings given on the command line; they
+// override (CA certs: extend) the http config section.
+type netFlags struct {
+	proxy   string
+	caCerts []string
+	timeout time.Duration
+	iface   string
+}
+
+// setupNetwork builds the base transport under every client from the http
+// config and flags. Without proxy, CA, connect timeout or interface
+// settings it stays nil, i.e. http.DefaultTransport.
+func (s *state) setupNetwork(f netFlags) error {
+	var c config.HTTPConfig
+	if s.cfg.HTTP != nil {
+		c = *s.cfg.HTTP
+	}
+	if f.proxy != "" {
+		c.Proxy = f.proxy
+	}
+	if f.iface != "" {
+		c.Interface = f.iface
+	}
+	caCerts := slices.Concat(c.CACerts, f.caCerts)
+
+	var n transport.Network
+	// Commands that keep an encrypted config locked do not go online and
+	// cannot read a sealed proxy.
+	if c.Proxy != "" && !vault.IsSealed(c.Proxy) {
+		u, err := transport.ParseProxy(c.Proxy)
+		if err != nil {
+			return err
+		}
+		n.Proxy = u
+		s.proxy = u.String()
+	}
+	if len(caCerts) > 0 {
+		pool, err := transport.LoadRootCAs(caCerts)
+		if err != nil {
+			return err
+		}
+		n.RootCAs = pool
+		s.rootCAs = pool
+	}
+	if c.Interface != "" {
+		addr, err := transport.LocalAddr(c.Interface)
+		if err != nil {
+			return err
+		}
+		n.LocalAddr = addr
+	}
+	// Durations were checked by validateHTTPConfig.
+	n.ConnectTimeout, _ = parseDuration("connect_timeout", c.ConnectTimeout)
+	s.timeout, _ = parseDuration("timeout", c.Timeout)
+	if f.timeout > 0 {
+		s.timeout = f.timeout
+	}
+	if n != (transport.Network{}) {
+		s.base = transport.NewTransport(n)
+	}
+	return nil
+}
+
+// browserProxy is the proxy handed to the Playwright helpers: the
+// configured one, else the environment's, which Chromium ignores on its own.
+func (s *state) browserProxy() string {
+	if s.proxy != "" {
+		return s.proxy
+	}
+	for _, k := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if v := strings.TrimSpace(os.Getenv(k)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// rateLimiter is the process-wide limiter, configured from http.rate_limit.
+func (s *state) rateLimiter() *transport.Limiter {
+	s.limiterOnce.Do(func() {
+		var limits map[string]config.RateLimit
+		if s.cfg.HTTP != nil {
+			limits = s.cfg.HTTP.RateLimit
+		}
+		s.limiter = &transport.Limiter{RateFor: func(host string) transport.Rate {
+			rl, ok := limits[host]
+			if !ok {
+				if h, _, err := net.SplitHostPort(host); err == nil {
+					rl, ok = limits[h]
+				}
+			}
+			if !ok {
+				rl = limits["default"]
+			}
+			if rl.Disabled {
+				return transport.Rate{}
+			}
+			r := transport.DefaultRate
+			if rl.RequestsPerSecond > 0 {
+				r.PerSecond = rl.RequestsPerSecond
+			}
+			if rl.Burst > 0 {
+				r.Burst = rl.Burst
+			}
+			return r
+		}}
+	})
+	return s.limiter
+}
+
+// retryPolicy is the http.retry config entry of provider, falling back to
+// the "default" entry and then to transport.DefaultPolicy. Invalid
+// durations were rejected by validateHTTPConfig at load time.
+func (s *state) retryPolicy(provider string) transport.Policy {
+	if s.cfg.HTTP == nil {
+		return transport.Policy{}
+	}
+	rp, ok := s.cfg.HTTP.Retry[provider]
+	if !ok {
+		rp = s.cfg.HTTP.Retry["default"]
+	}
+	pol, _ := parseRetryPolicy(rp)
+	return pol
+}
+
+func parseRetryPolicy(rp config.RetryPolicy) (transport.Policy, error) {
+	pol := transport.Policy{MaxAttempts: rp.MaxAttempts}
+	var err error
+	if pol.BaseDelay, err = parseDuration("base_delay", rp.BaseDelay); err != nil {
+		return pol, err
+	}
+	if pol.MaxDelay, err = parseDuration("max_delay", rp.MaxDelay); err != nil {
+		return pol, err
+	}
+	return pol, nil
+}
+
+func parseDuration(field, v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s: invalid duration %q", field, v)
+	}
+	return d, nil
+}
+
+// validateHTTPConfig reports config mistakes early instead of silently
+// falling back to defaults.
+func validateHTTPConfig(c *config.HTTPConfig) error {
+	if c == nil {
+		return nil
+	}
+	// A sealed proxy is checked by setupNetwork once the config is unlocked.
+	if c.Proxy != "" && !vault.IsSealed(c.Proxy) {
+		if _, err := transport.ParseProxy(c.Proxy); err != nil {
+			return fmt.Errorf("config: http.%w", err)
+		}
+	}
+	if _, err := parseDuration("timeout", c.Timeout); err != nil {
+		return fmt.Errorf("config: http.%w", err)
+	}
+	if _, err := parseDuration("connect_timeout", c.ConnectTimeout); err != nil {
+		return fmt.Errorf("config: http.%w", err)
+	}
+	for host, rl := range c.RateLimit {
+		if rl.RequestsPerSecond < 0 || rl.Burst < 0 {
+			return fmt.Errorf("config: http.rate_limit.%s: requests_per_second and burst must not be negative", host)
+		}
+	}
+	for name, rp := range c.Retry {
+		if rp.MaxAttempts < 0 {
+			return fmt.Errorf("config: http.retry.%s.max_attempts must not be negative", name)
+		}
+		if _, err := parseRetryPolicy(rp); err != nil {
+			return fmt.Errorf("config: http.retry.%s.%w", name, err)
+		}
+	}
+	return nil
+}
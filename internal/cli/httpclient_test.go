@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/foodora"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func TestHTTPClient_RetriesReadsButNotReorders(t *testing.T) {
+	var gets, posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			posts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if gets.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"active_orders":[]}}`))
+	}))
+	defer srv.Close()
+
+	st := &state{}
+	st.cfg.HTTP = &config.HTTPConfig{Retry: map[string]config.RetryPolicy{
+		provider.Foodora: {MaxAttempts: 5, BaseDelay: "1ms", MaxDelay: "5ms"},
+	}}
+	c, err := foodora.New(foodora.Options{BaseURL: srv.URL + "/", AccessToken: "t", HTTPClient: st.httpClient(provider.Foodora, 5*time.Second)})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if _, err := c.ActiveOrders(context.Background()); err != nil {
+		t.Fatalf("active orders: %v", err)
+	}
+	if gets.Load() != 2 {
+		t.Fatalf("gets=%d", gets.Load())
+	}
+	if _, err := c.OrderReorder(context.Background(), "OC-1", foodora.ReorderRequestBody{}); err == nil {
+		t.Fatalf("expected reorder error")
+	}
+	if posts.Load() != 1 {
+		t.Fatalf("reorder sent %d times", posts.Load())
+	}
+}
+
+func TestRetryPolicy_Config(t *testing.T) {
+	st := &state{}
+	if pol := st.retryPolicy(provider.Glovo); pol.MaxAttempts != 0 {
+		t.Fatalf("no config: %+v", pol)
+	}
+	st.cfg.HTTP = &config.HTTPConfig{Retry: map[string]config.RetryPolicy{
+		"default":        {MaxAttempts: 2, MaxDelay: "3s"},
+		provider.Foodora: {MaxAttempts: 1},
+	}}
+	if pol := st.retryPolicy(provider.Glovo); pol.MaxAttempts != 2 || pol.MaxDelay != 3*time.Second {
+		t.Fatalf("default: %+v", pol)
+	}
+	if pol := st.retryPolicy(provider.Foodora); pol.MaxAttempts != 1 || pol.MaxDelay != 0 {
+		t.Fatalf("foodora: %+v", pol)
+	}
+
+	for _, bad := range []config.RetryPolicy{{MaxAttempts: -1}, {BaseDelay: "soon"}, {MaxDelay: "-1s"}} {
+		if err := validateHTTPConfig(&config.HTTPConfig{Retry: map[string]config.RetryPolicy{"glovo": bad}}); err == nil {
+			t.Fatalf("%+v: expected error", bad)
+		}
+	}
+
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(cfgPath, []byte(`{"version":1,"providers":{},"http":{"retry":{"glovo":{"base_delay":"fast"}}}}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "config", "show"}, ""); err == nil || !strings.Contains(err.Error(), "http.retry.glovo.base_delay") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestVerbose_ReportsRetriesAndRateLimitDelays(t *testing.T) {
+	var calls atomic.Int32
+	gl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"orders":[]}`))
+	}))
+	defer gl.Close()
+	cfgPath := writeMultiProviderConfig(t, "", gl.URL)
+	cfg, _ := config.Load(cfgPath)
+	cfg.HTTP = &config.HTTPConfig{
+		Retry:     map[string]config.RetryPolicy{"default": {BaseDelay: "1ms"}},
+		RateLimit: map[string]config.RateLimit{"127.0.0.1": {RequestsPerSecond: 50, Burst: 1}},
+	}
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	_, stderr, err := runCLI(cfgPath, []string{"-v", "glovo", "orders"}, "")
+	if err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	for _, want := range []string{"retry: GET 127.0.0.1:", "HTTP 502); attempt 2 in", "rate limit: GET 127.0.0.1:"} {
+		if !strings.Contains(stderr, want) {
+			t.Fatalf("stderr missing %q: %q", want, stderr)
+		}
+	}
+	calls.Store(0)
+	if _, stderr, _ = runCLI(cfgPath, []string{"glovo", "orders"}, ""); stderr != "" {
+		t.Fatalf("quiet run logged: %q", stderr)
+	}
+}
+
+func TestRateLimiter_Config(t *testing.T) {
+	st := &state{}
+	st.cfg.HTTP = &config.HTTPConfig{RateLimit: map[string]config.RateLimit{
+		"default":          {Burst: 2},
+		"api.glovoapp.com": {RequestsPerSecond: 0.5},
+		"localhost:8080":   {Disabled: true},
+	}}
+	rate := st.rateLimiter().RateFor
+	if r := rate("mj.fd-api.com"); r.PerSecond != 2 || r.Burst != 2 {
+		t.Fatalf("default: %+v", r)
+	}
+	if r := rate("api.glovoapp.com:443"); r.PerSecond != 0.5 || r.Burst != 6 {
+		t.Fatalf("host: %+v", r)
+	}
+	if r := rate("localhost:8080"); r.PerSecond != 0 {
+		t.Fatalf("disabled: %+v", r)
+	}
+	if st.rateLimiter() != st.rateLimiter() {
+		t.Fatalf("limiter not shared")
+	}
+	if err := validateHTTPConfig(&config.HTTPConfig{RateLimit: map[string]config.RateLimit{"x": {Burst: -1}}}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	setEnv(t, "FOODORA_CLIENT_SECRET", "secret")
+
+	srv := newFoodoraTestServer(t)
+	cfgPath := writeMultiProviderConfig(t, srv.URL, "")
+	dir := filepath.Join(t.TempDir(), "cassette")
+
+	recorded, _, err := runCLI(cfgPath, []string{"--record", dir, "foodora", "orders"}, "")
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	srv.Close()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("cassette: %v %v", entries, err)
+	}
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if strings.Contains(string(b), "Bearer access") {
+			t.Fatalf("token recorded in %s:\n%s", e.Name(), b)
+		}
+	}
+
+	before, _ := os.ReadFile(cfgPath)
+	replayed, _, err := runCLI(cfgPath, []string{"--replay", dir, "foodora", "orders"}, "")
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed != recorded || !strings.Contains(replayed, "OC-1") {
+		t.Fatalf("replayed:\n%s\nrecorded:\n%s", replayed, recorded)
+	}
+	if after, _ := os.ReadFile(cfgPath); string(after) != string(before) {
+		t.Fatalf("replay rewrote the config")
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"--replay", t.TempDir(), "foodora", "orders"}, ""); err == nil || !strings.Contains(err.Error(), "no recordings") {
+		t.Fatalf("empty cassette: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"--record", dir, "--replay", dir, "foodora", "orders"}, ""); err == nil {
+		t.Fatalf("expected --record/--replay conflict")
+	}
+}
+
+func TestDebugHTTP_TracesWithoutSecrets(t *testing.T) {
+	gl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"orders":[]}`))
+	}))
+	defer gl.Close()
+	cfgPath := writeMultiProviderConfig(t, "", gl.URL)
+
+	_, stderr, err := runCLI(cfgPath, []string{"--debug-http", "glovo", "orders"}, "")
+	if err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	if strings.Contains(stderr, "glovo-token") {
+		t.Fatalf("token leaked: %s", stderr)
+	}
+	for _, want := range []string{"> GET " + gl.URL + "/", "> Authorization: ***\n", "> Glovo-App-Platform: web\n", "< 200 OK", `< {"orders":[]}`} {
+		if !strings.Contains(stderr, want) {
+			t.Fatalf("stderr missing %q:\n%s", want, stderr)
+		}
+	}
+}
+
+func TestProxy_AppliesToClientsAndBrowser(t *testing.T) {
+	var hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.URL.Host)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"orders":[]}`))
+	}))
+	defer proxy.Close()
+
+	cfgPath := writeMultiProviderConfig(t, "", "http://glovo.example.invalid")
+	if _, _, err := runCLI(cfgPath, []string{"--proxy", proxy.URL, "glovo", "orders"}, ""); err != nil {
+		t.Fatalf("orders via --proxy: %v", err)
+	}
+	cfg, _ := config.Load(cfgPath)
+	cfg.HTTP = &config.HTTPConfig{Proxy: proxy.URL, Timeout: "5s"}
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "orders"}, ""); err != nil {
+		t.Fatalf("orders via http.proxy: %v", err)
+	}
+	if strings.Join(hosts, ",") != "glovo.example.invalid,glovo.example.invalid" {
+		t.Fatalf("proxied hosts=%v", hosts)
+	}
+
+	st := &state{cfg: cfg}
+	if err := st.setupNetwork(netFlags{proxy: "socks5://u:p@127.0.0.1:1080", timeout: time.Second}); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if st.browserProxy() != "socks5://u:p@127.0.0.1:1080" {
+		t.Fatalf("browser proxy=%q", st.browserProxy())
+	}
+	if c := st.httpClient(provider.Glovo, 20*time.Second); c.Timeout != time.Second {
+		t.Fatalf("timeout=%s", c.Timeout)
+	}
+	if err := (&state{}).setupNetwork(netFlags{caCerts: []string{filepath.Join(t.TempDir(), "missing.pem")}}); err == nil {
+		t.Fatalf("expected missing CA error")
+	}
+	if err := (&state{}).setupNetwork(netFlags{iface: "no-such-iface0"}); err == nil {
+		t.Fatalf("expected unknown interface error")
+	}
+	if plain := (&state{}); plain.setupNetwork(netFlags{}) != nil || plain.base != nil {
+		t.Fatalf("expected default transport without settings")
+	}
+	if err := validateHTTPConfig(&config.HTTPConfig{Proxy: "ftp://x"}); err == nil {
+		t.Fatalf("expected proxy scheme error")
+	}
+	if err := validateHTTPConfig(&config.HTTPConfig{ConnectTimeout: "soon"}); err == nil || !strings.Contains(err.Error(), "http.connect_timeout") {
+		t.Fatalf("connect_timeout: %v", err)
+	}
+}
+
+func TestProxy_AppliesToNotifySinks(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.Method+" "+r.URL.String())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	cfgPath := writeMultiProviderConfig(t, "", "")
+	cfg, _ := config.Load(cfgPath)
+	cfg.HTTP = &config.HTTPConfig{Proxy: proxy.URL}
+	cfg.Notify = &config.NotifyConfig{Sinks: []config.NotifySink{{Type: "webhook", URL: "http://hooks.example.invalid/order"}}}
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"notify", "test"}, ""); err != nil {
+		t.Fatalf("notify test: %v", err)
+	}
+	if len(proxied) != 1 || proxied[0] != "POST http://hooks.example.invalid/order" {
+		t.Fatalf("proxied=%v", proxied)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/steipete/ordercli/internal/browserauth"
 	"github.com/steipete/ordercli/internal/config"
 	"github.com/steipete/ordercli/internal/foodora"
+	"github.com/steipete/ordercli/internal/provider"
 	"github.com/steipete/ordercli/internal/version"
 	"golang.org/x/term"
 )
@@ -138,8 +139,7 @@ func newLoginCmd(st *state) *cobra.Command {
 						}
 					}
 					st.markDirty()
-					fmt.Fprintln(cmd.OutOrStdout(), "ok")
-					return nil
+					return st.emitOK(cmd, "ok")
 				}
 
 				if mfa == nil {
@@ -218,6 +218,7 @@ func oauthPassword(ctx context.Context, st *state, cmd *cobra.Command, browser b
 			DeviceID:  cfg.DeviceID,
 			Timeout:   10 * time.Minute,
 			LogWriter: cmd.ErrOrStderr(),
+			Proxy:     st.browserProxy(),
 			ProfileDir: func() string {
 				if profileDir == "" {
 					return ""
@@ -230,11 +231,7 @@ func oauthPassword(ctx context.Context, st *state, cmd *cobra.Command, browser b
 		}
 
 		if sess.CookieHeader != "" {
-			if cfg.CookiesByHost == nil {
-				cfg.CookiesByHost = map[string]string{}
-			}
-			cfg.CookiesByHost[strings.ToLower(sess.Host)] = sess.CookieHeader
-			st.markDirty()
+			st.storeCookies(sess.Host, sess.CookieHeader)
 		}
 		if sess.UserAgent != "" {
 			cfg.HTTPUserAgent = sess.UserAgent
@@ -244,7 +241,10 @@ func oauthPassword(ctx context.Context, st *state, cmd *cobra.Command, browser b
 	}
 
 	_, cookie := st.cookieHeaderForBaseURL()
-	prof := st.appHeaders()
+	prof, err := st.headerProfile(provider.Foodora)
+	if err != nil {
+		return foodora.AuthToken{}, nil, err
+	}
 	ua := cfg.HTTPUserAgent
 	if ua == "" && prof.UserAgent != "" {
 		ua = prof.UserAgent
@@ -262,6 +262,8 @@ func oauthPassword(ctx context.Context, st *state, cmd *cobra.Command, browser b
 		CookieHeader:     cookie,
 		FPAPIKey:         prof.FPAPIKey,
 		AppName:          prof.AppName,
+		Headers:          prof.Headers,
+		HTTPClient:       st.httpClient(provider.Foodora, 20*time.Second),
 		OriginalUserAgent: func() string {
 			if strings.HasPrefix(ua, "Android-app-") {
 				return ua
@@ -314,7 +316,7 @@ func newLogoutCmd(st *state) *cobra.Command {
 	return &cobra.Command{
 		Use:   "logout",
 		Short: "Forget stored tokens",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := st.foodora()
 			cfg.PendingMfaToken = ""
 			cfg.PendingMfaChannel = ""
@@ -324,7 +326,7 @@ func newLogoutCmd(st *state) *cobra.Command {
 			cfg.RefreshToken = ""
 			cfg.ExpiresAt = time.Time{}
 			st.markDirty()
-			fmt.Fprintln(cmd.OutOrStdout(), "ok")
+			return st.emitOK(cmd, "ok")
 		},
 	}
 }
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/foodora"
+	"github.com/steipete/ordercli/internal/glovo"
+	"github.com/steipete/ordercli/internal/metrics"
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// deliveryBuckets span a quick pickup to a very late dinner (seconds).
+var deliveryBuckets = []float64{600, 900, 1200, 1800, 2400, 3000, 3600, 5400, 7200}
+
+// cliMetrics are the Prometheus series of `ordercli serve`. All methods are
+// no-ops on a nil receiver, so commands without metrics pay nothing.
+type cliMetrics struct {
+	reg *metrics.Registry
+
+	active         *metrics.GaugeVec
+	delivery       *metrics.HistogramVec
+	latency        *metrics.HistogramVec
+	responses      *metrics.CounterVec
+	pollErrors     *metrics.CounterVec
+	secretFailures *metrics.CounterVec
+
+	mu     sync.Mutex
+	clocks map[string]*deliveryClock
+}
+
+type deliveryClock struct {
+	start time.Time
+	done  bool
+}
+
+func newCLIMetrics() *cliMetrics {
+	reg := metrics.NewRegistry()
+	return &cliMetrics{
+		reg:            reg,
+		active:         reg.Gauge("ordercli_active_orders", "Orders on the provider's active list that are not delivered yet.", "provider"),
+		delivery:       reg.Histogram("ordercli_time_to_delivery_seconds", "Time from order placement (or first sighting) to delivery.", deliveryBuckets, "provider"),
+		latency:        reg.Histogram("ordercli_http_request_duration_seconds", "Latency of provider API requests.", metrics.DefBuckets, "provider"),
+		responses:      reg.Counter("ordercli_http_responses_total", "Provider API responses by HTTP status code (\"error\" when no response arrived).", "provider", "code"),
+		pollErrors:     reg.Counter("ordercli_poll_errors_total", "Failed active-order polls by HTTP status of the provider error (\"error\" when not an HTTP error).", "provider", "status"),
+		secretFailures: reg.Counter("ordercli_client_secret_fetch_failures_total", "Failed foodora client secret fetches from Firebase Remote Config."),
+		clocks:         map[string]*deliveryClock{},
+	}
+}
+
+// observe records one poll: the active gauge, poll errors and, for orders
+// that just turned delivered, their time to delivery.
+func (m *cliMetrics) observe(snap poller.Snapshot) {
+	if m == nil {
+		return
+	}
+	if snap.Err != nil {
+		m.pollErrors.Inc(snap.Provider, errorStatus(snap.Err))
+		return
+	}
+	active := 0
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seen := map[string]bool{}
+	for _, o := range snap.Orders {
+		if !o.Delivered {
+			active++
+		}
+		k := snap.Provider + "/" + o.ID
+		seen[k] = true
+		c := m.clocks[k]
+		if c == nil {
+			c = &deliveryClock{start: o.Time, done: o.Delivered}
+			if c.start.IsZero() {
+				c.start = snap.At
+			}
+			m.clocks[k] = c
+			continue
+		}
+		if o.Delivered && !c.done {
+			c.done = true
+			m.delivery.Observe(snap.At.Sub(c.start).Seconds(), snap.Provider)
+		}
+	}
+	for k := range m.clocks {
+		if !seen[k] && strings.HasPrefix(k, snap.Provider+"/") {
+			delete(m.clocks, k)
+		}
+	}
+	m.active.Set(float64(active), snap.Provider)
+}
+
+// errorStatus is the HTTP status code of a provider error, or "error".
+func errorStatus(err error) string {
+	var fe *foodora.HTTPError
+	if errors.As(err, &fe) {
+		return strconv.Itoa(fe.StatusCode)
+	}
+	var ge *glovo.HTTPError
+	if errors.As(err, &ge) {
+		return strconv.Itoa(ge.StatusCode)
+	}
+	return "error"
+}
+
+func (m *cliMetrics) secretFetchFailed() {
+	if m == nil {
+		return
+	}
+	m.secretFailures.Inc()
+}
+
+// transport wraps next (http.DefaultTransport when nil) with latency and
+// status-code accounting for one provider.
+func (m *cliMetrics) transport(provider string, next http.RoundTripper) http.RoundTripper {
+	if m == nil {
+		return next
+	}
+	return &instrumentedTransport{m: m, provider: provider, next: next}
+}
+
+type instrumentedTransport struct {
+	m        *cliMetrics
+	provider string
+	next     http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	start := time.Now()
+	res, err := next.RoundTrip(req)
+	t.m.latency.Observe(time.Since(start).Seconds(), t.provider)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(res.StatusCode)
+	}
+	t.m.responses.Inc(t.provider, code)
+	return res, err
+}
+
+// tokenExpiry reports the seconds until each stored access token expires
+// (negative once expired). Tokens without a known expiry are left out.
+func (s *state) tokenExpiry(now time.Time) []metrics.Sample {
+	var out []metrics.Sample
+	if fc := s.cfg.Providers.Foodora; fc != nil && fc.AccessToken != "" {
+		exp := fc.ExpiresAt
+		if exp.IsZero() {
+			exp, _ = fc.AccessTokenExpiresAt()
+		}
+		if !exp.IsZero() {
+			out = append(out, metrics.Sample{Labels: []string{provider.Foodora}, Value: exp.Sub(now).Seconds()})
+		}
+	}
+	if gc := s.cfg.Providers.Glovo; gc != nil {
+		if exp, ok := config.AccessTokenExpiresAt(gc.AccessToken); ok {
+			out = append(out, metrics.Sample{Labels: []string{provider.Glovo}, Value: exp.Sub(now).Seconds()})
+		}
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/foodora"
+	"github.com/steipete/ordercli/internal/glovo"
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func TestCLIMetrics_Observe(t *testing.T) {
+	m := newCLIMetrics()
+	t0 := time.Date(2026, 1, 2, 18, 0, 0, 0, time.UTC)
+	placed := t0.Add(-10 * time.Minute)
+
+	m.observe(poller.Snapshot{Provider: "foodora", At: t0, Orders: []provider.Order{
+		{ID: "A", Status: "Cooking", Time: placed},
+		{ID: "B", Status: "Cooking"},
+		{ID: "OLD", Delivered: true},
+	}})
+	if v := m.active.Value("foodora"); v != 2 {
+		t.Fatalf("active=%v", v)
+	}
+	m.observe(poller.Snapshot{Provider: "foodora", At: t0.Add(20 * time.Minute), Orders: []provider.Order{
+		{ID: "A", Delivered: true, Time: placed},
+		{ID: "B", Delivered: true},
+		{ID: "OLD", Delivered: true},
+	}})
+	m.observe(poller.Snapshot{Provider: "foodora", At: t0.Add(21 * time.Minute), Orders: []provider.Order{
+		{ID: "A", Delivered: true, Time: placed},
+	}})
+	if n := m.delivery.Count("foodora"); n != 2 {
+		t.Fatalf("deliveries=%d", n)
+	}
+	if len(m.clocks) != 1 || m.active.Value("foodora") != 0 {
+		t.Fatalf("clocks=%v", m.clocks)
+	}
+
+	m.observe(poller.Snapshot{Provider: "foodora", Err: fmt.Errorf("poll: %w", &foodora.HTTPError{StatusCode: 403})})
+	m.observe(poller.Snapshot{Provider: "glovo", Err: &glovo.HTTPError{StatusCode: 401}})
+	m.observe(poller.Snapshot{Provider: "glovo", Err: errors.New("boom")})
+	if m.pollErrors.Value("foodora", "403") != 1 || m.pollErrors.Value("glovo", "401") != 1 || m.pollErrors.Value("glovo", "error") != 1 {
+		t.Fatalf("poll errors")
+	}
+
+	var nilM *cliMetrics
+	nilM.observe(poller.Snapshot{})
+	nilM.secretFetchFailed()
+	if nilM.transport("x", nil) != nil {
+		t.Fatalf("nil metrics should not wrap")
+	}
+}
+
+func TestCLIMetrics_Transport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	st := &state{metrics: newCLIMetrics()}
+	st.cfg.HTTP = &config.HTTPConfig{Retry: map[string]config.RetryPolicy{"default": {MaxAttempts: 1}}}
+	hc := st.httpClient("foodora", time.Second)
+	res, err := hc.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	res.Body.Close()
+	if _, err := hc.Get("http://127.0.0.1:1"); err == nil {
+		t.Fatalf("expected dial error")
+	}
+	if st.metrics.responses.Value("foodora", "403") != 1 || st.metrics.responses.Value("foodora", "error") != 1 {
+		t.Fatalf("responses not counted")
+	}
+	if st.metrics.latency.Count("foodora") != 2 {
+		t.Fatalf("latency not observed")
+	}
+}
+
+func TestCLIMetrics_SecretFetchFailures(t *testing.T) {
+	setEnv(t, "FOODORA_CLIENT_SECRET", "")
+	st := &state{metrics: newCLIMetrics()}
+	st.metrics.secretFetchFailed()
+	if st.metrics.secretFailures.Value() != 1 {
+		t.Fatalf("secret failures")
+	}
+
+	orig := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(*http.Request) (*http.Response, error) { return nil, errors.New("offline") })
+	defer func() { http.DefaultTransport = orig }()
+	if _, err := st.forceFetchClientSecret(context.Background(), ""); err == nil {
+		t.Fatalf("expected fetch error")
+	}
+	if st.metrics.secretFailures.Value() != 2 {
+		t.Fatalf("fetch failure not counted: %v", st.metrics.secretFailures.Value())
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestTokenExpiry(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	jwt := func(exp int64) string {
+		return "x." + base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, `{"exp":%d}`, exp)) + ".y"
+	}
+	st := &state{}
+	if got := st.tokenExpiry(now); len(got) != 0 {
+		t.Fatalf("empty config: %+v", got)
+	}
+	st.cfg.Providers.Foodora = &config.FoodoraConfig{AccessToken: jwt(now.Unix() + 90)}
+	st.cfg.Providers.Glovo = &config.GlovoConfig{AccessToken: jwt(now.Unix() - 10)}
+	got := st.tokenExpiry(now)
+	if len(got) != 2 || got[0].Value != 90 || got[1].Labels[0] != "glovo" || got[1].Value != -10 {
+		t.Fatalf("got %+v", got)
+	}
+	st.cfg.Providers.Foodora.ExpiresAt = now.Add(time.Hour)
+	if got := st.tokenExpiry(now); got[0].Value != 3600 || !strings.EqualFold(got[0].Labels[0], "foodora") {
+		t.Fatalf("explicit expiry: %+v", got)
+	}
+}
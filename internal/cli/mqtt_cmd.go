@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/homeassistant"
+	"github.com/steipete/ordercli/internal/mqtt"
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func newMQTTCmd(st *state) *cobra.Command {
+	var broker string
+	var interval time.Duration
+	var only []string
+
+	cmd := &cobra.Command{
+		Use:   "mqtt",
+		Short: "Publish active orders to MQTT with Home Assistant discovery (config section mqtt)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var cfg config.MQTTConfig
+			if st.cfg.MQTT != nil {
+				cfg = *st.cfg.MQTT
+			}
+			if broker != "" {
+				cfg.Broker = broker
+			}
+			if strings.TrimSpace(cfg.Broker) == "" {
+				return errors.New("missing MQTT broker (set mqtt.broker in the config or pass --broker)")
+			}
+			names, err := st.selectProviders(only)
+			if err != nil {
+				return err
+			}
+
+			sessions := &sessionSet{st: st}
+			providers := map[string]provider.Provider{}
+			for _, name := range names {
+				// ETAs cost foodora a request per order; ask once per interval.
+				providers[name] = provider.WithETA(sessions.provider(name), interval)
+			}
+			link := &mqttLink{errOut: cmd.ErrOrStderr()}
+			bridge := homeassistant.New(link, homeassistant.Options{
+				TopicPrefix:     cfg.TopicPrefix,
+				DiscoveryPrefix: cfg.DiscoveryPrefix,
+				// Discovery follows a successful poll, so the session is open.
+				HasETA: func(name string) bool {
+					p, ok := providers[name]
+					return ok && p.Capabilities().Has(provider.CapETA)
+				},
+			})
+			link.bridge = bridge
+			link.opts = mqtt.Options{
+				Broker:   cfg.Broker,
+				ClientID: cfg.ClientID,
+				Username: cfg.Username,
+				Password: cfg.Password,
+				Will:     bridge.Will(),
+			}
+			if st.rootCAs != nil {
+				// ssl:// brokers trust http.ca_certs / --ca-cert like the API clients.
+				link.opts.TLSConfig = &tls.Config{RootCAs: st.rootCAs, MinVersion: tls.VersionTLS12}
+			}
+			if link.opts.ClientID == "" {
+				host, _ := os.Hostname()
+				link.opts.ClientID = "ordercli-" + host
+			}
+
+			ctx := cmd.Context()
+			// Fail fast on a wrong address or credentials; later drops reconnect.
+			if err := link.connect(ctx); err != nil {
+				return err
+			}
+			defer link.close()
+
+			p := &poller.Poller{Interval: interval}
+			for _, name := range names {
+				p.Providers = append(p.Providers, providers[name])
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "publishing to %s (providers: %v)\n", cfg.Broker, names)
+			p.Run(ctx, func(snap poller.Snapshot) {
+				if snap.Err != nil {
+					fmt.Fprintf(link.errOut, "warning: %s: %v\n", snap.Provider, snap.Err)
+				}
+				if err := link.ensure(ctx); err != nil {
+					fmt.Fprintf(link.errOut, "warning: mqtt: %v\n", err)
+					return
+				}
+				if err := bridge.Update(snap); err != nil {
+					fmt.Fprintf(link.errOut, "warning: mqtt: %v\n", err)
+				}
+			})
+			return sessions.save()
+		},
+	}
+
+	cmd.Flags().StringVar(&broker, "broker", "", "broker URL, e.g. tcp://homeassistant.local:1883 (overrides mqtt.broker)")
+	cmd.Flags().DurationVar(&interval, "interval", poller.DefaultInterval, "poll interval for providers without a poll hint")
+	cmd.Flags().StringSliceVar(&only, "provider", nil, "only these providers (repeatable; default: all configured)")
+	return cmd
+}
+
+// mqttLink is the bridge's publisher; it reconnects lazily when the broker
+// connection dropped since the last poll.
+type mqttLink struct {
+	opts   mqtt.Options
+	bridge *homeassistant.Bridge
+	errOut io.Writer
+	c      *mqtt.Client
+}
+
+func (l *mqttLink) Publish(m mqtt.Message) error {
+	if l.c == nil {
+		return errors.New("not connected")
+	}
+	return l.c.Publish(m)
+}
+
+func (l *mqttLink) connect(ctx context.Context) error {
+	dctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	c, err := mqtt.Dial(dctx, l.opts)
+	if err != nil {
+		return err
+	}
+	l.c = c
+	return l.bridge.Online()
+}
+
+func (l *mqttLink) ensure(ctx context.Context) error {
+	if l.c != nil && l.c.Err() == nil {
+		return nil
+	}
+	if l.c != nil {
+		fmt.Fprintf(l.errOut, "warning: mqtt: %v; reconnecting\n", l.c.Err())
+	}
+	return l.connect(ctx)
+}
+
+func (l *mqttLink) close() {
+	if l.c == nil || l.c.Err() != nil {
+		return
+	}
+	_ = l.bridge.Offline()
+	_ = l.c.Close()
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/homeassistant"
+	"github.com/steipete/ordercli/internal/mqtt"
+)
+
+func TestMQTT_PublishesActiveOrders(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+	setEnv(t, "FOODORA_CLIENT_SECRET", "secret")
+
+	broker, err := mqtt.NewBroker()
+	if err != nil {
+		t.Fatalf("broker: %v", err)
+	}
+	defer broker.Close()
+	broker.Username, broker.Password = "ha", "pw"
+
+	fd := newFoodoraTestServer(t)
+	defer fd.Close()
+	cfgPath := writeMultiProviderConfig(t, fd.URL, "")
+	cfg, _ := config.Load(cfgPath)
+	cfg.MQTT = &config.MQTTConfig{Broker: broker.URL(), ClientID: "office", Username: "ha", Password: "pw", TopicPrefix: "lunch"}
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	root := newRoot()
+	var out, errOut syncBuffer
+	root.SetOut(&out)
+	root.SetErr(&errOut)
+	root.SetContext(ctx)
+	root.SetArgs([]string{"--config", cfgPath, "mqtt"})
+	done := make(chan error, 1)
+	go func() { done <- root.Execute() }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	var state homeassistant.OrderState
+	for time.Now().Before(deadline) {
+		if m, ok := broker.Retained()["lunch/orders/foodora_oc-1/state"]; ok {
+			_ = json.Unmarshal(m.Payload, &state)
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if state.OrderID != "OC-1" || state.Vendor != "Vendor" || state.Status != "Cooking" || state.Provider != "foodora" {
+		t.Fatalf("state=%+v out=%q err=%q", state, out.String(), errOut.String())
+	}
+	ret := broker.Retained()
+	if _, ok := ret["homeassistant/sensor/ordercli_foodora_oc-1/eta_minutes/config"]; !ok {
+		t.Fatalf("discovery config missing")
+	}
+	if got := string(ret["lunch/status"].Payload); got != "online" {
+		t.Fatalf("availability=%q", got)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("mqtt: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("mqtt did not stop")
+	}
+	for deadline := time.Now().Add(3 * time.Second); string(broker.Retained()["lunch/status"].Payload) != "offline"; {
+		if time.Now().After(deadline) {
+			t.Fatalf("availability not offline after stop")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if c := broker.Clients(); len(c) != 1 || c[0] != "office" {
+		t.Fatalf("clients=%v", c)
+	}
+}
+
+func TestMQTT_Errors(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+	cfgPath := writeMultiProviderConfig(t, "", "http://127.0.0.1:1")
+	if _, _, err := runCLI(cfgPath, []string{"mqtt"}, ""); err == nil || !strings.Contains(err.Error(), "missing MQTT broker") {
+		t.Fatalf("err=%v", err)
+	}
+
+	broker, err := mqtt.NewBroker()
+	if err != nil {
+		t.Fatalf("broker: %v", err)
+	}
+	broker.Username = "someone"
+	if _, _, err := runCLI(cfgPath, []string{"mqtt", "--broker", broker.URL()}, ""); err == nil || !strings.Contains(err.Error(), "bad user name") {
+		t.Fatalf("err=%v", err)
+	}
+	broker.Username = ""
+
+	// A dropped connection is reported and redialled on the next poll.
+	var errOut bytes.Buffer
+	link := &mqttLink{opts: mqtt.Options{Broker: broker.URL()}, errOut: &errOut}
+	link.bridge = homeassistant.New(link, homeassistant.Options{})
+	if err := link.Publish(mqtt.Message{Topic: "x"}); err == nil {
+		t.Fatalf("publish before connect should fail")
+	}
+	if err := link.ensure(context.Background()); err != nil {
+		t.Fatalf("ensure: %v", err)
+	}
+	_ = broker.Close()
+	<-link.c.Done()
+	if err := link.ensure(context.Background()); err == nil || !strings.Contains(errOut.String(), "reconnecting") {
+		t.Fatalf("err=%v out=%q", err, errOut.String())
+	}
+	link.close() // no-op on a dead connection
+}
+
+func TestMQTT_TrustsConfiguredCAs(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+	cfgPath := writeMultiProviderConfig(t, "", "http://127.0.0.1:1")
+
+	// Any TLS server will do: the handshake is what the CA decides. It hangs
+	// up soon after, as no HTTP request ever arrives.
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.Config.ReadTimeout = 100 * time.Millisecond
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+	broker := "ssl://" + srv.Listener.Addr().String()
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"mqtt", "--broker", broker}, ""); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("want an untrusted certificate, got %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"--ca-cert", caPath, "mqtt", "--broker", broker}, ""); err == nil || strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("want the handshake to pass and MQTT to fail, got %v", err)
+	}
+}
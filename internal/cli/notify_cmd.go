@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/poller"
+)
+
+func newNotifyCmd(st *state) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Notification sinks for order status changes (config section notify)",
+	}
+	cmd.AddCommand(newNotifyTestCmd(st))
+	return cmd
+}
+
+func newNotifyTestCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "test",
+		Short: "Send a sample status change to every configured sink",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := st.notifier()
+			if err != nil {
+				return err
+			}
+			if n.Len() == 0 {
+				return errors.New("no notification sinks configured (add notify.sinks to the config)")
+			}
+			ev := poller.Event{
+				Provider: "ordercli",
+				OrderID:  "test",
+				From:     "Cooking",
+				To:       "On the way",
+				At:       time.Now(),
+			}
+			if err := n.Send(cmd.Context(), ev); err != nil {
+				return err
+			}
+			return st.emitOK(cmd, fmt.Sprintf("sent to %d sink(s)", n.Len()))
+		},
+	}
+}
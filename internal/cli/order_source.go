@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// orderSource is the shared "which orders" flag set of commands that work on
+// past orders: live history or the archive, narrowed by provider and date.
+type orderSource struct {
+	only     []string
+	limit    int
+	archived bool
+	since    string
+	until    string
+}
+
+func (s *orderSource) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&s.only, "provider", nil, "only these providers (repeatable; default: all configured)")
+	cmd.Flags().IntVar(&s.limit, "limit", 200, "max orders per provider when reading live history")
+	cmd.Flags().BoolVar(&s.archived, "archived", false, "read from the local archive (see ordercli sync) instead of the APIs")
+	cmd.Flags().StringVar(&s.since, "since", "", "only orders on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&s.until, "until", "", "only orders before this date (YYYY-MM-DD)")
+}
+
+// load returns the selected orders, newest first.
+func (s *orderSource) load(cmd *cobra.Command, st *state) ([]provider.Order, error) {
+	from, to, err := parseDateRange(s.since, s.until)
+	if err != nil {
+		return nil, err
+	}
+	var orders []provider.Order
+	if s.archived {
+		orders, err = archivedHistory(st, s.only, 0)
+	} else {
+		var names []string
+		if names, err = st.selectProviders(s.only); err == nil {
+			orders, err = fetchAllHistory(cmd, st, names, s.limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	orders, undated := filterOrders(orders, from, to)
+	for _, name := range provider.Names {
+		if n := undated[name]; n > 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: left out %d %s orders without a date (--since/--until)\n", n, name)
+		}
+	}
+	for i := range orders {
+		if orders[i].Currency == "" {
+			orders[i].Currency = defaultCurrency(st, orders[i].Provider)
+		}
+	}
+	return orders, nil
+}
+
+// parseDateRange parses optional YYYY-MM-DD bounds in local time.
+func parseDateRange(since, until string) (from, to time.Time, err error) {
+	parse := func(flag, v string) (time.Time, error) {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return time.Time{}, nil
+		}
+		t, err := time.ParseInLocation(time.DateOnly, v, time.Local)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("--%s: want YYYY-MM-DD, got %q", flag, v)
+		}
+		return t, nil
+	}
+	if from, err = parse("since", since); err != nil {
+		return
+	}
+	if to, err = parse("until", until); err != nil {
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		err = fmt.Errorf("--until must be after --since")
+	}
+	return
+}
+
+// filterOrders keeps orders in [from, to). With a bound set, undated orders
+// (all of Glovo's) are dropped and counted per provider in undated.
+func filterOrders(orders []provider.Order, from, to time.Time) (out []provider.Order, undated map[string]int) {
+	if from.IsZero() && to.IsZero() {
+		return orders, nil
+	}
+	undated = map[string]int{}
+	for _, o := range orders {
+		if o.Time.IsZero() {
+			undated[o.Provider]++
+			continue
+		}
+		if !from.IsZero() && o.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !o.Time.Before(to) {
+			continue
+		}
+		out = append(out, o)
+	}
+	return out, undated
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steipete/ordercli/internal/config"
 	"github.com/steipete/ordercli/internal/foodora"
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
 	"github.com/steipete/ordercli/internal/version"
 )
 
@@ -25,31 +29,26 @@ func newOrdersCmd(st *state) *cobra.Command {
 				return err
 			}
 
-			ctx := cmd.Context()
-			for {
-				resp, err := c.ActiveOrders(ctx)
-				if err != nil {
-					return err
-				}
-				printActiveOrders(cmd, resp.Data.ActiveOrders)
-
-				if !watch {
-					return nil
-				}
-				sleep := 30 * time.Second
-				if resp.Data.PollInSeconds != nil && *resp.Data.PollInSeconds > 0 {
-					sleep = time.Duration(*resp.Data.PollInSeconds) * time.Second
-				}
-				time.Sleep(sleep)
+			if watch {
+				p := provider.NewFoodora(c, foodoraCurrency(st))
+				return st.watchTransitions(cmd, &poller.Poller{Providers: []provider.Provider{p}}, false)
 			}
+			resp, err := c.ActiveOrders(cmd.Context())
+			if err != nil {
+				return err
+			}
+			orders := resp.Data.ActiveOrders
+			return st.emit(cmd, false, orders, func(w io.Writer) { printActiveOrders(w, orders) })
 		},
 	}
-	cmd.Flags().BoolVar(&watch, "watch", false, "poll active orders")
+	cmd.Flags().BoolVar(&watch, "watch", false, "poll and print status changes until every order is delivered")
 	return cmd
 }
 
 func newOrderCmd(st *state) *cobra.Command {
-	return &cobra.Command{
+	var asJSON bool
+
+	cmd := &cobra.Command{
 		Use:   "order <orderCode>",
 		Short: "Show details for a single order (tracking/orders/{orderCode})",
 		Args:  cobra.ExactArgs(1),
@@ -62,13 +61,113 @@ func newOrderCmd(st *state) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "status=%d\n", resp.Status)
-			if v, ok := resp.Data["status_messages"]; ok {
-				fmt.Fprintf(cmd.OutOrStdout(), "status_messages=%v\n", v)
+			// JSON output is the payload as sent; the struct only covers
+			// what the table shows.
+			var v any = resp.Data
+			if len(resp.Data.Raw) > 0 {
+				v = resp.Data.Raw
 			}
-			return nil
+			return st.emit(cmd, asJSON, v, func(w io.Writer) { printOrderTracking(w, resp.Data, time.Now()) })
 		},
 	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON")
+	return cmd
+}
+
+func printOrderTracking(out io.Writer, t foodora.OrderTracking, now time.Time) {
+	code := t.Code
+	if code == "" {
+		code = "-"
+	}
+	fmt.Fprintf(out, "order=%s\n", code)
+	if t.Vendor != nil && t.Vendor.Name != "" {
+		fmt.Fprintf(out, "vendor=%s\n", t.Vendor.Name)
+	}
+	if s := t.StatusText(); s != "" {
+		fmt.Fprintf(out, "status=%s\n", s)
+	}
+	fmt.Fprintf(out, "delivered=%t\n", t.IsDelivered)
+	if eta := formatETA(t.ETA, now); eta != "" {
+		fmt.Fprintf(out, "eta=%s\n", eta)
+	}
+
+	if len(t.Status.Titles) > 0 {
+		fmt.Fprintln(out, "progress:")
+		for _, step := range t.Status.Titles {
+			mark := "[ ]"
+			switch {
+			case step.Active:
+				mark = "[>]"
+			case step.Filled:
+				mark = "[x]"
+			}
+			fmt.Fprintf(out, "- %s %s\n", mark, step.Name)
+		}
+	}
+	if len(t.Timeline) > 0 {
+		fmt.Fprintln(out, "timeline:")
+		for _, e := range t.Timeline {
+			msg := e.Message
+			if msg == "" {
+				msg = string(e.Code)
+			}
+			when := "--:--"
+			if !e.Time.IsZero() {
+				when = e.Time.Local().Format("15:04")
+			}
+			fmt.Fprintf(out, "- %s %s\n", when, msg)
+		}
+	}
+
+	if r := t.Rider; r != nil {
+		fmt.Fprintf(out, "rider=%s\n", strings.Join(nonEmpty(r.Name, r.Vehicle, r.Phone), " "))
+		if loc := r.Location.String(); loc != "" {
+			fmt.Fprintf(out, "rider_location=%s\n", loc)
+		}
+	}
+	if v := t.Vendor; v != nil {
+		if line := strings.Join(nonEmpty(v.Address, v.Phone), " "); line != "" {
+			fmt.Fprintf(out, "vendor_address=%s\n", line)
+		}
+	}
+	if a := t.Address; a != nil {
+		if line := a.Line(); line != "" {
+			fmt.Fprintf(out, "delivery_address=%s\n", line)
+		}
+		if a.Instructions != "" {
+			fmt.Fprintf(out, "delivery_instructions=%s\n", a.Instructions)
+		}
+	}
+}
+
+// formatETA renders the window as local clock times plus minutes remaining,
+// e.g. "18:40-18:55 (in 12-27 min)".
+func formatETA(e *foodora.TrackingETA, now time.Time) string {
+	if e == nil || (e.From.IsZero() && e.To.IsZero()) {
+		return ""
+	}
+	from, to := e.Remaining(now)
+	var clock, mins []string
+	for _, b := range []struct {
+		at   foodora.FlexibleTime
+		left time.Duration
+	}{{e.From, from}, {e.To, to}} {
+		if !b.at.IsZero() {
+			clock = append(clock, b.at.Local().Format("15:04"))
+			mins = append(mins, strconv.Itoa(int(b.left.Round(time.Minute).Minutes())))
+		}
+	}
+	return strings.Join(clock, "-") + " (in " + strings.Join(mins, "-") + " min)"
+}
+
+func nonEmpty(parts ...string) []string {
+	out := parts[:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
 }
 
 func newAuthedClient(st *state) (*foodora.Client, error) {
@@ -81,7 +180,10 @@ func newAuthedClient(st *state) (*foodora.Client, error) {
 	}
 
 	_, cookie := st.cookieHeaderForBaseURL()
-	prof := st.appHeaders()
+	prof, err := st.headerProfile(provider.Foodora)
+	if err != nil {
+		return nil, err
+	}
 	ua := cfg.HTTPUserAgent
 	if ua == "" && prof.UserAgent != "" {
 		ua = prof.UserAgent
@@ -100,6 +202,9 @@ func newAuthedClient(st *state) (*foodora.Client, error) {
 		CookieHeader:     cookie,
 		FPAPIKey:         prof.FPAPIKey,
 		AppName:          prof.AppName,
+		Headers:          prof.Headers,
+		HTTPClient:       st.httpClient(provider.Foodora, 20*time.Second),
+		Schema:           st.schemaRecorder(),
 		OriginalUserAgent: func() string {
 			if strings.HasPrefix(ua, "Android-app-") {
 				return ua
@@ -149,8 +254,7 @@ func newAuthedClient(st *state) (*foodora.Client, error) {
 	return c, nil
 }
 
-func printActiveOrders(cmd *cobra.Command, orders []foodora.ActiveOrder) {
-	out := cmd.OutOrStdout()
+func printActiveOrders(out io.Writer, orders []foodora.ActiveOrder) {
 	if len(orders) == 0 {
 		fmt.Fprintln(out, "no active orders")
 		return
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func newAllOrdersCmd(st *state) *cobra.Command {
+	var watch bool
+	var interval time.Duration
+	var only []string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "orders",
+		Short: "Show active orders from every configured provider",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := newPoller(cmd, st, only, interval)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if !watch {
+				snaps := p.Once(cmd.Context())
+				failed := 0
+				for _, s := range snaps {
+					if s.Err != nil {
+						failed++
+						fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %v\n", s.Provider, s.Err)
+					}
+				}
+				if failed == len(snaps) {
+					return errors.New("orders: every provider failed")
+				}
+				views := make([]snapshotView, 0, len(snaps))
+				for _, s := range snaps {
+					views = append(views, snapshotJSON(s))
+				}
+				return st.emit(cmd, asJSON, views, func(out io.Writer) { printActiveSnapshots(out, snaps, false) })
+			}
+
+			feed, err := st.newStatusFeed(cmd)
+			if err != nil {
+				return err
+			}
+			view := make([]poller.Snapshot, len(p.Providers))
+			index := map[string]int{}
+			for i, pr := range p.Providers {
+				index[pr.Name()] = i
+				view[i] = poller.Snapshot{Provider: pr.Name()}
+			}
+			format := st.format(asJSON)
+			p.Run(cmd.Context(), func(s poller.Snapshot) {
+				view[index[s.Provider]] = s
+				if s.Err == nil {
+					feed.update(cmd.Context(), s.Provider, s.Orders, s.At)
+				}
+				switch format {
+				case outputJSON, outputNDJSON:
+					// One line per poll, so the stream stays parseable.
+					_ = json.NewEncoder(out).Encode(snapshotJSON(s))
+					return
+				case outputTemplate:
+					if err := st.execTemplate(out, snapshotJSON(s)); err != nil {
+						fmt.Fprintf(cmd.ErrOrStderr(), "warning: template: %v\n", err)
+					}
+					return
+				}
+				fmt.Fprint(out, "\033[2J\033[H")
+				fmt.Fprintf(out, "Active orders (%s, Ctrl+C to stop)\n\n", time.Now().Format(time.TimeOnly))
+				printActiveSnapshots(out, view, true)
+			})
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&watch, "watch", false, "keep polling and redraw a live view")
+	cmd.Flags().DurationVar(&interval, "interval", poller.DefaultInterval, "poll interval for providers without a poll hint")
+	cmd.Flags().StringSliceVar(&only, "provider", nil, "only these providers (repeatable; default: all configured)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print normalized JSON (NDJSON per poll with --watch)")
+	return cmd
+}
+
+// newPoller opens the selected providers. Providers that fail to open are
+// reported on stderr and skipped.
+func newPoller(cmd *cobra.Command, st *state, only []string, interval time.Duration) (*poller.Poller, error) {
+	names, err := st.selectProviders(only)
+	if err != nil {
+		return nil, err
+	}
+	p := &poller.Poller{Interval: interval}
+	for _, name := range names {
+		pr, err := openProvider(st, name)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %v\n", name, err)
+			continue
+		}
+		p.Providers = append(p.Providers, pr)
+	}
+	if len(p.Providers) == 0 {
+		return nil, errors.New("orders: no provider could be opened")
+	}
+	return p, nil
+}
+
+func printActiveSnapshots(out io.Writer, snaps []poller.Snapshot, showSchedule bool) {
+	found := false
+	for _, s := range snaps {
+		if s.Err != nil {
+			if showSchedule {
+				fmt.Fprintf(out, "%s\terror: %v\n", s.Provider, s.Err)
+			}
+			continue
+		}
+		for _, o := range s.Orders {
+			found = true
+			eta := ""
+			if !o.ETA.IsZero() {
+				eta = "eta " + o.ETA.In(time.Local).Format(time.TimeOnly)
+			}
+			fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\n", o.Provider, o.ID, o.Vendor, o.Status, eta)
+		}
+	}
+	if !found {
+		fmt.Fprintln(out, "no active orders")
+	}
+	if showSchedule {
+		fmt.Fprintln(out)
+		for _, s := range snaps {
+			if s.At.IsZero() {
+				fmt.Fprintf(out, "%s: polling…\n", s.Provider)
+				continue
+			}
+			fmt.Fprintf(out, "%s: updated %s, next in %s\n", s.Provider, s.At.Format(time.TimeOnly), s.Next)
+		}
+	}
+}
+
+type snapshotView struct {
+	Provider string           `json:"provider"`
+	At       time.Time        `json:"at"`
+	Error    string           `json:"error,omitempty"`
+	Orders   []provider.Order `json:"orders"`
+}
+
+func snapshotJSON(s poller.Snapshot) snapshotView {
+	v := snapshotView{Provider: s.Provider, At: s.At, Orders: make([]provider.Order, len(s.Orders))}
+	if s.Err != nil {
+		v.Error = s.Err.Error()
+	}
+	copy(v.Orders, s.Orders)
+	for i := range v.Orders {
+		v.Orders[i].Raw = nil
+	}
+	return v
+}
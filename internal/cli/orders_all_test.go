@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAllOrders_OnceAndWatch(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+
+	fd := newFoodoraTestServer(t)
+	defer fd.Close()
+	gl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"orders":[{"orderId":7,"layoutType":"ACTIVE_ORDER","content":{"title":"Tacos"}}]}`))
+	}))
+	defer gl.Close()
+	cfgPath := writeMultiProviderConfig(t, fd.URL, gl.URL)
+
+	out, errOut, err := runCLI(cfgPath, []string{"orders"}, "")
+	if err != nil {
+		t.Fatalf("orders: %v err=%s", err, errOut)
+	}
+	if !strings.Contains(out, "foodora\tOC-1\tVendor\tCooking") || !strings.Contains(out, "glovo\t7\tTacos\tACTIVE_ORDER") {
+		t.Fatalf("unexpected out=%q", out)
+	}
+
+	root := newRoot()
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"--config", cfgPath, "orders", "--watch", "--provider", "glovo"})
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if err := root.ExecuteContext(ctx); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Active orders") || !strings.Contains(buf.String(), "glovo: updated") {
+		t.Fatalf("unexpected watch out=%q", buf.String())
+	}
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats selectable with the root --output flag.
+const (
+	outputTable    = "table"
+	outputJSON     = "json"
+	outputNDJSON   = "ndjson"
+	outputTemplate = "template"
+)
+
+// output is the parsed --output/--template selection.
+type output struct {
+	format string
+	tmpl   *template.Template
+}
+
+// parseOutput validates --output and --template. A template without an
+// explicit format implies --output template.
+func parseOutput(format, tmpl string) (output, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = outputTable
+		if tmpl != "" {
+			format = outputTemplate
+		}
+	}
+	switch format {
+	case outputTable, outputJSON, outputNDJSON:
+		if tmpl != "" {
+			return output{}, fmt.Errorf("--template needs --output template (got --output %s)", format)
+		}
+		return output{format: format}, nil
+	case outputTemplate:
+		if tmpl == "" {
+			return output{}, fmt.Errorf("--output template needs --template")
+		}
+		t, err := template.New("output").Funcs(templateFuncs).Parse(tmpl)
+		if err != nil {
+			return output{}, fmt.Errorf("--template: %w", err)
+		}
+		return output{format: format, tmpl: t}, nil
+	default:
+		return output{}, fmt.Errorf("unknown output %q (use table, json, ndjson or template)", format)
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// format resolves the effective format for a command; legacyJSON is the
+// command's own --json flag, kept as shorthand for --output json.
+func (s *state) format(legacyJSON bool) string {
+	if legacyJSON && (s.out.format == "" || s.out.format == outputTable) {
+		return outputJSON
+	}
+	if s.out.format == "" {
+		return outputTable
+	}
+	return s.out.format
+}
+
+// structured reports whether the command should print machine output.
+func (s *state) structured(legacyJSON bool) bool {
+	return s.format(legacyJSON) != outputTable
+}
+
+// emit writes v in the selected format. table prints the human form.
+//
+// ndjson prints one JSON value per line, splitting top-level lists into one
+// line per element; templates likewise run once per list element, otherwise
+// once for the whole value.
+func (s *state) emit(cmd *cobra.Command, legacyJSON bool, v any, table func(io.Writer)) error {
+	out := cmd.OutOrStdout()
+	switch s.format(legacyJSON) {
+	case outputJSON:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputNDJSON:
+		enc := json.NewEncoder(out)
+		for _, item := range listItems(v) {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case outputTemplate:
+		for _, item := range listItems(v) {
+			if err := s.execTemplate(out, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		table(out)
+		return nil
+	}
+}
+
+// emitOK reports a completed action: msg in table mode (nothing when msg is
+// empty), {"ok":true,...} otherwise.
+func (s *state) emitOK(cmd *cobra.Command, msg string) error {
+	return s.emit(cmd, false, actionResult{OK: true, Message: msg}, func(w io.Writer) {
+		if msg != "" {
+			fmt.Fprintln(w, msg)
+		}
+	})
+}
+
+type actionResult struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+func (s *state) execTemplate(out io.Writer, v any) error {
+	// Templates see the JSON shape, so field names match --output json.
+	data, err := jsonShape(v)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := s.out.tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	_, err = out.Write(buf.Bytes())
+	return err
+}
+
+func jsonShape(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func listItems(v any) []any {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		return []any{v}
+	}
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		return []any{v} // []byte / json.RawMessage is one value
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
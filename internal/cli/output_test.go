@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseOutput(t *testing.T) {
+	o, err := parseOutput("", "")
+	if err != nil || o.format != outputTable {
+		t.Fatalf("o=%+v err=%v", o, err)
+	}
+	o, err = parseOutput("", "{{.id}}")
+	if err != nil || o.format != outputTemplate || o.tmpl == nil {
+		t.Fatalf("o=%+v err=%v", o, err)
+	}
+	o, err = parseOutput(" NDJSON ", "")
+	if err != nil || o.format != outputNDJSON {
+		t.Fatalf("o=%+v err=%v", o, err)
+	}
+	for _, tc := range [][2]string{
+		{"json", "{{.id}}"},
+		{"template", ""},
+		{"template", "{{.id"},
+		{"yaml", ""},
+	} {
+		if _, err := parseOutput(tc[0], tc[1]); err == nil {
+			t.Fatalf("expected error for %q/%q", tc[0], tc[1])
+		}
+	}
+}
+
+func TestListItems(t *testing.T) {
+	if got := listItems([]int{1, 2}); len(got) != 2 {
+		t.Fatalf("got=%v", got)
+	}
+	if got := listItems(json.RawMessage(`[1]`)); len(got) != 1 {
+		t.Fatalf("got=%v", got)
+	}
+	if got := listItems(map[string]int{"a": 1}); len(got) != 1 {
+		t.Fatalf("got=%v", got)
+	}
+}
+
+func TestOutputFlag_Commands(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+
+	fd := newFoodoraTestServer(t)
+	defer fd.Close()
+	cfgPath := writeMultiProviderConfig(t, fd.URL, "")
+
+	out, errOut, err := runCLI(cfgPath, []string{"--output", "json", "foodora", "orders"}, "")
+	if err != nil {
+		t.Fatalf("orders: %v err=%s", err, errOut)
+	}
+	var active []map[string]any
+	if err := json.Unmarshal([]byte(out), &active); err != nil || len(active) != 1 || active[0]["code"] != "OC-1" {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"-o", "ndjson", "history"}, "")
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"id":"HIST-1"`) {
+		t.Fatalf("out=%q", out)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"--template", "{{.provider}} {{.id}} {{upper .vendor}}", "history"}, "")
+	if err != nil || out != "foodora HIST-1 TEST VENDOR\n" {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+
+	// Legacy --json keeps working and --output wins over it.
+	out, _, err = runCLI(cfgPath, []string{"-o", "ndjson", "stats", "--json"}, "")
+	if err != nil || strings.Count(out, "\n") != 1 {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"-o", "json", "foodora", "config", "show"}, "")
+	if err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	if strings.Contains(out, `"access"`) || !strings.Contains(out, `"access_token_set": true`) {
+		t.Fatalf("config show leaked or missing fields: %q", out)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"-o", "ndjson", "export"}, "")
+	if err != nil || !strings.HasPrefix(out, `{"provider":"foodora","order_id":"HIST-1"`) {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+	out, _, err = runCLI(cfgPath, []string{"--template", "{{.order_id}},{{.total}}", "export"}, "")
+	if err != nil || out != "HIST-1,12.3\n" {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+
+	// Actions report {"ok":true}; this one also repoints the base URL, so it runs last.
+	out, _, err = runCLI(cfgPath, []string{"-o", "json", "foodora", "config", "set", "--country", "AT"}, "")
+	if err != nil || !strings.Contains(out, `"ok": true`) {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"-o", "xml", "history"}, ""); err == nil {
+		t.Fatalf("expected output error")
+	}
+}
@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/profiles"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func newProfilesCmd(st *state) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "App identity header profiles (config section header_profiles)",
+	}
+	cmd.AddCommand(newProfilesListCmd(st))
+	cmd.AddCommand(newProfilesShowCmd(st))
+	cmd.AddCommand(newProfilesSetCmd(st))
+	return cmd
+}
+
+// headerProviders are the providers with header profiles, in display order.
+var headerProviders = []string{provider.Foodora, provider.Glovo}
+
+type profileListItem struct {
+	profiles.Profile
+	// ActiveFor lists the providers currently using the profile.
+	ActiveFor []string `json:"active_for,omitempty"`
+}
+
+func newProfilesListCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List built-in and configured header profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			active := map[string][]string{}
+			for _, name := range headerProviders {
+				if p, err := st.headerProfile(name); err == nil {
+					active[p.Name] = append(active[p.Name], name)
+				}
+			}
+			var items []profileListItem
+			for _, p := range profiles.List(st.cfg.HeaderProfiles) {
+				items = append(items, profileListItem{Profile: p, ActiveFor: active[p.Name]})
+			}
+			return st.emit(cmd, false, items, func(out io.Writer) {
+				for _, it := range items {
+					mark := " "
+					if len(it.ActiveFor) > 0 {
+						mark = "*"
+					}
+					fmt.Fprintf(out, "%s %s\t%s\t%s\n", mark, it.Name, it.Provider, it.Source)
+				}
+			})
+		},
+	}
+}
+
+type activeProfile struct {
+	Provider string           `json:"provider"`
+	Country  string           `json:"country,omitempty"`
+	Account  string           `json:"account,omitempty"`
+	Profile  profiles.Profile `json:"profile"`
+}
+
+func newProfilesShowCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show [name]",
+		Short: "Show a header profile, or the ones in use with their country/account keys",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				p, ok := profiles.Get(st.cfg.HeaderProfiles, args[0])
+				if !ok {
+					return fmt.Errorf("unknown header profile %q (see `ordercli profiles list`)", args[0])
+				}
+				return st.emit(cmd, false, p, func(out io.Writer) { writeProfile(out, p) })
+			}
+
+			var active []activeProfile
+			for _, name := range headerProviders {
+				p, err := st.headerProfile(name)
+				if err != nil {
+					return err
+				}
+				a := activeProfile{Provider: name, Account: accountKey(st, name), Profile: p}
+				if name == provider.Foodora {
+					a.Country = st.foodora().TargetCountryISO
+				} else {
+					a.Country = st.glovo().CountryCode
+				}
+				active = append(active, a)
+			}
+			return st.emit(cmd, false, active, func(out io.Writer) {
+				for i, a := range active {
+					if i > 0 {
+						fmt.Fprintln(out)
+					}
+					fmt.Fprintf(out, "# %s (country=%s account=%s)\n", a.Provider, a.Country, a.Account)
+					writeProfile(out, a.Profile)
+				}
+			})
+		},
+	}
+}
+
+func writeProfile(out io.Writer, p profiles.Profile) {
+	fmt.Fprintf(out, "name=%s\n", p.Name)
+	fmt.Fprintf(out, "provider=%s\n", p.Provider)
+	fmt.Fprintf(out, "source=%s\n", p.Source)
+	for _, f := range []struct{ k, v string }{
+		{"user_agent", p.UserAgent},
+		{"app_name", p.AppName},
+		{"fp_api_key", p.FPAPIKey},
+		{"app_version", p.AppVersion},
+		{"client_info", p.ClientInfo},
+	} {
+		if f.v != "" {
+			fmt.Fprintf(out, "%s=%s\n", f.k, f.v)
+		}
+	}
+	keys := make([]string, 0, len(p.Headers))
+	for k := range p.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(out, "header.%s=%s\n", k, p.Headers[k])
+	}
+}
+
+func newProfilesSetCmd(st *state) *cobra.Command {
+	var fields config.HeaderProfile
+	var headers []string
+	var use, reset bool
+	var country, account string
+
+	cmd := &cobra.Command{
+		Use:   "set <name>",
+		Short: "Create or override a header profile, and optionally select it",
+		Long: `Create or override a header profile, and optionally select it.
+
+Fields set on a built-in profile override only those fields; --reset drops
+the overrides. --header Name=Value adds a raw header (empty value removes it).
+--use selects the profile for its provider, limited to --country and/or
+--account (see ` + "`ordercli profiles show`" + ` for the current keys); the newest
+selection wins.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := strings.TrimSpace(args[0])
+			if name == "" {
+				return errors.New("profile name missing")
+			}
+			if (country != "" || account != "") && !use {
+				return errors.New("--country and --account need --use")
+			}
+			if st.cfg.HeaderProfiles == nil {
+				st.cfg.HeaderProfiles = &config.HeaderProfilesConfig{}
+			}
+			hp := st.cfg.HeaderProfiles
+			if reset {
+				delete(hp.Profiles, name)
+			}
+
+			own := hp.Profiles[name]
+			for _, f := range []struct {
+				flag string
+				dst  *string
+				v    string
+			}{
+				{"provider", &own.Provider, fields.Provider},
+				{"user-agent", &own.UserAgent, fields.UserAgent},
+				{"app-name", &own.AppName, fields.AppName},
+				{"fp-api-key", &own.FPAPIKey, fields.FPAPIKey},
+				{"app-version", &own.AppVersion, fields.AppVersion},
+				{"client-info", &own.ClientInfo, fields.ClientInfo},
+			} {
+				if cmd.Flags().Changed(f.flag) {
+					*f.dst = strings.TrimSpace(f.v)
+				}
+			}
+			for _, h := range headers {
+				k, v, ok := strings.Cut(h, "=")
+				k = strings.TrimSpace(k)
+				if !ok || k == "" {
+					return fmt.Errorf("invalid --header %q (want Name=Value)", h)
+				}
+				if v = strings.TrimSpace(v); v == "" {
+					delete(own.Headers, k)
+					continue
+				}
+				if own.Headers == nil {
+					own.Headers = map[string]string{}
+				}
+				own.Headers[k] = v
+			}
+			if len(own.Headers) == 0 {
+				own.Headers = nil
+			}
+			if own.Provider+own.UserAgent+own.AppName+own.FPAPIKey+own.AppVersion+own.ClientInfo != "" || own.Headers != nil {
+				if hp.Profiles == nil {
+					hp.Profiles = map[string]config.HeaderProfile{}
+				}
+				hp.Profiles[name] = own
+			} else {
+				delete(hp.Profiles, name)
+			}
+
+			p, ok := profiles.Get(hp, name)
+			if !ok {
+				return fmt.Errorf("unknown header profile %q (pass --provider to create it)", name)
+			}
+			if p.Provider == "" {
+				return fmt.Errorf("header profile %q needs --provider (foodora or glovo)", name)
+			}
+			if use {
+				rule := config.HeaderProfileRule{Provider: p.Provider, Country: strings.ToUpper(strings.TrimSpace(country)), Account: strings.TrimSpace(account), Profile: name}
+				hp.Rules = slices.DeleteFunc(hp.Rules, func(r config.HeaderProfileRule) bool {
+					return r.Provider == rule.Provider && strings.EqualFold(r.Country, rule.Country) && r.Account == rule.Account
+				})
+				hp.Rules = append([]config.HeaderProfileRule{rule}, hp.Rules...)
+			}
+			if err := profiles.Validate(hp); err != nil {
+				return err
+			}
+			if len(hp.Profiles) == 0 && len(hp.Rules) == 0 {
+				st.cfg.HeaderProfiles = nil
+			}
+			st.markDirty()
+			return st.emitOK(cmd, "")
+		},
+	}
+	cmd.Flags().StringVar(&fields.Provider, "provider", "", "provider the profile is for (foodora or glovo)")
+	cmd.Flags().StringVar(&fields.UserAgent, "user-agent", "", "foodora User-Agent (e.g. Android-app-25.3.0(250300134))")
+	cmd.Flags().StringVar(&fields.AppName, "app-name", "", "foodora App-Name (e.g. at.mjam)")
+	cmd.Flags().StringVar(&fields.FPAPIKey, "fp-api-key", "", "foodora X-FP-API-KEY")
+	cmd.Flags().StringVar(&fields.AppVersion, "app-version", "", "glovo-app-version (e.g. v1.1782.0)")
+	cmd.Flags().StringVar(&fields.ClientInfo, "client-info", "", "glovo-client-info")
+	cmd.Flags().StringArrayVar(&headers, "header", nil, "extra header Name=Value sent on every request (repeatable)")
+	cmd.Flags().BoolVar(&reset, "reset", false, "drop the configured fields of this profile first")
+	cmd.Flags().BoolVar(&use, "use", false, "select this profile for its provider")
+	cmd.Flags().StringVar(&country, "country", "", "with --use: only for this country code")
+	cmd.Flags().StringVar(&account, "account", "", "with --use: only for this account key")
+	return cmd
+}
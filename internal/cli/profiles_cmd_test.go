@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/profiles"
+)
+
+func TestProfilesCLI_SetSelectsHeaders(t *testing.T) {
+	var got http.Header
+	gl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"orders":[]}`))
+	}))
+	defer gl.Close()
+	cfgPath := writeMultiProviderConfig(t, "", gl.URL)
+
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "orders"}, ""); err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	if got.Get("glovo-app-version") != "v1.1782.0" {
+		t.Fatalf("default app version=%q", got.Get("glovo-app-version"))
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"profiles", "set", "glovo-next", "--provider", "glovo", "--app-version", "v1.1900.0", "--client-info", "web-customer-web-react/v1.1900.0 project:customer-web", "--header", "X-Extra=1", "--use"}, ""); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "orders"}, ""); err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	if got.Get("glovo-app-version") != "v1.1900.0" || got.Get("X-Extra") != "1" || !strings.Contains(got.Get("glovo-client-info"), "v1.1900.0") {
+		t.Fatalf("headers=%v", got)
+	}
+
+	out, _, err := runCLI(cfgPath, []string{"profiles", "list"}, "")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, want := range []string{"* glovo-next\tglovo\tconfig\n", "  glovo-web\tglovo\tbuiltin\n", "* foodora-android\tfoodora\tbuiltin\n", "  mjam-android\tfoodora\tbuiltin\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("list missing %q:\n%s", want, out)
+		}
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"-o", "json", "profiles", "show", "glovo-next"}, "")
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	var p profiles.Profile
+	if err := json.Unmarshal([]byte(out), &p); err != nil || p.AppVersion != "v1.1900.0" || p.Headers["X-Extra"] != "1" {
+		t.Fatalf("show=%s err=%v", out, err)
+	}
+	out, _, err = runCLI(cfgPath, []string{"profiles", "show"}, "")
+	if err != nil || !strings.Contains(out, "# glovo (country= account=)\nname=glovo-next\n") {
+		t.Fatalf("show active=%s err=%v", out, err)
+	}
+
+	// Overriding a built-in keeps its other fields; --reset drops the override.
+	if _, _, err := runCLI(cfgPath, []string{"profiles", "set", "mjam-android", "--user-agent", "Android-app-26.0.0(260000000)", "--use", "--country", "at"}, ""); err != nil {
+		t.Fatalf("override: %v", err)
+	}
+	cfg, _ := config.Load(cfgPath)
+	hp := cfg.HeaderProfiles
+	if hp == nil || hp.Profiles["mjam-android"].UserAgent != "Android-app-26.0.0(260000000)" || hp.Profiles["mjam-android"].AppName != "" {
+		t.Fatalf("config=%+v", hp)
+	}
+	if len(hp.Rules) != 2 || hp.Rules[0] != (config.HeaderProfileRule{Provider: "foodora", Country: "AT", Profile: "mjam-android"}) {
+		t.Fatalf("rules=%+v", hp.Rules)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"profiles", "set", "mjam-android", "--reset"}, ""); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if cfg, _ = config.Load(cfgPath); len(cfg.HeaderProfiles.Profiles) != 1 {
+		t.Fatalf("profiles after reset=%+v", cfg.HeaderProfiles.Profiles)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"profiles", "set", "new-one", "--app-name", "x"}, ""); err == nil || !strings.Contains(err.Error(), "--provider") {
+		t.Fatalf("new without provider: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"profiles", "set", "glovo-next", "--country", "ES"}, ""); err == nil {
+		t.Fatalf("expected --country without --use error")
+	}
+	if _, _, err := runCLI(cfgPath, []string{"profiles", "show", "nope"}, ""); err == nil {
+		t.Fatalf("expected unknown profile error")
+	}
+}
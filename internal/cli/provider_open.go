@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// configuredProviders lists providers with stored credentials, in display order.
+// It reads the config directly so unconfigured providers are not materialized.
+func (s *state) configuredProviders() []string {
+	var out []string
+	if f := s.cfg.Providers.Foodora; f != nil && f.HasSession() {
+		out = append(out, provider.Foodora)
+	}
+	// Deliveroo has no stored session yet; the bearer token comes from the env.
+	if strings.TrimSpace(os.Getenv("DELIVEROO_BEARER_TOKEN")) != "" {
+		out = append(out, provider.Deliveroo)
+	}
+	if g := s.cfg.Providers.Glovo; g != nil && strings.TrimSpace(g.AccessToken) != "" {
+		out = append(out, provider.Glovo)
+	}
+	return out
+}
+
+// openProvider builds the provider adapter for name, refreshing sessions as needed.
+func openProvider(st *state, name string) (provider.Provider, error) {
+	switch name {
+	case provider.Foodora:
+		c, err := newAuthedClient(st)
+		if err != nil {
+			return nil, err
+		}
+		return provider.NewFoodora(c, foodoraCurrency(st)), nil
+	case provider.Deliveroo:
+		c, err := newDeliverooClient(st, deliverooClientFlags{})
+		if err != nil {
+			return nil, err
+		}
+		return provider.NewDeliveroo(c), nil
+	case provider.Glovo:
+		c, err := newGlovoClient(st)
+		if err != nil {
+			return nil, err
+		}
+		return provider.NewGlovo(c), nil
+	default:
+		return nil, unknownProviderError(name)
+	}
+}
+
+// selectProviders narrows the configured providers to the requested ones.
+func (s *state) selectProviders(only []string) ([]string, error) {
+	configured := s.configuredProviders()
+	if len(only) == 0 {
+		if len(configured) == 0 {
+			return nil, errNoProviders
+		}
+		return configured, nil
+	}
+	var out []string
+	for _, name := range only {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(provider.Names, name) {
+			return nil, unknownProviderError(name)
+		}
+		if !slices.Contains(configured, name) {
+			return nil, fmt.Errorf("provider %s is not configured", name)
+		}
+		if !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
+
+func unknownProviderError(name string) error {
+	return fmt.Errorf("unknown provider %q (known: %s)", name, strings.Join(provider.Names, ", "))
+}
+
+var errNoProviders = errors.New("no providers configured (run `ordercli foodora login`, `ordercli glovo session <token>`, or set DELIVEROO_BEARER_TOKEN)")
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/apierr"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/profiles"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// cfCookieTTL is how long Cloudflare's clearance cookies usually last: a
+// zone's default challenge passage and the fixed bot-management lifetime.
+var cfCookieTTL = map[string]time.Duration{
+	"cf_clearance": 30 * time.Minute,
+	"__cf_bm":      30 * time.Minute,
+}
+
+// storedCookie is a Cloudflare cookie kept in cookies_by_host.
+type storedCookie struct {
+	Host string `json:"host"`
+	Name string `json:"name"`
+	// SavedAt is zero for cookies stored before capture times were kept.
+	SavedAt time.Time `json:"saved_at,omitempty"`
+}
+
+func (c storedCookie) String() string {
+	if c.SavedAt.IsZero() {
+		return fmt.Sprintf("%s for %s (saved at an unknown time)", c.Name, c.Host)
+	}
+	age := time.Since(c.SavedAt).Round(time.Minute)
+	return fmt.Sprintf("%s for %s (saved %s ago)", c.Name, c.Host, strings.TrimSuffix(age.String(), "0s"))
+}
+
+// cloudflareCookies lists the Cloudflare cookies stored for host, or for
+// every host when host is empty.
+func cloudflareCookies(cfg *config.FoodoraConfig, host string) []storedCookie {
+	var out []storedCookie
+	for h, header := range cfg.CookiesByHost {
+		if host != "" && h != host {
+			continue
+		}
+		for _, part := range strings.Split(header, ";") {
+			name, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if _, ok := cfCookieTTL[name]; ok {
+				out = append(out, storedCookie{Host: h, Name: name, SavedAt: cfg.CookiesSavedAt[h]})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Host != out[j].Host {
+			return out[i].Host < out[j].Host
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// expiredCookies are the Cloudflare cookies older than their usual lifetime.
+func expiredCookies(cfg *config.FoodoraConfig, now time.Time) []storedCookie {
+	var out []storedCookie
+	for _, c := range cloudflareCookies(cfg, "") {
+		if !c.SavedAt.IsZero() && now.Sub(c.SavedAt) > cfCookieTTL[c.Name] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// remediation returns what to do about a classified provider error, one
+// line per step; nil for other errors.
+func (s *state) remediation(err error) []string {
+	var e *apierr.Error
+	if !errors.As(err, &e) {
+		return nil
+	}
+	var hints []string
+	switch e.Kind {
+	case apierr.Challenge:
+		if e.Provider == provider.Foodora {
+			hints = append(hints, "solve the challenge in a browser with `ordercli foodora login --browser`, or log in on the website in Chrome and import its cookies with `ordercli foodora cookies chrome`")
+		} else {
+			hints = append(hints, "open the site in a browser on this network to pass the check, then retry")
+		}
+	case apierr.WAFBlock:
+		hints = append(hints, "waiting or a browser will not help; retry from another network (--proxy or --interface)")
+		if profiles.Supported(e.Provider) {
+			hints = append(hints, "an outdated app identity can trigger blocks; compare `ordercli profiles show` with the current app")
+		}
+	case apierr.RateLimited:
+		wait := "a few minutes"
+		if e.RetryAfter > 0 {
+			wait = e.RetryAfter.String()
+		}
+		hints = append(hints, fmt.Sprintf("wait %s before retrying; a lower http.rate_limit for %s spaces out requests", wait, e.Host))
+	case apierr.AuthExpired:
+		switch e.Provider {
+		case provider.Foodora:
+			hints = append(hints, "log in again with `ordercli foodora login` (add --browser when Cloudflare interferes)")
+		case provider.Glovo:
+			hints = append(hints, "store a fresh token with `ordercli glovo session <access_token>`")
+		case provider.Deliveroo:
+			hints = append(hints, "set a fresh DELIVEROO_BEARER_TOKEN (and DELIVEROO_COOKIE)")
+		}
+	}
+
+	// The challenge came despite the stored clearance, so it is no longer
+	// accepted, whatever its age.
+	if fc := s.cfg.Providers.Foodora; fc != nil && e.Kind == apierr.Challenge && e.Provider == provider.Foodora {
+		for _, c := range cloudflareCookies(fc, strings.ToLower(e.Host)) {
+			hints = append(hints, fmt.Sprintf("stored cookie %s has likely expired; refresh it with `ordercli foodora cookies chrome` or `ordercli foodora login --browser`", c))
+		}
+	}
+	return hints
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/apierr"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func TestRemediation_CloudflareChallenge(t *testing.T) {
+	var cookies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookies = append(cookies, r.Header.Get("Cookie"))
+		w.Header().Set("Server", "cloudflare")
+		w.Header().Set("Cf-Ray", "8a1b2c3d4e5f-VIE")
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`<!DOCTYPE html><html><head><title>Just a moment...</title></head><body><script src="/cdn-cgi/challenge-platform/h/g/orchestrate/chl_page/v1"></script></body></html>`))
+	}))
+	defer srv.Close()
+
+	cfgPath := writeMultiProviderConfig(t, srv.URL, "")
+	cfg, _ := config.Load(cfgPath)
+	cfg.Providers.Foodora.CookiesByHost = map[string]string{"127.0.0.1": "cf_clearance=abc; __cf_bm=def; session=1"}
+	cfg.Providers.Foodora.CookiesSavedAt = map[string]time.Time{"127.0.0.1": time.Now().Add(-3 * time.Hour)}
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	root, st := newRootState()
+	var out, errOut bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&errOut)
+	root.SetArgs([]string{"--config", cfgPath, "foodora", "orders"})
+	err := root.Execute()
+
+	var e *apierr.Error
+	if !errors.As(err, &e) || e.Kind != apierr.Challenge || e.Vendor != apierr.Cloudflare || e.RayID != "8a1b2c3d4e5f-VIE" {
+		t.Fatalf("want Cloudflare challenge, got %v", err)
+	}
+	if len(cookies) == 0 || !strings.Contains(cookies[0], "cf_clearance=abc") {
+		t.Fatalf("stored cookies not sent: %q", cookies)
+	}
+	if strings.Contains(err.Error(), "<html") {
+		t.Fatalf("error should not quote the challenge page: %v", err)
+	}
+
+	hints := strings.Join(st.remediation(err), "\n")
+	for _, want := range []string{
+		"ordercli foodora login --browser",
+		"ordercli foodora cookies chrome",
+		"stored cookie cf_clearance for 127.0.0.1 (saved 3h0m ago) has likely expired",
+		"stored cookie __cf_bm for 127.0.0.1",
+	} {
+		if !strings.Contains(hints, want) {
+			t.Fatalf("hints missing %q:\n%s", want, hints)
+		}
+	}
+
+	show, _, err := runCLI(cfgPath, []string{"foodora", "config", "show"}, "")
+	if err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	if !strings.Contains(show, "cookie_expired=cf_clearance for 127.0.0.1 (saved 3h0m ago)") {
+		t.Fatalf("config show should flag the expired cookie:\n%s", show)
+	}
+}
+
+func TestRemediation_Kinds(t *testing.T) {
+	st := &state{cfg: config.New()}
+	cases := []struct {
+		err  *apierr.Error
+		want string
+	}{
+		{&apierr.Error{Kind: apierr.RateLimited, Provider: provider.Glovo, Host: "api.glovoapp.com", RetryAfter: 30 * time.Second}, "wait 30s before retrying; a lower http.rate_limit for api.glovoapp.com"},
+		{&apierr.Error{Kind: apierr.RateLimited, Provider: provider.Foodora, Host: "mj.fd-api.com"}, "wait a few minutes"},
+		{&apierr.Error{Kind: apierr.WAFBlock, Provider: provider.Foodora}, "ordercli profiles show"},
+		{&apierr.Error{Kind: apierr.Challenge, Provider: provider.Deliveroo}, "open the site in a browser"},
+		{&apierr.Error{Kind: apierr.AuthExpired, Provider: provider.Foodora}, "ordercli foodora login"},
+		{&apierr.Error{Kind: apierr.AuthExpired, Provider: provider.Glovo}, "ordercli glovo session <access_token>"},
+		{&apierr.Error{Kind: apierr.AuthExpired, Provider: provider.Deliveroo}, "DELIVEROO_BEARER_TOKEN"},
+	}
+	for _, tc := range cases {
+		got := strings.Join(st.remediation(tc.err), "\n")
+		if !strings.Contains(got, tc.want) {
+			t.Errorf("%s/%s: hints %q missing %q", tc.err.Provider, tc.err.Kind, got, tc.want)
+		}
+	}
+	if hints := st.remediation(errors.New("plain")); hints != nil {
+		t.Fatalf("unclassified errors get no hints: %v", hints)
+	}
+}
+
+func TestStoreCookies_RecordsCaptureTime(t *testing.T) {
+	st := &state{cfg: config.New()}
+	st.storeCookies("MJ.FD-API.COM", "cf_clearance=x")
+	cfg := st.foodora()
+	if cfg.CookiesByHost["mj.fd-api.com"] != "cf_clearance=x" || time.Since(cfg.CookiesSavedAt["mj.fd-api.com"]) > time.Minute {
+		t.Fatalf("unexpected cookies=%v saved=%v", cfg.CookiesByHost, cfg.CookiesSavedAt)
+	}
+	if len(expiredCookies(cfg, time.Now())) != 0 || len(expiredCookies(cfg, time.Now().Add(time.Hour))) != 1 {
+		t.Fatalf("expiry by age is off")
+	}
+	if !st.dirty {
+		t.Fatalf("state should be dirty")
+	}
+}
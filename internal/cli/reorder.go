@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -46,7 +45,10 @@ func newReorderCmd(st *state) *cobra.Command {
 					return errors.New("no order found")
 				}
 
-				printHistoryDetail(cmd.OutOrStdout(), resp.Data.Items[0])
+				item := resp.Data.Items[0]
+				if err := st.emit(cmd, false, item, func(w io.Writer) { printHistoryDetail(w, item) }); err != nil {
+					return err
+				}
 				fmt.Fprintln(cmd.ErrOrStderr(), "hint: run with --confirm to call orders/{orderCode}/reorder (adds items to cart)")
 				return nil
 			}
@@ -68,14 +70,9 @@ func newReorderCmd(st *state) *cobra.Command {
 				return err
 			}
 
-			if asJSON {
-				b, _ := json.MarshalIndent(resp.Data, "", "  ")
-				b = append(b, '\n')
-				_, _ = cmd.OutOrStdout().Write(b)
-				return nil
+			if err := st.emit(cmd, asJSON, resp.Data, func(w io.Writer) { printReorderDetail(w, resp.Data) }); err != nil {
+				return err
 			}
-
-			printReorderDetail(cmd.OutOrStdout(), resp.Data)
 			fmt.Fprintln(cmd.ErrOrStderr(), "note: this only builds a cart; it does not place an order")
 			return nil
 		},
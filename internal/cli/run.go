@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
@@ -9,29 +10,79 @@ import (
 )
 
 func Run(ctx context.Context, args []string) error {
-	root := newRoot()
+	root, st := newRootState()
 	root.SetArgs(args)
 	root.SetContext(ctx)
 	if err := root.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		// Interrupts are a normal way to stop a watch; don't print them.
+		if !errors.Is(err, context.Canceled) {
+			fmt.Fprintln(os.Stderr, err)
+			for _, hint := range st.remediation(err) {
+				fmt.Fprintln(os.Stderr, "hint: "+hint)
+			}
+		}
 		return err
 	}
 	return nil
 }
 
 func newRoot() *cobra.Command {
+	cmd, _ := newRootState()
+	return cmd
+}
+
+// newRootState also returns the state the commands share, for reporting
+// after Execute.
+func newRootState() (*cobra.Command, *state) {
 	var cfgPath string
+	var archivePath string
+	var outputFormat, outputTmpl string
+	var verbose, debugHTTP bool
+	var recordDir, replayDir string
+	var netOpts netFlags
 
 	cmd := &cobra.Command{
 		Use:   "ordercli",
 		Short: "multi-provider order CLI",
 	}
 	cmd.PersistentFlags().StringVar(&cfgPath, "config", "", "config path (default: OS config dir)")
+	cmd.PersistentFlags().StringVar(&archivePath, "archive", "", "order archive path (default: OS data dir)")
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", "output format: table, json, ndjson or template (default table)")
+	cmd.PersistentFlags().StringVar(&outputTmpl, "template", "", "Go text/template applied to each result (implies --output template)")
+	cmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "report HTTP retries and rate-limit delays on stderr")
+	cmd.PersistentFlags().StringVar(&netOpts.proxy, "proxy", "", "HTTP(S) or SOCKS5 proxy URL for all clients (overrides http.proxy)")
+	cmd.PersistentFlags().StringArrayVar(&netOpts.caCerts, "ca-cert", nil, "PEM `file` of an extra trusted CA (repeatable)")
+	cmd.PersistentFlags().DurationVar(&netOpts.timeout, "http-timeout", 0, "per-request timeout for all clients (overrides http.timeout; default 20s)")
+	cmd.PersistentFlags().StringVar(&netOpts.iface, "interface", "", "network interface or local IP for outgoing connections")
+	cmd.PersistentFlags().BoolVar(&debugHTTP, "debug-http", false, "trace every HTTP request and response on stderr (credentials masked)")
+	cmd.PersistentFlags().StringVar(&recordDir, "record", "", "record scrubbed HTTP interactions into `dir`")
+	cmd.PersistentFlags().StringVar(&replayDir, "replay", "", "answer HTTP requests from the recordings in `dir` instead of the network")
 
 	st := &state{}
 	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		st.configPath = cfgPath
-		return st.load()
+		st.archivePath = archivePath
+		if verbose {
+			st.logOut = cmd.ErrOrStderr()
+		}
+		if debugHTTP {
+			st.debugOut = cmd.ErrOrStderr()
+		}
+		out, err := parseOutput(outputFormat, outputTmpl)
+		if err != nil {
+			return err
+		}
+		st.out = out
+		if err := st.load(); err != nil {
+			return err
+		}
+		if err := st.unlock(cmd); err != nil {
+			return err
+		}
+		if err := st.setupNetwork(netOpts); err != nil {
+			return err
+		}
+		return st.openCassette(recordDir, replayDir)
 	}
 	cmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
 		return st.save()
@@ -40,6 +91,17 @@ func newRoot() *cobra.Command {
 	cmd.AddCommand(newFoodoraCmd(st))
 	cmd.AddCommand(newDeliverooCmd(st))
 	cmd.AddCommand(newGlovoCmd(st))
+	cmd.AddCommand(newAllHistoryCmd(st))
+	cmd.AddCommand(newAllOrdersCmd(st))
+	cmd.AddCommand(newSyncCmd(st))
+	cmd.AddCommand(newStatsCmd(st))
+	cmd.AddCommand(newExportCmd(st))
+	cmd.AddCommand(newNotifyCmd(st))
+	cmd.AddCommand(newServeCmd(st))
+	cmd.AddCommand(newMQTTCmd(st))
+	cmd.AddCommand(newProfilesCmd(st))
+	cmd.AddCommand(newDoctorCmd(st))
+	cmd.AddCommand(newVaultCmd(st))
 
-	return cmd
+	return cmd, st
 }
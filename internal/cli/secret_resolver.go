@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/steipete/ordercli/internal/firebase"
 )
@@ -40,8 +42,9 @@ func (s *state) resolveClientSecret(ctx context.Context, clientID string) (resol
 		return resolvedSecret{Secret: v, FromEnv: true}, nil
 	}
 
-	secret, err := fetchClientSecretFromRemoteConfig(ctx, s.firebaseConfig(), s.remoteConfigKeyCandidates(), clientID)
+	secret, err := fetchClientSecretFromRemoteConfig(ctx, s.httpClient("firebase", 20*time.Second), s.firebaseConfig(), s.remoteConfigKeyCandidates(), clientID)
 	if err != nil {
+		s.metrics.secretFetchFailed()
 		return resolvedSecret{}, err
 	}
 	if secret == "" {
@@ -64,8 +67,9 @@ func (s *state) forceFetchClientSecret(ctx context.Context, clientID string) (re
 		clientID = "android"
 	}
 
-	secret, err := fetchClientSecretFromRemoteConfig(ctx, s.firebaseConfig(), s.remoteConfigKeyCandidates(), clientID)
+	secret, err := fetchClientSecretFromRemoteConfig(ctx, s.httpClient("firebase", 20*time.Second), s.firebaseConfig(), s.remoteConfigKeyCandidates(), clientID)
 	if err != nil {
+		s.metrics.secretFetchFailed()
 		return resolvedSecret{}, err
 	}
 	if secret == "" {
@@ -124,8 +128,9 @@ func (s *state) remoteConfigKeyCandidates() []string {
 	return out
 }
 
-func fetchClientSecretFromRemoteConfig(ctx context.Context, cfg firebase.APKFirebaseConfig, keys []string, clientID string) (string, error) {
+func fetchClientSecretFromRemoteConfig(ctx context.Context, hc *http.Client, cfg firebase.APKFirebaseConfig, keys []string, clientID string) (string, error) {
 	rc := firebase.NewRemoteConfigClient(cfg)
+	rc.SetHTTPClient(hc)
 	resp, err := rc.Fetch(ctx)
 	if err != nil {
 		return "", err
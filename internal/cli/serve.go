@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/metrics"
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
+	"github.com/steipete/ordercli/internal/server"
+)
+
+func newServeCmd(st *state) *cobra.Command {
+	var addr string
+	var interval time.Duration
+	var only []string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run a daemon that polls active orders and serves them over HTTP (JSON, SSE, Prometheus metrics)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := st.selectProviders(only)
+			if err != nil {
+				return err
+			}
+			n, err := st.notifier()
+			if err != nil {
+				return err
+			}
+
+			errOut := cmd.ErrOrStderr()
+			m := newCLIMetrics()
+			st.metrics = m
+			sessions := &sessionSet{st: st}
+			m.reg.GaugeFunc("ordercli_token_expiry_seconds", "Seconds until the stored access token expires (negative when expired).",
+				[]string{"provider"}, func() []metrics.Sample {
+					sessions.mu.Lock()
+					defer sessions.mu.Unlock()
+					return st.tokenExpiry(time.Now())
+				})
+			p := &poller.Poller{Interval: interval}
+			for _, name := range names {
+				p.Providers = append(p.Providers, sessions.provider(name))
+			}
+			srv := server.New(p, server.Options{
+				OnEvent: func(ctx context.Context, ev poller.Event) {
+					if err := n.Send(ctx, ev); err != nil {
+						fmt.Fprintf(errOut, "warning: notify: %v\n", err)
+					}
+				},
+				OnSnapshot: func(s poller.Snapshot) {
+					m.observe(s)
+					if s.Err != nil {
+						fmt.Fprintf(errOut, "warning: %s: %v\n", s.Provider, s.Err)
+					}
+				},
+			})
+
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			if !isLoopback(ln.Addr()) {
+				fmt.Fprintf(errOut, "warning: serving on %s; the API has no authentication\n", ln.Addr())
+			}
+
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+			mux := srv.Handler()
+			mux.Handle("GET /metrics", m.reg)
+			hs := &http.Server{
+				Handler:           mux,
+				ReadHeaderTimeout: 10 * time.Second,
+				// SSE handlers end when the daemon stops.
+				BaseContext: func(net.Listener) context.Context { return ctx },
+			}
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				srv.Run(ctx)
+			}()
+			serveErr := make(chan error, 1)
+			go func() { serveErr <- hs.Serve(ln) }()
+			fmt.Fprintf(cmd.OutOrStdout(), "listening on http://%s (providers: %v)\n", ln.Addr(), names)
+
+			select {
+			case <-ctx.Done():
+			case err = <-serveErr:
+			}
+			cancel()
+			shutdownCtx, stop := context.WithTimeout(context.Background(), 5*time.Second)
+			defer stop()
+			_ = hs.Shutdown(shutdownCtx)
+			wg.Wait()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return sessions.save()
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", server.DefaultAddr, "listen address (loopback by default; the API has no authentication)")
+	cmd.Flags().DurationVar(&interval, "interval", poller.DefaultInterval, "poll interval for providers without a poll hint")
+	cmd.Flags().StringSliceVar(&only, "provider", nil, "only these providers (repeatable; default: all configured)")
+	return cmd
+}
+
+func isLoopback(a net.Addr) bool {
+	tcp, ok := a.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// sessionSet opens providers lazily and reopens them when their stored
+// session is about to expire, so a long-running daemon keeps polling.
+// Refreshed tokens are written back to the config right away.
+type sessionSet struct {
+	mu sync.Mutex // guards st (config) across the provider goroutines
+	st *state
+}
+
+func (s *sessionSet) provider(name string) *sessionProvider {
+	return &sessionProvider{set: s, name: name}
+}
+
+func (s *sessionSet) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.st.save()
+}
+
+// sessionProvider is a provider.Provider that (re)opens its session on use.
+type sessionProvider struct {
+	set  *sessionSet
+	name string
+	p    provider.Provider
+}
+
+func (p *sessionProvider) current() (provider.Provider, error) {
+	s := p.set
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.p != nil && !s.st.sessionExpiring(p.name, time.Now()) {
+		return p.p, nil
+	}
+	pr, err := openProvider(s.st, p.name)
+	if err != nil {
+		return nil, err
+	}
+	p.p = pr
+	// openProvider refreshes expired tokens; keep them across restarts.
+	if err := s.st.save(); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// sessionExpiring reports whether the stored session of name needs a refresh.
+// Only foodora sessions expire under ordercli's control.
+func (s *state) sessionExpiring(name string, now time.Time) bool {
+	if name != provider.Foodora {
+		return false
+	}
+	return s.foodora().TokenLikelyExpired(now)
+}
+
+func (p *sessionProvider) Name() string { return p.name }
+
+func (p *sessionProvider) Capabilities() provider.Capability {
+	pr, err := p.current()
+	if err != nil {
+		return 0
+	}
+	return pr.Capabilities()
+}
+
+func (p *sessionProvider) History(ctx context.Context, req provider.HistoryRequest) (provider.HistoryPage, error) {
+	pr, err := p.current()
+	if err != nil {
+		return provider.HistoryPage{}, err
+	}
+	return pr.History(ctx, req)
+}
+
+func (p *sessionProvider) ActiveOrders(ctx context.Context) (provider.ActiveOrders, error) {
+	pr, err := p.current()
+	if err != nil {
+		return provider.ActiveOrders{}, err
+	}
+	return pr.ActiveOrders(ctx)
+}
+
+func (p *sessionProvider) OrderDetail(ctx context.Context, id string) (provider.Order, error) {
+	pr, err := p.current()
+	if err != nil {
+		return provider.Order{}, err
+	}
+	return pr.OrderDetail(ctx, id)
+}
+
+func (p *sessionProvider) ETA(ctx context.Context, id string) (time.Time, error) {
+	pr, err := p.current()
+	if err != nil {
+		return time.Time{}, err
+	}
+	l, ok := pr.(provider.ETALookup)
+	if !ok {
+		return time.Time{}, provider.ErrUnsupported
+	}
+	return l.ETA(ctx, id)
+}
+
+func (p *sessionProvider) Me(ctx context.Context) (provider.Account, error) {
+	pr, err := p.current()
+	if err != nil {
+		return provider.Account{}, err
+	}
+	return pr.Me(ctx)
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+	"github.com/steipete/ordercli/internal/server"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServe_RefreshesSessionAndServesOrders(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+	setEnv(t, "FOODORA_CLIENT_SECRET", "secret")
+
+	fd := newFoodoraTestServer(t)
+	defer fd.Close()
+	cfgPath := writeMultiProviderConfig(t, fd.URL, "")
+	cfg, _ := config.Load(cfgPath)
+	cfg.Providers.Foodora.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	root := newRoot()
+	var out, errOut syncBuffer
+	root.SetOut(&out)
+	root.SetErr(&errOut)
+	root.SetContext(ctx)
+	root.SetArgs([]string{"--config", cfgPath, "serve", "--addr", "127.0.0.1:0"})
+	done := make(chan error, 1)
+	go func() { done <- root.Execute() }()
+
+	addrRE := regexp.MustCompile(`listening on (http://\S+)`)
+	var base string
+	var st server.Status
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if m := addrRE.FindStringSubmatch(out.String()); m != nil {
+			base = m[1]
+			if res, err := http.Get(base + "/api/orders"); err == nil {
+				_ = json.NewDecoder(res.Body).Decode(&st)
+				res.Body.Close()
+				if st.Active == 1 {
+					break
+				}
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if st.Active != 1 || st.Providers[0].Orders[0].ID != "OC-1" {
+		t.Fatalf("status=%+v out=%q err=%q", st, out.String(), errOut.String())
+	}
+
+	// The refreshed token is persisted while the daemon runs.
+	cfg, _ = config.Load(cfgPath)
+	if cfg.Providers.Foodora.AccessToken != "access2" {
+		t.Fatalf("token not refreshed: %q", cfg.Providers.Foodora.AccessToken)
+	}
+
+	res, err := http.Get(base + "/metrics")
+	if err != nil {
+		t.Fatalf("metrics: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	for _, want := range []string{
+		`ordercli_active_orders{provider="foodora"} 1`,
+		`ordercli_http_responses_total{provider="foodora",code="200"}`,
+		`ordercli_http_request_duration_seconds_count{provider="foodora"}`,
+		`# TYPE ordercli_token_expiry_seconds gauge`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("metrics missing %q:\n%s", want, body)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("serve did not stop")
+	}
+}
+
+func TestServe_Errors(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+	cfgPath := writeMultiProviderConfig(t, "", "")
+	if _, _, err := runCLI(cfgPath, []string{"serve"}, ""); err == nil {
+		t.Fatalf("expected no-providers error")
+	}
+	cfgPath = writeMultiProviderConfig(t, "", "http://127.0.0.1:1")
+	if _, _, err := runCLI(cfgPath, []string{"serve", "--addr", "not-an-addr"}, ""); err == nil {
+		t.Fatalf("expected listen error")
+	}
+	if isLoopback(&net.TCPAddr{IP: net.IPv4(192, 168, 1, 2)}) || !isLoopback(&net.TCPAddr{IP: net.IPv6loopback}) {
+		t.Fatalf("isLoopback")
+	}
+}
+
+func TestSessionProvider_Delegates(t *testing.T) {
+	cfgPath := writeMultiProviderConfig(t, "", "http://127.0.0.1:1")
+	st := &state{configPath: cfgPath}
+	if err := st.load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	set := &sessionSet{st: st}
+
+	gl := set.provider("glovo")
+	if gl.Name() != "glovo" || gl.Capabilities() == 0 {
+		t.Fatalf("glovo caps=%v", gl.Capabilities())
+	}
+	ctx := context.Background()
+	if _, err := gl.History(ctx, provider.HistoryRequest{}); err == nil {
+		t.Fatalf("expected network error")
+	}
+	if _, err := gl.Me(ctx); err == nil {
+		t.Fatalf("expected network error")
+	}
+
+	bad := set.provider("nope")
+	if bad.Capabilities() != 0 {
+		t.Fatalf("unknown provider caps")
+	}
+	if _, err := bad.ActiveOrders(ctx); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, err := bad.History(ctx, provider.HistoryRequest{}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, err := bad.OrderDetail(ctx, "1"); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, err := bad.Me(ctx); err == nil {
+		t.Fatalf("expected error")
+	}
+	if st.sessionExpiring("glovo", time.Now()) {
+		t.Fatalf("glovo sessions are not refreshed")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
@@ -12,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steipete/ordercli/internal/chromecookies"
 	"github.com/steipete/ordercli/internal/foodora"
+	"github.com/steipete/ordercli/internal/provider"
 	"github.com/steipete/ordercli/internal/version"
 )
 
@@ -98,11 +98,9 @@ func newSessionChromeCmd(st *state) *cobra.Command {
 			st.markDirty()
 
 			if access == "" {
-				fmt.Fprintln(cmd.OutOrStdout(), "ok (refresh_token imported; run `ordercli foodora session refresh`)")
-				return nil
+				return st.emitOK(cmd, "ok (refresh_token imported; run `ordercli foodora session refresh`)")
 			}
-			fmt.Fprintln(cmd.OutOrStdout(), "ok")
-			return nil
+			return st.emitOK(cmd, "ok")
 		},
 	}
 
@@ -148,7 +146,10 @@ func newSessionRefreshCmd(st *state) *cobra.Command {
 			}
 
 			_, cookie := st.cookieHeaderForBaseURL()
-			prof := st.appHeaders()
+			prof, err := st.headerProfile(provider.Foodora)
+			if err != nil {
+				return err
+			}
 			ua := cfg.HTTPUserAgent
 			if ua == "" && prof.UserAgent != "" {
 				ua = prof.UserAgent
@@ -166,6 +167,8 @@ func newSessionRefreshCmd(st *state) *cobra.Command {
 				CookieHeader:     cookie,
 				FPAPIKey:         prof.FPAPIKey,
 				AppName:          prof.AppName,
+				Headers:          prof.Headers,
+				HTTPClient:       st.httpClient(provider.Foodora, 20*time.Second),
 				OriginalUserAgent: func() string {
 					if strings.HasPrefix(ua, "Android-app-") {
 						return ua
@@ -208,8 +211,7 @@ func newSessionRefreshCmd(st *state) *cobra.Command {
 			}
 			cfg.OAuthClientID = clientID
 			st.markDirty()
-			fmt.Fprintln(cmd.OutOrStdout(), "ok")
-			return nil
+			return st.emitOK(cmd, "ok")
 		},
 	}
 
@@ -1,16 +1,63 @@
 package cli
 
 import (
+	"crypto/x509"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"sync"
+	"time"
 
+	"github.com/steipete/ordercli/internal/archive"
+	"github.com/steipete/ordercli/internal/cassette"
 	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/profiles"
+	"github.com/steipete/ordercli/internal/schema"
+	"github.com/steipete/ordercli/internal/transport"
 )
 
 type state struct {
-	configPath string
-	cfg        config.Config
-	dirty      bool
+	configPath  string
+	archivePath string
+	out         output
+	cfg         config.Config
+	dirty       bool
+	// metrics is set by `serve`; nil elsewhere.
+	metrics *cliMetrics
+	// logOut receives --verbose diagnostics; nil when not verbose.
+	logOut io.Writer
+	// debugOut receives --debug-http request traces; nil otherwise.
+	debugOut io.Writer
+	// limiter is shared by every client of the process; see httpClient.
+	limiterOnce sync.Once
+	limiter     *transport.Limiter
+	// cassette is the --record/--replay transport under every client; nil
+	// otherwise. replaying also keeps the config (and its real tokens)
+	// from being overwritten with the scrubbed ones served by the cassette.
+	cassette  http.RoundTripper
+	replaying bool
+	// base is the proxy/CA/interface transport under every client (nil:
+	// http.DefaultTransport); timeout overrides the per-client default;
+	// proxy is the configured proxy URL; rootCAs the trusted roots when
+	// extra CA certificates are configured (nil: system roots). See
+	// setupNetwork.
+	base    http.RoundTripper
+	timeout time.Duration
+	proxy   string
+	rootCAs *x509.CertPool
+	// schema records response drift for `doctor schema`; see
+	// schemaRecorder.
+	schemaOnce sync.Once
+	schema     *schema.Recorder
+}
+
+// logf writes a --verbose line to stderr.
+func (s *state) logf(format string, args ...any) {
+	if s.logOut != nil {
+		fmt.Fprintf(s.logOut, format+"\n", args...)
+	}
 }
 
 func (s *state) foodora() *config.FoodoraConfig { return s.cfg.Foodora() }
@@ -57,11 +104,14 @@ func (s *state) load() error {
 		return err
 	}
 	s.cfg = cfg
-	return nil
+	if err := validateHTTPConfig(s.cfg.HTTP); err != nil {
+		return err
+	}
+	return profiles.Validate(s.cfg.HeaderProfiles)
 }
 
 func (s *state) save() error {
-	if !s.dirty {
+	if !s.dirty || s.replaying {
 		return nil
 	}
 	if s.configPath == "" {
@@ -75,3 +125,39 @@ func (s *state) save() error {
 }
 
 func (s *state) markDirty() { s.dirty = true }
+
+// openArchive opens the order archive (--archive, or the default data dir path).
+func (s *state) openArchive() (*archive.Archive, error) {
+	path := s.archivePath
+	if path == "" {
+		p, err := archive.DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		path = p
+	}
+	return archive.Open(path)
+}
+
+// openCassette sets up --record or --replay. Both share one recorder/player
+// across every client so a replay sees requests in recording order.
+func (s *state) openCassette(record, replay string) error {
+	switch {
+	case record != "" && replay != "":
+		return errors.New("--record and --replay are mutually exclusive")
+	case record != "":
+		rec, err := cassette.NewRecorder(record, s.base)
+		if err != nil {
+			return fmt.Errorf("--record: %w", err)
+		}
+		s.cassette = rec
+	case replay != "":
+		p, err := cassette.NewPlayer(replay)
+		if err != nil {
+			return fmt.Errorf("--replay: %w", err)
+		}
+		s.cassette = p
+		s.replaying = true
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/stats"
+)
+
+func newStatsCmd(st *state) *cobra.Command {
+	var period string
+	var top int
+	var src orderSource
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize spending per provider (per month/week, average basket, top vendors)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := stats.ParsePeriod(period)
+			if err != nil {
+				return err
+			}
+			orders, err := src.load(cmd, st)
+			if err != nil {
+				return err
+			}
+
+			rep := stats.Compute(orders, stats.Options{Period: p, Top: top})
+			return st.emit(cmd, asJSON, rep, func(out io.Writer) { printStats(out, rep) })
+		},
+	}
+
+	cmd.Flags().StringVar(&period, "period", "month", "bucket spend by month or week")
+	cmd.Flags().IntVar(&top, "top", 5, "top vendors per provider")
+	src.addFlags(cmd)
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON")
+	return cmd
+}
+
+func printStats(out io.Writer, rep stats.Report) {
+	if len(rep.Providers) == 0 {
+		fmt.Fprintln(out, "no orders")
+		return
+	}
+	for i, s := range rep.Providers {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		title := s.Provider
+		if s.Currency != "" {
+			title += " (" + s.Currency + ")"
+		}
+		fmt.Fprintf(out, "%s: %d orders, total %s, average basket %s", title, s.Orders, money(s.Total), money(s.AverageBasket))
+		if s.Cancelled > 0 {
+			fmt.Fprintf(out, ", %d cancelled", s.Cancelled)
+		}
+		if s.Undated > 0 {
+			fmt.Fprintf(out, ", %d without a date (in no %s)", s.Undated, rep.Period)
+		}
+		fmt.Fprintln(out)
+
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintf(tw, "%s\torders\ttotal\t\n", rep.Period)
+		for _, b := range s.Periods {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t\n", b.Key, b.Orders, money(b.Total))
+		}
+		_ = tw.Flush()
+
+		if len(s.TopVendors) > 0 {
+			fmt.Fprintln(out, "top vendors:")
+			tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			for _, v := range s.TopVendors {
+				fmt.Fprintf(tw, "  %s\t%d\t%s\n", v.Name, v.Orders, money(v.Total))
+			}
+			_ = tw.Flush()
+		}
+	}
+}
+
+func money(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
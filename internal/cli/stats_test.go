@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/archive"
+	"github.com/steipete/ordercli/internal/provider"
+	"github.com/steipete/ordercli/internal/stats"
+)
+
+func TestStats_LiveTableAndJSON(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+
+	fd := newFoodoraTestServer(t)
+	defer fd.Close()
+	cfgPath := writeMultiProviderConfig(t, fd.URL, "")
+
+	out, errOut, err := runCLI(cfgPath, []string{"stats"}, "")
+	if err != nil {
+		t.Fatalf("stats: %v err=%s", err, errOut)
+	}
+	if !strings.Contains(out, "foodora (EUR): 1 orders, total 12.30, average basket 12.30") || !strings.Contains(out, "Test Vendor") {
+		t.Fatalf("out=%q", out)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"stats", "--json", "--period", "week"}, "")
+	if err != nil {
+		t.Fatalf("stats --json: %v", err)
+	}
+	var rep stats.Report
+	if err := json.Unmarshal([]byte(out), &rep); err != nil {
+		t.Fatalf("json: %v out=%q", err, out)
+	}
+	if rep.Period != stats.Week || len(rep.Providers) != 1 || rep.Providers[0].Total != 12.3 || len(rep.Providers[0].Periods) != 1 {
+		t.Fatalf("rep=%+v", rep)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"stats", "--period", "year"}, ""); err == nil {
+		t.Fatalf("expected period error")
+	}
+}
+
+func TestStats_ArchivedWithDateRange(t *testing.T) {
+	arcPath := filepath.Join(t.TempDir(), "archive.json")
+	arc, _ := archive.Open(arcPath)
+	now := time.Now()
+	arc.Upsert("", provider.Order{Provider: "glovo", ID: "1", Vendor: "A", Total: 5, Currency: "EUR", Time: time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)}, now)
+	arc.Upsert("", provider.Order{Provider: "glovo", ID: "2", Vendor: "B", Total: 7, Currency: "EUR", Time: time.Date(2025, 4, 1, 12, 0, 0, 0, time.Local)}, now)
+	arc.Upsert("", provider.Order{Provider: "glovo", ID: "3", Vendor: "C", Total: 9, Currency: "EUR"}, now)
+	if err := arc.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	cfgPath := writeMultiProviderConfig(t, "", "")
+	out, errOut, err := runCLI(cfgPath, []string{"--archive", arcPath, "stats", "--archived", "--since", "2025-03-15"}, "")
+	if err != nil {
+		t.Fatalf("stats: %v err=%s", err, errOut)
+	}
+	if !strings.Contains(out, "glovo (EUR): 1 orders, total 7.00") || strings.Contains(out, "2025-03") {
+		t.Fatalf("out=%q", out)
+	}
+	if !strings.Contains(errOut, "left out 1 glovo orders without a date") {
+		t.Fatalf("stderr=%q", errOut)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"--archive", arcPath, "stats", "--archived"}, "")
+	if err != nil || !strings.Contains(out, "glovo (EUR): 3 orders, total 21.00, average basket 7.00, 1 without a date (in no month)") {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"--archive", arcPath, "stats", "--archived", "--until", "2025-01-01"}, "")
+	if err != nil || !strings.Contains(out, "no orders") {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"--archive", arcPath, "stats", "--archived", "--since", "2025-05-01", "--until", "2025-04-01"}, ""); err == nil {
+		t.Fatalf("expected range error")
+	}
+	if _, _, err := runCLI(cfgPath, []string{"--archive", arcPath, "stats", "--archived", "--since", "May"}, ""); err == nil {
+		t.Fatalf("expected date error")
+	}
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/archive"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func newSyncCmd(st *state) *cobra.Command {
+	var only []string
+	var limit int
+	var full bool
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Copy new orders from every configured provider into the local archive",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := st.selectProviders(only)
+			if err != nil {
+				return err
+			}
+			arc, err := st.openArchive()
+			if err != nil {
+				return err
+			}
+
+			type result struct {
+				res archive.SyncResult
+				err error
+			}
+			results := make([]result, len(names))
+
+			ctx := cmd.Context()
+			opts := archive.SyncOptions{Full: full, Limit: limit}
+			var wg sync.WaitGroup
+			for i, name := range names {
+				p, err := openProvider(st, name)
+				if err != nil {
+					results[i].err = err
+					continue
+				}
+				// After openProvider, so a refreshed foodora token is used.
+				account := accountKey(st, name)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					results[i].res, results[i].err = arc.Sync(ctx, p, account, opts)
+				}()
+			}
+			wg.Wait()
+
+			summary := syncSummary{Providers: make([]syncProviderSummary, 0, len(names))}
+			failed := 0
+			for i, r := range results {
+				ps := syncProviderSummary{
+					Provider:    names[i],
+					Fetched:     r.res.Fetched,
+					Added:       r.res.Added,
+					Incremental: r.res.Incremental,
+				}
+				if r.err != nil {
+					failed++
+					ps.Error = r.err.Error()
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %v\n", names[i], r.err)
+				}
+				summary.Providers = append(summary.Providers, ps)
+			}
+			if err := arc.Save(); err != nil {
+				return err
+			}
+			summary.Archive, summary.Orders = arc.Path(), arc.Len()
+
+			err = st.emit(cmd, false, summary, func(out io.Writer) {
+				for _, ps := range summary.Providers {
+					mode := ""
+					if ps.Incremental {
+						mode = ", incremental"
+					}
+					fmt.Fprintf(out, "%s: %d orders (%d new%s)\n", ps.Provider, ps.Fetched, ps.Added, mode)
+				}
+				fmt.Fprintf(out, "archive=%s orders=%d\n", summary.Archive, summary.Orders)
+			})
+			if err != nil {
+				return err
+			}
+			if failed == len(names) {
+				return errors.New("sync: every provider failed")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&only, "provider", nil, "only these providers (repeatable; default: all configured)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "max orders to fetch per provider (0 = all); a capped sync resumes next time")
+	cmd.Flags().BoolVar(&full, "full", false, "ignore stored cursors and rescan the whole history")
+	return cmd
+}
+
+type syncSummary struct {
+	Archive   string                `json:"archive"`
+	Orders    int                   `json:"orders"`
+	Providers []syncProviderSummary `json:"providers"`
+}
+
+type syncProviderSummary struct {
+	Provider    string `json:"provider"`
+	Fetched     int    `json:"fetched"`
+	Added       int    `json:"added"`
+	Incremental bool   `json:"incremental"`
+	Error       string `json:"error,omitempty"`
+}
+
+// accountKey identifies the login a provider is used with, so archived orders
+// from different accounts can be told apart. It never contains secrets.
+func accountKey(st *state, name string) string {
+	switch name {
+	case provider.Foodora:
+		cfg := st.cfg.Providers.Foodora
+		if cfg == nil {
+			return ""
+		}
+		host := cfg.BaseURL
+		if u, err := url.Parse(cfg.BaseURL); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		if sub, ok := config.AccessTokenSubject(cfg.AccessToken); ok {
+			return host + "/" + sub
+		}
+		return host
+	case provider.Deliveroo:
+		market := ""
+		if cfg := st.cfg.Providers.Deliveroo; cfg != nil {
+			market = strings.TrimSpace(cfg.Market)
+		}
+		if sub, ok := config.AccessTokenSubject(strings.TrimSpace(os.Getenv("DELIVEROO_BEARER_TOKEN"))); ok {
+			return strings.TrimPrefix(market+"/"+sub, "/")
+		}
+		return market
+	case provider.Glovo:
+		cfg := st.cfg.Providers.Glovo
+		if cfg == nil {
+			return ""
+		}
+		if sub, ok := config.AccessTokenSubject(cfg.AccessToken); ok {
+			return strings.TrimPrefix(cfg.CountryCode+"/"+sub, "/")
+		}
+		return cfg.CountryCode
+	}
+	return ""
+}
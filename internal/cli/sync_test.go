@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/ordercli/internal/archive"
+)
+
+func TestSync_FillsArchiveAndHistoryReadsIt(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+
+	fd := newFoodoraTestServer(t)
+	defer fd.Close()
+	gl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "expired", http.StatusUnauthorized)
+	}))
+	defer gl.Close()
+
+	cfgPath := writeMultiProviderConfig(t, fd.URL, gl.URL)
+	arcPath := filepath.Join(t.TempDir(), "archive.json")
+
+	out, errOut, err := runCLI(cfgPath, []string{"--archive", arcPath, "sync"}, "")
+	if err != nil {
+		t.Fatalf("sync: %v err=%s", err, errOut)
+	}
+	if !strings.Contains(out, "foodora: 1 orders (1 new)") || !strings.Contains(out, "glovo: 0 orders (0 new)") {
+		t.Fatalf("out=%q", out)
+	}
+	if !strings.Contains(errOut, "warning: glovo:") {
+		t.Fatalf("err=%q", errOut)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"--archive", arcPath, "sync", "--provider", "foodora"}, "")
+	if err != nil || !strings.Contains(out, "foodora: 1 orders (0 new, incremental)") {
+		t.Fatalf("resync out=%q err=%v", out, err)
+	}
+	out, _, err = runCLI(cfgPath, []string{"--archive", arcPath, "sync", "--provider", "foodora", "--full"}, "")
+	if err != nil || !strings.Contains(out, "foodora: 1 orders (0 new)\n") {
+		t.Fatalf("full out=%q err=%v", out, err)
+	}
+
+	arc, err := archive.Open(arcPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	recs := arc.Orders(archive.Filter{})
+	if len(recs) != 1 || recs[0].ID != "HIST-1" || len(recs[0].Raw) == 0 || recs[0].Account == "" {
+		t.Fatalf("recs=%+v", recs)
+	}
+
+	// The archive stays readable without any configured provider.
+	empty := writeMultiProviderConfig(t, "", "")
+	out, errOut, err = runCLI(empty, []string{"--archive", arcPath, "history", "--archived"}, "")
+	if err != nil {
+		t.Fatalf("history --archived: %v err=%s", err, errOut)
+	}
+	if !strings.Contains(out, "\tfoodora\tHIST-1\tTest Vendor\tdelivered\t12.30 EUR") {
+		t.Fatalf("out=%q", out)
+	}
+	if _, _, err := runCLI(empty, []string{"--archive", arcPath, "history", "--archived", "--provider", "nope"}, ""); err == nil {
+		t.Fatalf("expected unknown provider error")
+	}
+}
+
+func TestSync_AllFail(t *testing.T) {
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+
+	gl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer gl.Close()
+	arcPath := filepath.Join(t.TempDir(), "archive.json")
+	_, _, err := runCLI(writeMultiProviderConfig(t, "", gl.URL), []string{"--archive", arcPath, "sync"}, "")
+	if err == nil || !strings.Contains(err.Error(), "every provider failed") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestAccountKey(t *testing.T) {
+	st := &state{}
+	st.cfg.Providers.Foodora = nil
+	if got := accountKey(st, "foodora"); got != "" {
+		t.Fatalf("got %q", got)
+	}
+	st.foodora().BaseURL = "https://mj.fd-api.com/api/v5/"
+	if got := accountKey(st, "foodora"); got != "mj.fd-api.com" {
+		t.Fatalf("got %q", got)
+	}
+	st.glovo().CountryCode = "ES"
+	if got := accountKey(st, "glovo"); got != "ES" {
+		t.Fatalf("got %q", got)
+	}
+	setEnv(t, "DELIVEROO_BEARER_TOKEN", "")
+	st.deliveroo().Market = "uk"
+	if got := accountKey(st, "deliveroo"); got != "uk" {
+		t.Fatalf("got %q", got)
+	}
+}
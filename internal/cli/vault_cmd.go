@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/vault"
+)
+
+// passphraseEnv unlocks an encrypted config without a prompt.
+const passphraseEnv = "ORDERCLI_PASSPHRASE"
+
+// keepLocked is the annotation of commands that run without unlocking an
+// encrypted config.
+const keepLocked = "ordercli/keep-locked"
+
+// unlock decrypts the secrets of an encrypted config with $ORDERCLI_PASSPHRASE,
+// the key cached by `ordercli config unlock`, or a prompt, in that order.
+func (s *state) unlock(cmd *cobra.Command) error {
+	if !s.cfg.Locked() || cmd.Annotations[keepLocked] != "" {
+		return nil
+	}
+	if p, ok := os.LookupEnv(passphraseEnv); ok {
+		if err := s.cfg.Unlock(p); err != nil {
+			return fmt.Errorf("%s: %w", passphraseEnv, err)
+		}
+		return nil
+	}
+	if key, err := vault.AgentKey(vault.SocketPath(), s.cfg.Vault.ID()); err == nil && s.cfg.UnlockKey(key) == nil {
+		s.logf("config unlocked by agent")
+		return nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("%w: set %s or run `ordercli config unlock`", config.ErrLocked, passphraseEnv)
+	}
+	p, err := readPassphrase(cmd, "Config passphrase: ")
+	if err != nil {
+		return err
+	}
+	return s.cfg.Unlock(p)
+}
+
+func readPassphrase(cmd *cobra.Command, prompt string) (string, error) {
+	fmt.Fprint(cmd.ErrOrStderr(), prompt)
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(cmd.ErrOrStderr())
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// newPassphrase reads the passphrase for `config encrypt`: from the
+// environment, or typed twice.
+func newPassphrase(cmd *cobra.Command) (string, error) {
+	if p, ok := os.LookupEnv(passphraseEnv); ok {
+		if p == "" {
+			return "", fmt.Errorf("%s is empty", passphraseEnv)
+		}
+		return p, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("no terminal to ask for a passphrase; set %s", passphraseEnv)
+	}
+	p, err := readPassphrase(cmd, "New config passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", errors.New("empty passphrase")
+	}
+	again, err := readPassphrase(cmd, "Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if again != p {
+		return "", errors.New("passphrases do not match")
+	}
+	return p, nil
+}
+
+func newVaultCmd(st *state) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Encrypt secrets in the config file",
+	}
+	cmd.AddCommand(newVaultEncryptCmd(st))
+	cmd.AddCommand(newVaultDecryptCmd(st))
+	cmd.AddCommand(newVaultUnlockCmd(st))
+	cmd.AddCommand(newVaultLockCmd(st))
+	cmd.AddCommand(newVaultAgentCmd(st))
+	return cmd
+}
+
+func newVaultEncryptCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt tokens, cookies and other secrets with a passphrase",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if st.cfg.Encrypted() {
+				return errors.New("config is already encrypted (decrypt it first to change the passphrase)")
+			}
+			p, err := newPassphrase(cmd)
+			if err != nil {
+				return err
+			}
+			if err := st.cfg.EnableVault(p); err != nil {
+				return err
+			}
+			st.markDirty()
+			return st.emitOK(cmd, "config encrypted")
+		},
+	}
+}
+
+func newVaultDecryptCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "decrypt",
+		Short: "Store secrets in plain text again",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := st.cfg.DisableVault(); err != nil {
+				return err
+			}
+			st.markDirty()
+			// The cached key is of no use anymore.
+			_ = vault.StopAgent(vault.SocketPath())
+			return st.emitOK(cmd, "config decrypted")
+		},
+	}
+}
+
+func newVaultUnlockCmd(st *state) *cobra.Command {
+	var ttl time.Duration
+	cmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Cache the config key in a background agent",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !st.cfg.Encrypted() {
+				return errors.New("config is not encrypted")
+			}
+			if ttl <= 0 {
+				return errors.New("--ttl must be positive")
+			}
+			// Replace an agent holding another (or the same) key.
+			_ = vault.StopAgent(vault.SocketPath())
+			if err := startAgent(st.configPath, st.cfg.Vault.ID(), st.cfg.Key(), ttl); err != nil {
+				return err
+			}
+			return st.emitOK(cmd, fmt.Sprintf("unlocked for %s", ttl))
+		},
+	}
+	cmd.Flags().DurationVar(&ttl, "ttl", 15*time.Minute, "how long the agent keeps the key")
+	return cmd
+}
+
+func newVaultLockCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:         "lock",
+		Short:       "Make the agent forget the config key",
+		Annotations: map[string]string{keepLocked: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := vault.StopAgent(vault.SocketPath()); err != nil {
+				return st.emitOK(cmd, "no agent running")
+			}
+			return st.emitOK(cmd, "locked")
+		},
+	}
+}
+
+// newVaultAgentCmd is the process started by `config unlock`: it reads the
+// key from stdin and serves it on the agent socket until the TTL is up.
+func newVaultAgentCmd(st *state) *cobra.Command {
+	var id string
+	var ttl time.Duration
+	cmd := &cobra.Command{
+		Use:         "agent",
+		Hidden:      true,
+		Annotations: map[string]string{keepLocked: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+			if err != nil {
+				return err
+			}
+			key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(line))
+			if err != nil {
+				return err
+			}
+			ln, err := vault.Listen(vault.SocketPath())
+			if err != nil {
+				return err
+			}
+			// Outlive the terminal `config unlock` ran in.
+			signal.Ignore(syscall.SIGHUP)
+			fmt.Fprintln(cmd.OutOrStdout(), "ready")
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return vault.Serve(ctx, ln, id, key, ttl)
+		},
+	}
+	cmd.Flags().StringVar(&id, "id", "", "vault id")
+	cmd.Flags().DurationVar(&ttl, "ttl", 15*time.Minute, "how long to keep the key")
+	return cmd
+}
+
+// startAgent runs `ordercli config agent` in the background and waits until
+// it listens.
+func startAgent(configPath, id string, key []byte, ttl time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	c := exec.Command(exe, "--config", configPath, "config", "agent", "--id", id, "--ttl", ttl.String())
+	c.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString(key) + "\n")
+	out, err := c.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := c.Start(); err != nil {
+		return fmt.Errorf("start agent: %w", err)
+	}
+	line, _ := bufio.NewReader(out).ReadString('\n')
+	if strings.TrimSpace(line) != "ready" {
+		_ = c.Wait()
+		return errors.New("start agent: agent exited before listening")
+	}
+	return c.Process.Release()
+}
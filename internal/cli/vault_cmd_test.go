@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/vault"
+)
+
+func TestVault_EncryptUnlockDecrypt(t *testing.T) {
+	var auth string
+	var proxied bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = proxied || r.URL.IsAbs()
+		auth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":200,"data":{"count":0,"active_orders":[]}}`))
+	}))
+	defer srv.Close()
+	cfgPath := writeMultiProviderConfig(t, srv.URL, "")
+	// The test server doubles as the proxy, so a proxy that was not
+	// unlocked would fail the requests below.
+	plain, _ := config.Load(cfgPath)
+	plain.HTTP = &config.HTTPConfig{Proxy: "http://me:pr0xy@" + strings.TrimPrefix(srv.URL, "http://")}
+	if err := config.Save(cfgPath, plain); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	setEnv(t, "ORDERCLI_VAULT_SOCK", sock)
+	setEnv(t, passphraseEnv, "s3same")
+
+	if _, _, err := runCLI(cfgPath, []string{"config", "encrypt"}, ""); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	b, _ := os.ReadFile(cfgPath)
+	if !strings.Contains(string(b), `"access_token": "vault:v1:`) || strings.Contains(string(b), `"refresh"`) || strings.Contains(string(b), "pr0xy") {
+		t.Fatalf("expected sealed tokens:\n%s", b)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"config", "encrypt"}, ""); err == nil {
+		t.Fatalf("expected error encrypting twice")
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "orders"}, ""); err != nil || auth != "Bearer access" || !proxied {
+		t.Fatalf("orders with passphrase: auth=%q proxied=%v err=%v", auth, proxied, err)
+	}
+
+	setEnv(t, passphraseEnv, "wrong")
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "orders"}, ""); !errors.Is(err, vault.ErrWrongKey) {
+		t.Fatalf("want wrong passphrase, got %v", err)
+	}
+	_ = os.Unsetenv(passphraseEnv)
+	_, _, err := runCLI(cfgPath, []string{"foodora", "orders"}, "")
+	if !errors.Is(err, config.ErrLocked) || !strings.Contains(err.Error(), "config unlock") {
+		t.Fatalf("want locked error, got %v", err)
+	}
+
+	// An agent holding the key unlocks without a passphrase.
+	cfg, _ := config.Load(cfgPath)
+	if err := cfg.Unlock("s3same"); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	ln, err := vault.Listen(sock)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- vault.Serve(context.Background(), ln, cfg.Vault.ID(), cfg.Key(), time.Minute) }()
+	auth = ""
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "orders"}, ""); err != nil || auth != "Bearer access" {
+		t.Fatalf("orders with agent: auth=%q err=%v", auth, err)
+	}
+	if out, _, err := runCLI(cfgPath, []string{"config", "lock"}, ""); err != nil || !strings.Contains(out, "locked") {
+		t.Fatalf("lock: out=%q err=%v", out, err)
+	}
+	<-done
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "orders"}, ""); !errors.Is(err, config.ErrLocked) {
+		t.Fatalf("want locked after lock, got %v", err)
+	}
+
+	setEnv(t, passphraseEnv, "s3same")
+	if _, _, err := runCLI(cfgPath, []string{"config", "decrypt"}, ""); err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if b, _ := os.ReadFile(cfgPath); !strings.Contains(string(b), `"access_token": "access"`) || strings.Contains(string(b), "vault") {
+		t.Fatalf("expected plain config:\n%s", b)
+	}
+}
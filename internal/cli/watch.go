@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/notify"
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// statusFeed turns successive active-order polls into transitions and pushes
+// each one to the configured notification sinks.
+type statusFeed struct {
+	tr     poller.Tracker
+	n      *notify.Notifier
+	errOut io.Writer
+}
+
+func (s *state) newStatusFeed(cmd *cobra.Command) (*statusFeed, error) {
+	n, err := s.notifier()
+	if err != nil {
+		return nil, err
+	}
+	return &statusFeed{n: n, errOut: cmd.ErrOrStderr()}, nil
+}
+
+// update records a successful poll of one provider; notification failures
+// are warnings.
+func (f *statusFeed) update(ctx context.Context, providerName string, orders []provider.Order, at time.Time) []poller.Event {
+	events := f.tr.Update(providerName, orders, at)
+	for _, ev := range events {
+		if err := f.n.Send(ctx, ev); err != nil {
+			fmt.Fprintf(f.errOut, "warning: notify: %v\n", err)
+		}
+	}
+	return events
+}
+
+// watchTransitions runs p and prints only status transitions until every
+// order seen is delivered (exit 0) or has left the active list (exit
+// ExitUndelivered). Without any active order it says so on stderr and waits
+// for one. Cancelling the command context returns context.Canceled. The
+// config is saved before either error is returned.
+func (s *state) watchTransitions(cmd *cobra.Command, p *poller.Poller, legacyJSON bool) error {
+	feed, err := s.newStatusFeed(cmd)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	out, errOut := cmd.OutOrStdout(), cmd.ErrOrStderr()
+	format := s.format(legacyJSON)
+	var result error
+	finished := false
+	seen, waiting := false, false
+
+	p.Run(ctx, func(snap poller.Snapshot) {
+		if snap.Err != nil {
+			fmt.Fprintf(errOut, "warning: %s: %v\n", snap.Provider, snap.Err)
+			return
+		}
+		seen = seen || len(snap.Orders) > 0
+		if !seen && !waiting {
+			fmt.Fprintln(errOut, "no active orders, waiting…")
+			waiting = true
+		}
+		for _, ev := range feed.update(ctx, snap.Provider, snap.Orders, snap.At) {
+			if err := s.printEvent(out, format, ev); err != nil {
+				fmt.Fprintf(errOut, "warning: template: %v\n", err)
+			}
+		}
+		done, undelivered := feed.tr.Finished()
+		if !done {
+			return
+		}
+		finished = true
+		if undelivered > 0 {
+			result = &ExitError{Code: ExitUndelivered, Err: fmt.Errorf("watch: %d order(s) left the active list without being delivered", undelivered)}
+		}
+		cancel()
+	})
+
+	if !finished {
+		result = cmd.Context().Err()
+	}
+	if result != nil {
+		// PersistentPostRunE is skipped after an error; keep tokens refreshed
+		// while watching.
+		if err := s.save(); err != nil {
+			fmt.Fprintf(errOut, "warning: save config: %v\n", err)
+		}
+	}
+	return result
+}
+
+func (s *state) printEvent(out io.Writer, format string, ev poller.Event) error {
+	switch format {
+	case outputJSON, outputNDJSON:
+		return json.NewEncoder(out).Encode(ev)
+	case outputTemplate:
+		return s.execTemplate(out, ev)
+	}
+	_, err := fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\n", ev.At.Local().Format(time.DateTime), ev.Provider, ev.OrderID, ev.Vendor, ev.Summary())
+	return err
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// scriptedProvider returns one active-order list per poll, repeating the last.
+type scriptedProvider struct {
+	mu    sync.Mutex
+	polls [][]provider.Order
+}
+
+func (p *scriptedProvider) Name() string                      { return "foodora" }
+func (p *scriptedProvider) Capabilities() provider.Capability { return provider.CapActiveOrders }
+func (p *scriptedProvider) History(context.Context, provider.HistoryRequest) (provider.HistoryPage, error) {
+	return provider.HistoryPage{}, provider.ErrUnsupported
+}
+
+func (p *scriptedProvider) ActiveOrders(context.Context) (provider.ActiveOrders, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.polls) == 0 {
+		return provider.ActiveOrders{}, errors.New("boom")
+	}
+	orders := p.polls[0]
+	if len(p.polls) > 1 {
+		p.polls = p.polls[1:]
+	}
+	return provider.ActiveOrders{Orders: orders}, nil
+}
+
+func (p *scriptedProvider) OrderDetail(context.Context, string) (provider.Order, error) {
+	return provider.Order{}, provider.ErrUnsupported
+}
+
+func (p *scriptedProvider) Me(context.Context) (provider.Account, error) {
+	return provider.Account{}, provider.ErrUnsupported
+}
+
+func runWatch(t *testing.T, ctx context.Context, st *state, p provider.Provider) (string, string, error) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetContext(ctx)
+	err := st.watchTransitions(cmd, &poller.Poller{Providers: []provider.Provider{p}, Interval: time.Millisecond, Min: time.Millisecond}, false)
+	return out.String(), errOut.String(), err
+}
+
+func TestWatchTransitions_UntilDelivered(t *testing.T) {
+	p := &scriptedProvider{polls: [][]provider.Order{
+		{{ID: "OC-1", Vendor: "Vendor", Status: "Cooking"}},
+		{{ID: "OC-1", Vendor: "Vendor", Status: "Cooking"}},
+		{{ID: "OC-1", Vendor: "Vendor", Status: "On the way"}},
+		{{ID: "OC-1", Vendor: "Vendor", Status: "Delivered", Delivered: true}},
+	}}
+	out, _, err := runWatch(t, context.Background(), &state{}, p)
+	if err != nil || ExitCode(err) != 0 {
+		t.Fatalf("err=%v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 ||
+		!strings.HasSuffix(lines[0], "\tfoodora\tOC-1\tVendor\tCooking") ||
+		!strings.HasSuffix(lines[1], "\tCooking -> On the way") ||
+		!strings.HasSuffix(lines[2], "\tOn the way -> Delivered (delivered)") {
+		t.Fatalf("out=%q", out)
+	}
+}
+
+func TestWatchTransitions_GoneAndJSON(t *testing.T) {
+	p := &scriptedProvider{polls: [][]provider.Order{
+		{{ID: "OC-1", Status: "Cooking"}},
+		nil,
+	}}
+	st := &state{out: output{format: outputNDJSON}}
+	out, _, err := runWatch(t, context.Background(), st, p)
+	if ExitCode(err) != ExitUndelivered {
+		t.Fatalf("err=%v", err)
+	}
+	if strings.Count(out, "\n") != 2 || !strings.Contains(out, `"gone":true`) {
+		t.Fatalf("out=%q", out)
+	}
+}
+
+func TestWatchTransitions_WaitsForAnOrder(t *testing.T) {
+	p := &scriptedProvider{polls: [][]provider.Order{
+		{},
+		{},
+		{{ID: "OC-1", Vendor: "Vendor", Status: "Delivered", Delivered: true}},
+	}}
+	out, errOut, err := runWatch(t, context.Background(), &state{}, p)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if strings.Count(errOut, "no active orders, waiting") != 1 {
+		t.Fatalf("stderr=%q", errOut)
+	}
+	if !strings.Contains(out, "OC-1") {
+		t.Fatalf("out=%q", out)
+	}
+}
+
+func TestWatchTransitions_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, errOut, err := runWatch(t, ctx, &state{}, &scriptedProvider{})
+	if !errors.Is(err, context.Canceled) || ExitCode(err) != ExitInterrupted {
+		t.Fatalf("err=%v", err)
+	}
+	if !strings.Contains(errOut, "warning: foodora: boom") {
+		t.Fatalf("errOut=%q", errOut)
+	}
+}
+
+func TestWatchTransitions_SavesOnError(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	st := &state{configPath: cfgPath, cfg: config.New()}
+	st.foodora().AccessToken = "refreshed"
+	st.markDirty()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, _, err := runWatch(t, ctx, st, &scriptedProvider{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err=%v", err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil || cfg.Foodora().AccessToken != "refreshed" {
+		t.Fatalf("config not saved: %v", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if ExitCode(nil) != 0 || ExitCode(errors.New("x")) != 1 {
+		t.Fatalf("basic codes")
+	}
+	err := &ExitError{Code: 7, Err: errors.New("seven")}
+	if ExitCode(err) != 7 || err.Error() != "seven" || !errors.Is(err, err.Err) {
+		t.Fatalf("exit error")
+	}
+}
+
+func TestWatchTransitions_Notifies(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	st := &state{}
+	st.cfg.Notify = &config.NotifyConfig{Sinks: []config.NotifySink{{Type: "ntfy", URL: srv.URL}}}
+	p := &scriptedProvider{polls: [][]provider.Order{
+		{{ID: "OC-1", Status: "Cooking"}},
+		{{ID: "OC-1", Status: "Delivered", Delivered: true}},
+	}}
+	if _, _, err := runWatch(t, context.Background(), st, p); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 2 || bodies[1] != "OC-1: Cooking -> Delivered (delivered)" {
+		t.Fatalf("bodies=%q", bodies)
+	}
+
+	st.cfg.Notify.Sinks[0].Type = "pager"
+	if _, _, err := runWatch(t, context.Background(), st, p); err == nil {
+		t.Fatalf("expected config error")
+	}
+}
+
+func TestNotifyTestCmd(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits.Add(1) }))
+	defer srv.Close()
+
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	if _, _, err := runCLI(cfgPath, []string{"notify", "test"}, ""); err == nil {
+		t.Fatalf("expected no-sinks error")
+	}
+	cfg := config.New()
+	cfg.Notify = &config.NotifyConfig{Sinks: []config.NotifySink{{Type: "webhook", URL: srv.URL}}}
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	out, _, err := runCLI(cfgPath, []string{"notify", "test"}, "")
+	if err != nil || out != "sent to 1 sink(s)\n" || hits.Load() != 1 {
+		t.Fatalf("out=%q err=%v hits=%d", out, err, hits.Load())
+	}
+}
@@ -7,12 +7,26 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Version   int       `json:"version"`
-	Providers Providers `json:"providers,omitempty"`
+	Version    int               `json:"version"`
+	Providers  Providers         `json:"providers,omitempty"`
+	Accounting *AccountingConfig `json:"accounting,omitempty"`
+	Notify     *NotifyConfig     `json:"notify,omitempty"`
+	MQTT       *MQTTConfig       `json:"mqtt,omitempty"`
+	HTTP       *HTTPConfig       `json:"http,omitempty"`
+	// HeaderProfiles overrides and selects the app identity headers.
+	HeaderProfiles *HeaderProfilesConfig `json:"header_profiles,omitempty"`
+	// Vault, when set, keeps tokens, cookies and other secrets encrypted
+	// on disk; see Unlock.
+	Vault *VaultConfig `json:"vault,omitempty"`
+
+	// key is the vault key once unlocked.
+	key []byte
 }
 
 type Providers struct {
@@ -34,6 +48,9 @@ type FoodoraConfig struct {
 
 	HTTPUserAgent string            `json:"http_user_agent,omitempty"`
 	CookiesByHost map[string]string `json:"cookies_by_host,omitempty"`
+	// CookiesSavedAt is when each CookiesByHost entry was captured; Cloudflare
+	// clearance cookies only last so long.
+	CookiesSavedAt map[string]time.Time `json:"cookies_saved_at,omitempty"`
 
 	PendingMfaToken     string    `json:"pending_mfa_token,omitempty"`
 	PendingMfaChannel   string    `json:"pending_mfa_channel,omitempty"`
@@ -46,6 +63,128 @@ type DeliverooConfig struct {
 	BaseURL string `json:"base_url,omitempty"`
 }
 
+// AccountingConfig maps orders to accounts for the ledger/hledger/beancount
+// exports. Rules are tried in order; the first match wins, and the defaults
+// apply when no rule matches.
+type AccountingConfig struct {
+	ExpenseAccount string        `json:"expense_account,omitempty"`
+	FundingAccount string        `json:"funding_account,omitempty"`
+	Rules          []AccountRule `json:"rules,omitempty"`
+}
+
+// AccountRule matches by provider (exact) and/or vendor (case-insensitive
+// substring); empty fields match anything. An empty FundingAccount keeps the
+// default.
+type AccountRule struct {
+	Provider       string `json:"provider,omitempty"`
+	Vendor         string `json:"vendor,omitempty"`
+	ExpenseAccount string `json:"expense_account,omitempty"`
+	FundingAccount string `json:"funding_account,omitempty"`
+}
+
+// NotifyConfig lists the sinks that receive order status changes from the
+// watch loops.
+type NotifyConfig struct {
+	Sinks []NotifySink `json:"sinks,omitempty"`
+}
+
+// HTTPConfig tunes the HTTP transport shared by all provider clients.
+type HTTPConfig struct {
+	// Retry is keyed by provider ("foodora", "glovo", "deliveroo",
+	// "firebase"); "default" applies to providers without an entry.
+	Retry map[string]RetryPolicy `json:"retry,omitempty"`
+	// RateLimit is keyed by host ("api.glovoapp.com"); "default" applies to
+	// hosts without an entry.
+	RateLimit map[string]RateLimit `json:"rate_limit,omitempty"`
+	// Proxy is an http://, https://, socks5:// or socks5h:// URL used by
+	// every client and the browser helpers (default: HTTPS_PROXY etc.).
+	Proxy string `json:"proxy,omitempty"`
+	// CACerts are PEM files trusted in addition to the system roots.
+	CACerts []string `json:"ca_certs,omitempty"`
+	// Timeout bounds a whole request (default 20s); ConnectTimeout bounds
+	// dialing and the TLS handshake. Both are Go durations.
+	Timeout        string `json:"timeout,omitempty"`
+	ConnectTimeout string `json:"connect_timeout,omitempty"`
+	// Interface is the network interface name or local IP address outgoing
+	// connections are made from.
+	Interface string `json:"interface,omitempty"`
+}
+
+// RateLimit is a per-host token bucket: Burst requests at once, refilled at
+// RequestsPerSecond. Zero fields keep the defaults; Disabled turns it off.
+type RateLimit struct {
+	RequestsPerSecond float64 `json:"requests_per_second,omitempty"`
+	Burst             int     `json:"burst,omitempty"`
+	Disabled          bool    `json:"disabled,omitempty"`
+}
+
+// RetryPolicy overrides the retry defaults; zero fields keep them. Delays
+// are Go durations ("500ms", "10s"). MaxAttempts 1 disables retries.
+type RetryPolicy struct {
+	MaxAttempts int    `json:"max_attempts,omitempty"`
+	BaseDelay   string `json:"base_delay,omitempty"`
+	MaxDelay    string `json:"max_delay,omitempty"`
+}
+
+// HeaderProfilesConfig holds named header profiles and the rules that pick
+// one per provider. A profile named like a built-in one overrides only the
+// fields it sets. Rules are tried in order; the first match wins, and the
+// built-in choice applies when none matches.
+type HeaderProfilesConfig struct {
+	Profiles map[string]HeaderProfile `json:"profiles,omitempty"`
+	Rules    []HeaderProfileRule      `json:"rules,omitempty"`
+}
+
+// HeaderProfile is the app identity a provider client presents. Foodora
+// uses UserAgent, AppName and FPAPIKey; Glovo uses AppVersion and
+// ClientInfo. Headers are sent as-is on every request of either.
+type HeaderProfile struct {
+	Provider   string            `json:"provider,omitempty"`
+	UserAgent  string            `json:"user_agent,omitempty"`
+	AppName    string            `json:"app_name,omitempty"`
+	FPAPIKey   string            `json:"fp_api_key,omitempty"`
+	AppVersion string            `json:"app_version,omitempty"`
+	ClientInfo string            `json:"client_info,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+}
+
+// HeaderProfileRule selects Profile by provider (exact), country
+// (case-insensitive) and/or account (as shown by `profiles show`); empty
+// fields match anything.
+type HeaderProfileRule struct {
+	Provider string `json:"provider,omitempty"`
+	Country  string `json:"country,omitempty"`
+	Account  string `json:"account,omitempty"`
+	Profile  string `json:"profile"`
+}
+
+// MQTTConfig connects `ordercli mqtt` to a broker for Home Assistant.
+// Broker is tcp://host[:1883] or ssl://host[:8883]; the prefixes default to
+// "ordercli" and Home Assistant's "homeassistant".
+type MQTTConfig struct {
+	Broker          string `json:"broker,omitempty"`
+	ClientID        string `json:"client_id,omitempty"`
+	Username        string `json:"username,omitempty"`
+	Password        string `json:"password,omitempty"`
+	TopicPrefix     string `json:"topic_prefix,omitempty"`
+	DiscoveryPrefix string `json:"discovery_prefix,omitempty"`
+}
+
+// NotifySink is one notification target. Type picks the fields it uses:
+// "webhook" (URL, Headers), "ntfy" (topic URL, Token, Priority), "gotify"
+// (server URL, Token, Priority), "notify-send", and "exec" (Command, which
+// gets the event as JSON on stdin). Providers limits the sink to those
+// providers; empty means all.
+type NotifySink struct {
+	Type      string            `json:"type"`
+	URL       string            `json:"url,omitempty"`
+	Token     string            `json:"token,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Priority  int               `json:"priority,omitempty"`
+	Command   []string          `json:"command,omitempty"`
+	Providers []string          `json:"providers,omitempty"`
+}
+
 type GlovoConfig struct {
 	BaseURL     string  `json:"base_url,omitempty"`
 	AccessToken string  `json:"access_token,omitempty"`
@@ -65,6 +204,38 @@ func DefaultPath() (string, error) {
 	return filepath.Join(dir, "ordercli", "config.json"), nil
 }
 
+// DataDir is the per-user data directory for ordercli (archives and other
+// state that is not configuration): $XDG_DATA_HOME, ~/Library/Application
+// Support on macOS, %LocalAppData% on Windows, ~/.local/share elsewhere.
+func DataDir() (string, error) {
+	if d := strings.TrimSpace(os.Getenv("XDG_DATA_HOME")); d != "" {
+		return filepath.Join(d, "ordercli"), nil
+	}
+	switch runtime.GOOS {
+	case "windows":
+		if d := os.Getenv("LocalAppData"); d != "" {
+			return filepath.Join(d, "ordercli"), nil
+		}
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "ordercli"), nil
+	case "darwin":
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "ordercli"), nil
+	default:
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "share", "ordercli"), nil
+	}
+}
+
 func LegacyPathFoodcli() (string, error) {
 	dir, err := os.UserConfigDir()
 	if err != nil {
@@ -129,6 +300,14 @@ func Save(path string, cfg Config) error {
 		cfg.Providers.Foodora.DeviceID = newDeviceID()
 	}
 
+	if cfg.Vault != nil {
+		sealed, err := cfg.sealed()
+		if err != nil {
+			return err
+		}
+		cfg = sealed
+	}
+
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
 	}
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/base64"
 	"encoding/json"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,29 +16,56 @@ func AccessTokenExpiresAt(accessToken string) (time.Time, bool) {
 	return jwtExpiry(accessToken)
 }
 
-func jwtExpiry(token string) (time.Time, bool) {
+// AccessTokenSubject returns the account identifier of a JWT access token
+// (sub, falling back to user_id / customer_id).
+func AccessTokenSubject(accessToken string) (string, bool) {
+	var v struct {
+		Sub        any `json:"sub"`
+		UserID     any `json:"user_id"`
+		CustomerID any `json:"customer_id"`
+	}
+	if !jwtClaims(accessToken, &v) {
+		return "", false
+	}
+	for _, c := range []any{v.Sub, v.UserID, v.CustomerID} {
+		switch t := c.(type) {
+		case string:
+			if s := strings.TrimSpace(t); s != "" {
+				return s, true
+			}
+		case float64:
+			return strconv.FormatInt(int64(t), 10), true
+		}
+	}
+	return "", false
+}
+
+func jwtClaims(token string, out any) bool {
 	if strings.TrimSpace(token) == "" {
-		return time.Time{}, false
+		return false
 	}
 	_, payloadB64, ok := strings.Cut(token, ".")
 	if !ok {
-		return time.Time{}, false
+		return false
 	}
 	payloadB64, _, ok = strings.Cut(payloadB64, ".")
 	if !ok {
-		return time.Time{}, false
+		return false
 	}
 
 	payload, err := base64.RawURLEncoding.DecodeString(payloadB64)
 	if err != nil {
-		return time.Time{}, false
+		return false
 	}
+	return json.Unmarshal(payload, out) == nil
+}
 
+func jwtExpiry(token string) (time.Time, bool) {
 	var v struct {
 		Exp     int64 `json:"exp"`
 		Expires int64 `json:"expires"`
 	}
-	if err := json.Unmarshal(payload, &v); err != nil {
+	if !jwtClaims(token, &v) {
 		return time.Time{}, false
 	}
 	exp := v.Exp
@@ -74,3 +74,30 @@ func TestFoodoraConfig_TokenLikelyExpired_UsesJWT(t *testing.T) {
 		t.Fatalf("expected not expired")
 	}
 }
+
+func TestAccessTokenSubject(t *testing.T) {
+	mk := func(claims string) string {
+		return "h." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".s"
+	}
+	if got, ok := AccessTokenSubject(mk(`{"sub":" user-1 "}`)); !ok || got != "user-1" {
+		t.Fatalf("got %q ok=%v", got, ok)
+	}
+	if got, ok := AccessTokenSubject(mk(`{"user_id":12345}`)); !ok || got != "12345" {
+		t.Fatalf("got %q ok=%v", got, ok)
+	}
+	if _, ok := AccessTokenSubject(mk(`{"exp":1}`)); ok {
+		t.Fatalf("expected no subject")
+	}
+	if _, ok := AccessTokenSubject("not-a-jwt"); ok {
+		t.Fatalf("expected no subject")
+	}
+}
+
+func TestDataDir_XDG(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	got, err := DataDir()
+	if err != nil || got != filepath.Join(dir, "ordercli") {
+		t.Fatalf("got %q err=%v", got, err)
+	}
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/steipete/ordercli/internal/vault"
+)
+
+// VaultConfig is present when the secret fields of the config are stored
+// encrypted. Check is a sealed known value that tells a wrong passphrase
+// apart from a damaged field.
+type VaultConfig struct {
+	vault.Params
+	Check string `json:"check"`
+}
+
+// ErrLocked is returned when secrets are needed (or changed) before the
+// config was unlocked.
+var ErrLocked = errors.New("config is encrypted and locked")
+
+const (
+	vaultCheckName  = "vault"
+	vaultCheckValue = "ordercli"
+)
+
+// Encrypted reports whether the config is stored with a vault.
+func (c *Config) Encrypted() bool { return c.Vault != nil }
+
+// Locked reports whether secret fields still hold sealed values.
+func (c *Config) Locked() bool { return c.Vault != nil && c.key == nil }
+
+// Key is the vault key after Unlock (nil otherwise), for the agent.
+func (c *Config) Key() []byte { return c.key }
+
+// Unlock derives the key from passphrase and decrypts the secret fields.
+func (c *Config) Unlock(passphrase string) error {
+	if c.Vault == nil {
+		return nil
+	}
+	key, err := c.Vault.DeriveKey(passphrase)
+	if err != nil {
+		return err
+	}
+	return c.UnlockKey(key)
+}
+
+// UnlockKey decrypts the secret fields with an already derived key.
+func (c *Config) UnlockKey(key []byte) error {
+	if c.Vault == nil || c.key != nil {
+		return nil
+	}
+	if v, err := vault.Open(key, vaultCheckName, c.Vault.Check); err != nil || v != vaultCheckValue {
+		return vault.ErrWrongKey
+	}
+	err := c.secrets(func(name string, s *string) error {
+		if !vault.IsSealed(*s) {
+			return nil // set by hand; sealed on the next save
+		}
+		v, err := vault.Open(key, name, *s)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", name, err)
+		}
+		*s = v
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.key = key
+	return nil
+}
+
+// EnableVault makes Save encrypt the secret fields with a key derived from
+// passphrase.
+func (c *Config) EnableVault(passphrase string) error {
+	if c.Vault != nil {
+		return errors.New("config is already encrypted")
+	}
+	params, err := vault.NewParams()
+	if err != nil {
+		return err
+	}
+	key, err := params.DeriveKey(passphrase)
+	if err != nil {
+		return err
+	}
+	check, err := vault.Seal(key, vaultCheckName, vaultCheckValue)
+	if err != nil {
+		return err
+	}
+	c.Vault = &VaultConfig{Params: params, Check: check}
+	c.key = key
+	return nil
+}
+
+// DisableVault makes Save write the secret fields in plain text again.
+func (c *Config) DisableVault() error {
+	if c.Vault == nil {
+		return errors.New("config is not encrypted")
+	}
+	if c.key == nil {
+		return ErrLocked
+	}
+	c.Vault = nil
+	c.key = nil
+	return nil
+}
+
+// sealed returns a copy of c, which has a vault, with every secret field
+// encrypted. A locked config can only be written back unchanged.
+func (c *Config) sealed() (Config, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return Config{}, err
+	}
+	var out Config
+	if err := json.Unmarshal(b, &out); err != nil {
+		return Config{}, err
+	}
+	err = out.secrets(func(name string, s *string) error {
+		if *s == "" || vault.IsSealed(*s) {
+			return nil
+		}
+		if c.key == nil {
+			return fmt.Errorf("%w: cannot store %s", ErrLocked, name)
+		}
+		v, err := vault.Seal(c.key, name, *s)
+		if err != nil {
+			return err
+		}
+		*s = v
+		return nil
+	})
+	return out, err
+}
+
+// secrets calls fn with every secret field and its name, which is bound to
+// the sealed value. Names leave out map keys and list indexes so hand edits
+// that reorder sinks or hosts keep working.
+func (c *Config) secrets(fn func(name string, s *string) error) error {
+	if f := c.Providers.Foodora; f != nil {
+		for name, s := range map[string]*string{
+			"providers.foodora.access_token":      &f.AccessToken,
+			"providers.foodora.refresh_token":     &f.RefreshToken,
+			"providers.foodora.client_secret":     &f.ClientSecret,
+			"providers.foodora.pending_mfa_token": &f.PendingMfaToken,
+		} {
+			if err := fn(name, s); err != nil {
+				return err
+			}
+		}
+		for host, v := range f.CookiesByHost {
+			if err := fn("providers.foodora.cookies_by_host", &v); err != nil {
+				return err
+			}
+			f.CookiesByHost[host] = v
+		}
+	}
+	if g := c.Providers.Glovo; g != nil {
+		if err := fn("providers.glovo.access_token", &g.AccessToken); err != nil {
+			return err
+		}
+	}
+	if m := c.MQTT; m != nil {
+		if err := fn("mqtt.password", &m.Password); err != nil {
+			return err
+		}
+	}
+	if n := c.Notify; n != nil {
+		for i := range n.Sinks {
+			sink := &n.Sinks[i]
+			// Webhook URLs often are the credential (Slack, Discord, ntfy topics).
+			if err := fn("notify.sinks.url", &sink.URL); err != nil {
+				return err
+			}
+			if err := fn("notify.sinks.token", &sink.Token); err != nil {
+				return err
+			}
+			for k, v := range sink.Headers {
+				if err := fn("notify.sinks.headers", &v); err != nil {
+					return err
+				}
+				sink.Headers[k] = v
+			}
+		}
+	}
+	// A proxy URL is only sealed when it carries a user and password.
+	if h := c.HTTP; h != nil && (vault.IsSealed(h.Proxy) || strings.Contains(h.Proxy, "@")) {
+		if err := fn("http.proxy", &h.Proxy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
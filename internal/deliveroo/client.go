@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/steipete/ordercli/internal/apierr"
 )
 
 type Client struct {
@@ -24,6 +27,8 @@ type ClientOptions struct {
 	BearerToken string
 	Cookie      string
 	Timeout     time.Duration
+	// HTTPClient overrides the default client; Timeout is then ignored.
+	HTTPClient *http.Client
 }
 
 func NewClient(opts ClientOptions) (*Client, error) {
@@ -47,10 +52,13 @@ func NewClient(opts ClientOptions) (*Client, error) {
 		return nil, err
 	}
 
+	hc := opts.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: opts.Timeout}
+	}
+
 	return &Client{
-		http: &http.Client{
-			Timeout: opts.Timeout,
-		},
+		http:        hc,
 		market:      strings.TrimSpace(opts.Market),
 		consumerURL: consumer,
 		bearerToken: strings.TrimSpace(opts.BearerToken),
@@ -88,11 +96,13 @@ func (c *Client) OrderHistory(ctx context.Context, p OrderHistoryParams) (Orders
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-		return OrdersResponse{}, fmt.Errorf("deliveroo: unauthorized (%d)", resp.StatusCode)
-	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return OrdersResponse{}, fmt.Errorf("deliveroo: unexpected status %d", resp.StatusCode)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		err := fmt.Errorf("deliveroo: unexpected status %d", resp.StatusCode)
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			err = fmt.Errorf("deliveroo: unauthorized (%d)", resp.StatusCode)
+		}
+		return OrdersResponse{}, apierr.Wrap("deliveroo", resp, body, err)
 	}
 
 	var out OrdersResponse
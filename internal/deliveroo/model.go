@@ -51,6 +51,17 @@ type Order struct {
 	CurrencySymbol      string       `json:"currency_symbol"`
 	CurrencyCode        string       `json:"currency_code"`
 	Restaurant          *Restaurant  `json:"restaurant"`
+
+	Raw json.RawMessage `json:"-"`
+}
+
+func (o *Order) UnmarshalJSON(data []byte) error {
+	type plain Order
+	if err := json.Unmarshal(data, (*plain)(o)); err != nil {
+		return err
+	}
+	o.Raw = append(json.RawMessage(nil), data...)
+	return nil
 }
 
 type Restaurant struct {
@@ -28,10 +28,13 @@ type PublicStatus struct {
 	RawText          string   `json:"raw_text,omitempty"`
 }
 
-func FetchPublicStatus(ctx context.Context, targetURL string, timeout time.Duration) (PublicStatus, error) {
+// FetchPublicStatus reads a public order status page in a headless browser,
+// through proxy when it is not empty.
+func FetchPublicStatus(ctx context.Context, targetURL string, timeout time.Duration, proxy string) (PublicStatus, error) {
 	res, err := readBrowserPageText(ctx, targetURL, browserpage.Options{
 		Timeout:  timeout,
 		Headless: true,
+		Proxy:    proxy,
 	})
 	if err != nil {
 		return PublicStatus{}, err
@@ -157,6 +157,9 @@ func TestFetchPublicStatus(t *testing.T) {
 		if !opts.Headless {
 			t.Fatal("expected headless browser fetch")
 		}
+		if opts.Proxy != "http://proxy:3128" {
+			t.Fatalf("proxy=%q", opts.Proxy)
+		}
 		return browserpage.Result{
 			FinalURL: "https://deliveroo.example/final",
 			Title:    "Deliveroo",
@@ -164,7 +167,7 @@ func TestFetchPublicStatus(t *testing.T) {
 		}, nil
 	}
 
-	got, err := FetchPublicStatus(context.Background(), "https://deliveroo.example/status", 5*time.Second)
+	got, err := FetchPublicStatus(context.Background(), "https://deliveroo.example/status", 5*time.Second, "http://proxy:3128")
 	if err != nil {
 		t.Fatalf("FetchPublicStatus: %v", err)
 	}
@@ -187,7 +190,7 @@ func TestFetchPublicStatus_Error(t *testing.T) {
 		return browserpage.Result{}, errors.New("boom")
 	}
 
-	if _, err := FetchPublicStatus(context.Background(), "https://deliveroo.example/status", time.Second); err == nil || err.Error() != "boom" {
+	if _, err := FetchPublicStatus(context.Background(), "https://deliveroo.example/status", time.Second, ""); err == nil || err.Error() != "boom" {
 		t.Fatalf("err=%v", err)
 	}
 }
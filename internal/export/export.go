@@ -0,0 +1,188 @@
+// Package export writes normalized orders as flat records (CSV, NDJSON,
+// JSON) for spreadsheets and expense tools, or as plain-text accounting
+// journals (ledger, hledger, beancount).
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+	JSON   Format = "json"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case CSV, NDJSON, JSON, Ledger, HLedger, Beancount:
+		return f, nil
+	case "jsonl":
+		return NDJSON, nil
+	}
+	return "", fmt.Errorf("unknown format %q (use csv, ndjson, json, ledger, hledger or beancount)", s)
+}
+
+// Row is one exported record: an order header, or with Options.Items one line
+// item of an order (orders without items still get one row, with empty item
+// fields, so their totals are not lost).
+type Row struct {
+	Provider  string  `json:"provider"`
+	OrderID   string  `json:"order_id"`
+	Time      string  `json:"time"`
+	Vendor    string  `json:"vendor"`
+	Status    string  `json:"status"`
+	Delivered bool    `json:"delivered"`
+	Total     float64 `json:"total"`
+	Currency  string  `json:"currency"`
+	ItemCount int     `json:"item_count"`
+
+	ItemName     string  `json:"item_name,omitempty"`
+	ItemQuantity int     `json:"item_quantity,omitempty"`
+	ItemPrice    float64 `json:"item_price,omitempty"`
+}
+
+var (
+	headerColumns = []string{"provider", "order_id", "time", "vendor", "status", "delivered", "total", "currency", "item_count"}
+	itemColumns   = []string{"item_name", "item_quantity", "item_price"}
+)
+
+type Options struct {
+	// Items emits one row per line item instead of one per order.
+	Items bool
+	// Location formats times (default UTC for flat formats; journals use
+	// time.Local so dates match the day the order arrived).
+	Location *time.Location
+
+	// Accounting picks journal accounts; nil uses the defaults.
+	Accounting *config.AccountingConfig
+	// Skipped is told about orders a journal cannot book (cancelled,
+	// undated, no total or currency).
+	Skipped func(o provider.Order, reason string)
+}
+
+// Rows flattens orders in the given order.
+func Rows(orders []provider.Order, opts Options) []Row {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	rows := make([]Row, 0, len(orders))
+	for _, o := range orders {
+		base := Row{
+			Provider:  o.Provider,
+			OrderID:   o.ID,
+			Vendor:    o.Vendor,
+			Status:    o.Status,
+			Delivered: o.Delivered,
+			Total:     o.Total,
+			Currency:  o.Currency,
+			ItemCount: itemCount(o.Items),
+		}
+		if !o.Time.IsZero() {
+			base.Time = o.Time.In(loc).Format(time.RFC3339)
+		}
+		if !opts.Items || len(o.Items) == 0 {
+			rows = append(rows, base)
+			continue
+		}
+		for _, it := range o.Items {
+			r := base
+			r.ItemName = it.Name
+			r.ItemQuantity = it.Quantity
+			r.ItemPrice = it.Price
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+// Write renders orders (newest first, as history returns them) in format f.
+func Write(w io.Writer, f Format, orders []provider.Order, opts Options) error {
+	switch f {
+	case Ledger, HLedger, Beancount:
+		return writeLedger(w, f, orders, opts)
+	}
+	rows := Rows(orders, opts)
+	switch f {
+	case CSV:
+		return writeCSV(w, rows, opts.Items)
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		for _, r := range rows {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	default:
+		return fmt.Errorf("unknown format %q", f)
+	}
+}
+
+func writeCSV(w io.Writer, rows []Row, items bool) error {
+	cw := csv.NewWriter(w)
+	header := headerColumns
+	if items {
+		header = append(append([]string{}, headerColumns...), itemColumns...)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		rec := []string{
+			r.Provider,
+			r.OrderID,
+			r.Time,
+			r.Vendor,
+			r.Status,
+			strconv.FormatBool(r.Delivered),
+			amount(r.Total),
+			r.Currency,
+			strconv.Itoa(r.ItemCount),
+		}
+		if items {
+			qty := ""
+			if r.ItemQuantity != 0 {
+				qty = strconv.Itoa(r.ItemQuantity)
+			}
+			rec = append(rec, r.ItemName, qty, amount(r.ItemPrice))
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func amount(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// itemCount sums quantities; items without a quantity count once.
+func itemCount(items []provider.Item) int {
+	n := 0
+	for _, it := range items {
+		n += max(it.Quantity, 1)
+	}
+	return n
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func sampleOrders() []provider.Order {
+	return []provider.Order{
+		{
+			Provider: "foodora", ID: "A1", Vendor: "Pizza, Inc", Status: "delivered", Delivered: true,
+			Total: 21.5, Currency: "EUR", Time: time.Date(2025, 12, 20, 18, 30, 0, 0, time.UTC),
+			Items: []provider.Item{{Name: "Margherita", Quantity: 2, Price: 18}, {Name: "Cola", Price: 3.5}},
+		},
+		{Provider: "glovo", ID: "7", Vendor: "Burger", Total: 9},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, CSV, sampleOrders(), Options{}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	recs, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if len(recs) != 3 || strings.Join(recs[0], ",") != "provider,order_id,time,vendor,status,delivered,total,currency,item_count" {
+		t.Fatalf("recs=%v", recs)
+	}
+	if strings.Join(recs[1], "|") != "foodora|A1|2025-12-20T18:30:00Z|Pizza, Inc|delivered|true|21.50|EUR|3" {
+		t.Fatalf("row=%v", recs[1])
+	}
+	if recs[2][2] != "" || recs[2][8] != "0" {
+		t.Fatalf("row=%v", recs[2])
+	}
+}
+
+func TestWriteCSVItems(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, CSV, sampleOrders(), Options{Items: true}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	recs, _ := csv.NewReader(&buf).ReadAll()
+	if len(recs) != 4 || len(recs[0]) != 12 {
+		t.Fatalf("recs=%v", recs)
+	}
+	if recs[1][9] != "Margherita" || recs[1][10] != "2" || recs[1][11] != "18.00" {
+		t.Fatalf("row=%v", recs[1])
+	}
+	if recs[2][9] != "Cola" || recs[2][10] != "" || recs[3][9] != "" {
+		t.Fatalf("rows=%v", recs[2:])
+	}
+}
+
+func TestWriteNDJSONAndJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, NDJSON, sampleOrders(), Options{Items: true}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("lines=%q", lines)
+	}
+	var r Row
+	if err := json.Unmarshal([]byte(lines[0]), &r); err != nil || r.ItemName != "Margherita" || r.OrderID != "A1" {
+		t.Fatalf("r=%+v err=%v", r, err)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, JSON, sampleOrders(), Options{}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var rows []Row
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil || len(rows) != 2 || rows[1].Vendor != "Burger" {
+		t.Fatalf("rows=%+v err=%v", rows, err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"CSV": CSV, "ndjson": NDJSON, "jsonl": NDJSON, "json": JSON} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Fatalf("%q: got %q err=%v", in, got, err)
+		}
+	}
+	if _, err := ParseFormat("parquet"); err == nil {
+		t.Fatalf("expected error")
+	}
+	if err := Write(&bytes.Buffer{}, "xml", nil, Options{}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
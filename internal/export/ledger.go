@@ -0,0 +1,133 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// Plain-text accounting formats.
+const (
+	Ledger    Format = "ledger"
+	HLedger   Format = "hledger"
+	Beancount Format = "beancount"
+)
+
+const (
+	DefaultExpenseAccount = "Expenses:Food:Delivery"
+	DefaultFundingAccount = "Assets:Checking"
+)
+
+// accounts resolves the posting accounts of o from the configured rules.
+func accounts(cfg *config.AccountingConfig, o provider.Order) (expense, funding string) {
+	expense, funding = DefaultExpenseAccount, DefaultFundingAccount
+	if cfg == nil {
+		return expense, funding
+	}
+	if cfg.ExpenseAccount != "" {
+		expense = cfg.ExpenseAccount
+	}
+	if cfg.FundingAccount != "" {
+		funding = cfg.FundingAccount
+	}
+	vendor := strings.ToLower(o.Vendor)
+	for _, r := range cfg.Rules {
+		if r.Provider != "" && !strings.EqualFold(r.Provider, o.Provider) {
+			continue
+		}
+		if r.Vendor != "" && !strings.Contains(vendor, strings.ToLower(r.Vendor)) {
+			continue
+		}
+		if r.ExpenseAccount != "" {
+			expense = r.ExpenseAccount
+		}
+		if r.FundingAccount != "" {
+			funding = r.FundingAccount
+		}
+		break
+	}
+	return expense, funding
+}
+
+// skipReason says why o cannot become a transaction, or "" if it can.
+func skipReason(o provider.Order) string {
+	switch {
+	case o.Cancelled():
+		return "cancelled"
+	case o.Time.IsZero():
+		return "no delivery date"
+	case o.Total == 0:
+		return "no total"
+	case o.Currency == "":
+		return "unknown currency"
+	}
+	return ""
+}
+
+// writeLedger renders one transaction per order, oldest first as journals
+// expect. Orders that cannot be booked are passed to opts.Skipped.
+func writeLedger(w io.Writer, f Format, orders []provider.Order, opts Options) error {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	for i := len(orders) - 1; i >= 0; i-- {
+		o := orders[i]
+		if reason := skipReason(o); reason != "" {
+			if opts.Skipped != nil {
+				opts.Skipped(o, reason)
+			}
+			continue
+		}
+		expense, funding := accounts(opts.Accounting, o)
+		amount := strconv.FormatFloat(o.Total, 'f', 2, 64) + " " + o.Currency
+		payee := oneLine(o.Vendor)
+		if payee == "" {
+			payee = o.Provider
+		}
+		date := o.Time.In(loc)
+
+		var err error
+		if f == Beancount {
+			_, err = fmt.Fprintf(w, "%s * %s %s\n  provider: %s\n  order_id: %s\n  %s  %s\n  %s\n\n",
+				date.Format(time.DateOnly),
+				beanString(payee),
+				beanString(o.Provider+" order "+o.ID),
+				beanString(o.Provider),
+				beanString(o.ID),
+				expense, amount,
+				funding,
+			)
+		} else {
+			layout := time.DateOnly
+			if f == Ledger {
+				layout = "2006/01/02"
+			}
+			_, err = fmt.Fprintf(w, "%s * %s\n    ; provider: %s\n    ; order_id: %s\n    %s  %s\n    %s\n\n",
+				date.Format(layout),
+				payee,
+				o.Provider,
+				oneLine(o.ID),
+				expense, amount,
+				funding,
+			)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func beanString(s string) string {
+	return strconv.Quote(oneLine(s))
+}
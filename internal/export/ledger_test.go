@@ -0,0 +1,90 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func journalOrders() []provider.Order {
+	return []provider.Order{
+		{Provider: "glovo", ID: "G1", Vendor: "Burger  \"King\"", Total: 9, Currency: "EUR", Time: time.Date(2025, 12, 21, 19, 0, 0, 0, time.UTC)},
+		{Provider: "foodora", ID: "F1", Vendor: "Pizza", Total: 12.3, Currency: "EUR", Time: time.Date(2025, 12, 20, 18, 0, 0, 0, time.UTC)},
+		{Provider: "foodora", ID: "F0", Vendor: "Sushi", Total: 30, Currency: "EUR", Status: "Cancelled", Time: time.Date(2025, 12, 19, 18, 0, 0, 0, time.UTC)},
+		{Provider: "deliveroo", ID: "D1", Vendor: "Thai", Total: 15, Time: time.Date(2025, 12, 18, 18, 0, 0, 0, time.UTC)},
+		{Provider: "foodora", ID: "F9", Vendor: "Tacos", Total: 11, Currency: "EUR", Status: "Refunded", Time: time.Date(2025, 12, 17, 18, 0, 0, 0, time.UTC)},
+	}
+}
+
+func TestWriteLedgerAndHLedger(t *testing.T) {
+	var skipped []string
+	opts := Options{
+		Location: time.UTC,
+		Accounting: &config.AccountingConfig{
+			FundingAccount: "Liabilities:Visa",
+			Rules: []config.AccountRule{
+				{Vendor: "burger", ExpenseAccount: "Expenses:Food:Junk"},
+				{Provider: "foodora", ExpenseAccount: "Expenses:Food:Foodora", FundingAccount: "Assets:PayPal"},
+			},
+		},
+		Skipped: func(o provider.Order, reason string) { skipped = append(skipped, o.ID+":"+reason) },
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, Ledger, journalOrders(), opts); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := "2025/12/20 * Pizza\n" +
+		"    ; provider: foodora\n" +
+		"    ; order_id: F1\n" +
+		"    Expenses:Food:Foodora  12.30 EUR\n" +
+		"    Assets:PayPal\n\n" +
+		"2025/12/21 * Burger \"King\"\n" +
+		"    ; provider: glovo\n" +
+		"    ; order_id: G1\n" +
+		"    Expenses:Food:Junk  9.00 EUR\n" +
+		"    Liabilities:Visa\n\n"
+	if buf.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	if len(skipped) != 3 || skipped[0] != "F9:cancelled" || skipped[1] != "D1:unknown currency" || skipped[2] != "F0:cancelled" {
+		t.Fatalf("skipped=%v", skipped)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, HLedger, journalOrders()[:1], Options{Location: time.UTC}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := buf.String(); got[:10] != "2025-12-21" || !bytes.Contains(buf.Bytes(), []byte("    Expenses:Food:Delivery  9.00 EUR\n    Assets:Checking\n")) {
+		t.Fatalf("got=%q", got)
+	}
+}
+
+func TestWriteBeancount(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Beancount, journalOrders()[:1], Options{Location: time.UTC}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := "2025-12-21 * \"Burger \\\"King\\\"\" \"glovo order G1\"\n" +
+		"  provider: \"glovo\"\n" +
+		"  order_id: \"G1\"\n" +
+		"  Expenses:Food:Delivery  9.00 EUR\n" +
+		"  Assets:Checking\n\n"
+	if buf.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestAccountsDefaults(t *testing.T) {
+	e, f := accounts(nil, provider.Order{})
+	if e != DefaultExpenseAccount || f != DefaultFundingAccount {
+		t.Fatalf("e=%s f=%s", e, f)
+	}
+	e, f = accounts(&config.AccountingConfig{ExpenseAccount: "Expenses:Takeout"}, provider.Order{Provider: "glovo"})
+	if e != "Expenses:Takeout" || f != DefaultFundingAccount {
+		t.Fatalf("e=%s f=%s", e, f)
+	}
+}
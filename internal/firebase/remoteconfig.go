@@ -11,6 +11,8 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/steipete/ordercli/internal/transport"
 )
 
 type APKFirebaseConfig struct {
@@ -58,6 +60,13 @@ func NewRemoteConfigClient(cfg APKFirebaseConfig) *RemoteConfigClient {
 	}
 }
 
+// SetHTTPClient replaces the default client (20s timeout).
+func (c *RemoteConfigClient) SetHTTPClient(hc *http.Client) {
+	if hc != nil {
+		c.http = hc
+	}
+}
+
 type Installation struct {
 	FID       string
 	AuthToken string
@@ -164,6 +173,8 @@ func (c *RemoteConfigClient) fetchRemoteConfig(ctx context.Context, inst Install
 	req.Header.Set("X-Goog-Api-Key", c.cfg.APIKey)
 	req.Header.Set("X-Goog-Firebase-Installations-Id", inst.FID)
 	req.Header.Set("X-Goog-Firebase-Installations-Auth", inst.AuthToken)
+	// Fetching is read-only despite the POST; allow the shared transport to retry it.
+	transport.MarkIdempotent(req)
 
 	res, err := c.http.Do(req)
 	if err != nil {
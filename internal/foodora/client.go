@@ -12,6 +12,10 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/steipete/ordercli/internal/apierr"
+	"github.com/steipete/ordercli/internal/schema"
+	"github.com/steipete/ordercli/internal/transport"
 )
 
 type Client struct {
@@ -25,6 +29,9 @@ type Client struct {
 	fpAPIKey       string
 	appName        string
 	originalUA     string
+	extraHeaders   map[string]string
+	schema         *schema.Recorder
+	maxBody        int64
 
 	accessToken string
 	userAgent   string
@@ -41,6 +48,15 @@ type Options struct {
 	FPAPIKey          string
 	AppName           string
 	OriginalUserAgent string
+	// Headers are set on every request, after (and overriding) the ones above.
+	Headers map[string]string
+	// HTTPClient overrides the default client (20s timeout).
+	HTTPClient *http.Client
+	// Schema records how responses drift from the models (nil: not kept).
+	Schema *schema.Recorder
+	// MaxBodySize caps a response body (default transport.DefaultMaxBody);
+	// longer ones fail with a *transport.BodyTooLargeError.
+	MaxBodySize int64
 }
 
 func New(opts Options) (*Client, error) {
@@ -60,11 +76,18 @@ func New(opts Options) (*Client, error) {
 		ua = "ordercli"
 	}
 
+	hc := opts.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: 20 * time.Second}
+	}
+	maxBody := opts.MaxBodySize
+	if maxBody <= 0 {
+		maxBody = transport.DefaultMaxBody
+	}
+
 	return &Client{
-		baseURL: u,
-		http: &http.Client{
-			Timeout: 20 * time.Second,
-		},
+		baseURL:        u,
+		http:           hc,
 		deviceID:       opts.DeviceID,
 		globalEntityID: opts.GlobalEntityID,
 		targetISO:      opts.TargetCountryISO,
@@ -72,6 +95,9 @@ func New(opts Options) (*Client, error) {
 		fpAPIKey:       opts.FPAPIKey,
 		appName:        opts.AppName,
 		originalUA:     opts.OriginalUserAgent,
+		extraHeaders:   opts.Headers,
+		schema:         opts.Schema,
+		maxBody:        maxBody,
 		accessToken:    opts.AccessToken,
 		userAgent:      ua,
 	}, nil
@@ -140,6 +166,22 @@ func (c *Client) OrderStatus(ctx context.Context, orderCode string) (OrderStatus
 
 func (c *Client) OrderHistory(ctx context.Context, req OrderHistoryRequest) (OrderHistoryResponse, error) {
 	var out OrderHistoryResponse
+	if err := c.getJSON(ctx, "orders/order_history", orderHistoryQuery(req), &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// OrderHistoryRaw is OrderHistory without the typed decode; items keep every field the API returned.
+func (c *Client) OrderHistoryRaw(ctx context.Context, req OrderHistoryRequest) (OrderHistoryRawResponse, error) {
+	var out OrderHistoryRawResponse
+	if err := c.getJSON(ctx, "orders/order_history", orderHistoryQuery(req), &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+func orderHistoryQuery(req OrderHistoryRequest) url.Values {
 	q := url.Values{}
 	include := req.Include
 	if include == "" {
@@ -153,10 +195,7 @@ func (c *Client) OrderHistory(ctx context.Context, req OrderHistoryRequest) (Ord
 	}
 	q.Set("limit", strconv.Itoa(limit))
 	q.Set("pandago_enabled", strconv.FormatBool(req.PandaGoEnabled))
-	if err := c.getJSON(ctx, "orders/order_history", q, &out); err != nil {
-		return out, err
-	}
-	return out, nil
+	return q
 }
 
 func (c *Client) OrderHistoryByCode(ctx context.Context, req OrderHistoryByCodeRequest) (OrderHistoryRawResponse, error) {
@@ -233,6 +272,9 @@ func (c *Client) oauthToken(ctx context.Context, form url.Values, h oauthHeaders
 	if c.appName != "" {
 		req.Header.Set("App-Name", c.appName)
 	}
+	for k, v := range c.extraHeaders {
+		req.Header.Set(k, v)
+	}
 	req.Header.Set("X-OTP-Method", h.otpMethod)
 	if h.otpCode != "" {
 		req.Header.Set("X-OTP", h.otpCode)
@@ -268,12 +310,12 @@ func (c *Client) oauthToken(ctx context.Context, form url.Values, h oauthHeaders
 		return AuthToken{}, &ch, nil
 	}
 
-	return AuthToken{}, nil, &HTTPError{
+	return AuthToken{}, nil, apierr.Wrap("foodora", res, body, &HTTPError{
 		Method:     req.Method,
 		URL:        req.URL.String(),
 		StatusCode: res.StatusCode,
 		Body:       body,
-	}
+	})
 }
 
 func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out any) error {
@@ -306,6 +348,9 @@ func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out
 	if c.appName != "" {
 		req.Header.Set("App-Name", c.appName)
 	}
+	for k, v := range c.extraHeaders {
+		req.Header.Set(k, v)
+	}
 	if c.globalEntityID != "" {
 		req.Header.Set("X-Global-Entity-ID", c.globalEntityID)
 	}
@@ -316,36 +361,7 @@ func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out
 		req.Header.Set("Authorization", "Bearer "+c.accessToken)
 	}
 
-	res, err := c.http.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-
-	body, err := io.ReadAll(io.LimitReader(res.Body, 4<<20))
-	if err != nil {
-		return err
-	}
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return &HTTPError{
-			Method:     req.Method,
-			URL:        req.URL.String(),
-			StatusCode: res.StatusCode,
-			Body:       body,
-		}
-	}
-
-	dec := json.NewDecoder(bytes.NewReader(body))
-	dec.DisallowUnknownFields()
-	if err := dec.Decode(out); err == nil {
-		return nil
-	}
-
-	// Fallback: tolerate API drift by decoding as lenient JSON.
-	if err := json.Unmarshal(body, out); err != nil {
-		return fmt.Errorf("%s: decode JSON: %w", path, err)
-	}
-	return nil
+	return c.doJSON(req, path, out)
 }
 
 func (c *Client) postJSON(ctx context.Context, path string, query url.Values, in any, out any) error {
@@ -384,6 +400,9 @@ func (c *Client) postJSON(ctx context.Context, path string, query url.Values, in
 	if c.appName != "" {
 		req.Header.Set("App-Name", c.appName)
 	}
+	for k, v := range c.extraHeaders {
+		req.Header.Set(k, v)
+	}
 	if c.globalEntityID != "" {
 		req.Header.Set("X-Global-Entity-ID", c.globalEntityID)
 	}
@@ -394,33 +413,41 @@ func (c *Client) postJSON(ctx context.Context, path string, query url.Values, in
 		req.Header.Set("Authorization", "Bearer "+c.accessToken)
 	}
 
+	return c.doJSON(req, path, out)
+}
+
+// doJSON sends req and decodes a successful response into out in one pass.
+// Fields the models lack are recorded as schema drift instead of failing
+// the decode.
+func (c *Client) doJSON(req *http.Request, path string, out any) error {
 	res, err := c.http.Do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	body, err := io.ReadAll(io.LimitReader(res.Body, 4<<20))
-	if err != nil {
-		return err
-	}
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return &HTTPError{
+		// Error bodies only feed messages and classification; a cut-off one
+		// is fine.
+		body, err := io.ReadAll(io.LimitReader(res.Body, 4<<20))
+		if err != nil {
+			return err
+		}
+		return apierr.Wrap("foodora", res, body, &HTTPError{
 			Method:     req.Method,
 			URL:        req.URL.String(),
 			StatusCode: res.StatusCode,
 			Body:       body,
-		}
+		})
 	}
 
-	dec := json.NewDecoder(bytes.NewReader(body))
-	dec.DisallowUnknownFields()
-	if err := dec.Decode(out); err == nil {
-		return nil
+	drift, err := schema.Decode(transport.LimitBody(res.Body, c.maxBody), out)
+	c.schema.Record("foodora", req.Method, path, out, drift)
+	var tooLarge *transport.BodyTooLargeError
+	if errors.As(err, &tooLarge) {
+		return fmt.Errorf("%s: %w", path, err)
 	}
-
-	// Fallback: tolerate API drift by decoding as lenient JSON.
-	if err := json.Unmarshal(body, out); err != nil {
+	if err != nil {
 		return fmt.Errorf("%s: decode JSON: %w", path, err)
 	}
 	return nil
@@ -2,10 +2,16 @@ package foodora
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/steipete/ordercli/internal/schema"
+	"github.com/steipete/ordercli/internal/transport"
 )
 
 func TestClient_ActiveOrders_DecodeFallback(t *testing.T) {
@@ -18,7 +24,7 @@ func TestClient_ActiveOrders_DecodeFallback(t *testing.T) {
 		if r.Header.Get("Authorization") != "Bearer tok" {
 			t.Fatalf("auth=%q", r.Header.Get("Authorization"))
 		}
-		// includes unknown field to trigger DisallowUnknownFields decode failure and fallback
+		// unknown fields are skipped (and reported as schema drift), not fatal
 		w.Header().Set("Content-Type", "application/json")
 		_, _ = w.Write([]byte(`{
   "status": 200,
@@ -42,6 +48,55 @@ func TestClient_ActiveOrders_DecodeFallback(t *testing.T) {
 	}
 }
 
+func TestClient_RecordsUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":200,"data":{"count":0,"poll_in_sec":30,"active_orders":[],"eta_hint":"soon"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "drift.json")
+	c, err := New(Options{BaseURL: srv.URL + "/", AccessToken: "tok", UserAgent: "ua", Schema: schema.NewRecorder(path, nil)})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := c.ActiveOrders(context.Background()); err != nil {
+		t.Fatalf("ActiveOrders: %v", err)
+	}
+	rep, err := schema.Load(path)
+	if err != nil || len(rep.Endpoints) != 1 {
+		t.Fatalf("report=%+v err=%v", rep, err)
+	}
+	if f := rep.Endpoints[0].Findings; len(f) != 1 || f[0].Kind != schema.Unknown || f[0].Path != "data.eta_hint" {
+		t.Fatalf("findings=%+v", f)
+	}
+}
+
+func TestClient_OversizedBody(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":200,"data":{"count":0,"active_orders":[],"pad":"` + strings.Repeat("x", 4096) + `"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := New(Options{BaseURL: srv.URL + "/", AccessToken: "tok", UserAgent: "ua", MaxBodySize: 1024})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_, err = c.ActiveOrders(context.Background())
+	var tooLarge *transport.BodyTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 1024 {
+		t.Fatalf("want BodyTooLargeError, got %v", err)
+	}
+	if got := err.Error(); got != "tracking/active-orders: response body larger than 1024 bytes" {
+		t.Fatalf("error=%q", got)
+	}
+}
+
 func TestClient_OrderHistoryByCode_Validation(t *testing.T) {
 	t.Parallel()
 
@@ -96,7 +151,7 @@ func TestClient_OrderReorder_PostJSONFallback(t *testing.T) {
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		// unknown field triggers strict decode failure
+		// unknown field is skipped, not fatal
 		_, _ = w.Write([]byte(`{
   "status": 200,
   "unknown": 1,
@@ -2,11 +2,15 @@ package foodora
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+
+	"github.com/steipete/ordercli/internal/apierr"
 )
 
 func TestClient_OAuthTokenPassword_Success(t *testing.T) {
@@ -110,6 +114,33 @@ func TestClient_OAuthTokenPassword_MfaTriggered(t *testing.T) {
 	}
 }
 
+func TestClient_OAuthTokenPassword_WrongPasswordWithCookies(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Cookie") != "cf_clearance=c" {
+			t.Errorf("cookie=%q", r.Header.Get("Cookie"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(401)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"wrong credentials"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := New(Options{BaseURL: srv.URL + "/", UserAgent: "ua", CookieHeader: "cf_clearance=c"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_, _, err = c.OAuthTokenPassword(context.Background(), OAuthPasswordRequest{Username: "u", Password: "wrong", ClientSecret: "s"})
+	var apiErr *apierr.Error
+	if err == nil || errors.As(err, &apiErr) {
+		t.Fatalf("want a plain login error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "session expired") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
 func TestClient_OAuthTokenRefresh_SetsClientIDDefault(t *testing.T) {
 	t.Parallel()
 
@@ -66,8 +66,8 @@ type StatusTitle struct {
 }
 
 type OrderStatusResponse struct {
-	Status int            `json:"status"`
-	Data   map[string]any `json:"data"`
+	Status int           `json:"status"`
+	Data   OrderTracking `json:"data"`
 }
 
 type OrderHistoryRequest struct {
@@ -93,6 +93,13 @@ type OrderHistoryItem struct {
 	ConfirmedDeliveryTime *OrderHistoryTime   `json:"confirmed_delivery_time"`
 	Vendor                *OrderHistoryVendor `json:"vendor"`
 	TotalValue            float64             `json:"total_value"`
+	Products              []OrderProduct      `json:"order_products,omitempty"`
+}
+
+type OrderProduct struct {
+	Name       string      `json:"name"`
+	Quantity   FlexibleInt `json:"quantity"`
+	TotalPrice float64     `json:"total_price"`
 }
 
 type OrderHistoryVendor struct {
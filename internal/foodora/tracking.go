@@ -0,0 +1,153 @@
+package foodora
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OrderTracking is the data of tracking/orders/{orderCode}: the live view of
+// one order (status steps, ETA, rider, vendor, drop-off address).
+type OrderTracking struct {
+	Code          string              `json:"order_code"`
+	IsDelivered   bool                `json:"is_delivered"`
+	Status        StatusMessages      `json:"status_messages"`
+	CurrentStatus *OrderHistoryStatus `json:"current_status,omitempty"`
+	// Timeline is the status history, oldest first.
+	Timeline []TrackingEvent `json:"status_history,omitempty"`
+	ETA      *TrackingETA    `json:"eta,omitempty"`
+	Rider    *TrackingRider  `json:"rider,omitempty"`
+	Vendor   *TrackingVendor `json:"vendor,omitempty"`
+	Address  *TrackingPlace  `json:"delivery_address,omitempty"`
+
+	// Raw is the tracking data exactly as the API returned it, including
+	// fields the struct does not model.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the tracking data and keeps a copy of the raw payload.
+func (t *OrderTracking) UnmarshalJSON(b []byte) error {
+	type plain OrderTracking
+	if err := json.Unmarshal(b, (*plain)(t)); err != nil {
+		return err
+	}
+	t.Raw = append(json.RawMessage(nil), b...)
+	return nil
+}
+
+type TrackingEvent struct {
+	Code    FlexibleString `json:"code,omitempty"`
+	Message string         `json:"message,omitempty"`
+	Time    FlexibleTime   `json:"timestamp,omitzero"`
+}
+
+// TrackingETA is the promised delivery window; To is zero for a point ETA.
+type TrackingETA struct {
+	From FlexibleTime `json:"from,omitzero"`
+	To   FlexibleTime `json:"to,omitzero"`
+}
+
+type TrackingRider struct {
+	Name     string    `json:"name,omitempty"`
+	Phone    string    `json:"phone,omitempty"`
+	Vehicle  string    `json:"vehicle_type,omitempty"`
+	Location *GeoPoint `json:"location,omitempty"`
+}
+
+type TrackingVendor struct {
+	Code     string    `json:"code,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	Phone    string    `json:"phone,omitempty"`
+	Address  string    `json:"address,omitempty"`
+	Location *GeoPoint `json:"location,omitempty"`
+}
+
+type TrackingPlace struct {
+	Label        string    `json:"label,omitempty"`
+	Formatted    string    `json:"formatted_address,omitempty"`
+	Street       string    `json:"street,omitempty"`
+	Building     string    `json:"building,omitempty"`
+	Postcode     string    `json:"postcode,omitempty"`
+	City         string    `json:"city,omitempty"`
+	Instructions string    `json:"delivery_instructions,omitempty"`
+	Location     *GeoPoint `json:"location,omitempty"`
+}
+
+type GeoPoint struct {
+	Latitude  FlexibleFloat `json:"latitude"`
+	Longitude FlexibleFloat `json:"longitude"`
+}
+
+func (p *GeoPoint) String() string {
+	if p == nil || (p.Latitude == 0 && p.Longitude == 0) {
+		return ""
+	}
+	return strconv.FormatFloat(float64(p.Latitude), 'f', -1, 64) + "," + strconv.FormatFloat(float64(p.Longitude), 'f', -1, 64)
+}
+
+// Text is the status line the apps show: the subtitle, else the active step.
+func (m StatusMessages) Text() string {
+	if m.Subtitle != "" {
+		return m.Subtitle
+	}
+	for _, t := range m.Titles {
+		if t.Active {
+			return t.Name
+		}
+	}
+	if len(m.Titles) > 0 {
+		return m.Titles[0].Name
+	}
+	return ""
+}
+
+// StatusText is the best available status line of a tracked order.
+func (t OrderTracking) StatusText() string {
+	if s := t.Status.Text(); s != "" {
+		return s
+	}
+	if t.CurrentStatus != nil {
+		if t.CurrentStatus.Message != "" {
+			return t.CurrentStatus.Message
+		}
+		return string(t.CurrentStatus.Code)
+	}
+	if n := len(t.Timeline); n > 0 {
+		return t.Timeline[n-1].Message
+	}
+	return ""
+}
+
+// Line is a one-line address: the formatted address, else its parts.
+func (p *TrackingPlace) Line() string {
+	if p == nil {
+		return ""
+	}
+	if p.Formatted != "" {
+		return p.Formatted
+	}
+	street := strings.TrimSpace(p.Street + " " + p.Building)
+	city := strings.TrimSpace(p.Postcode + " " + p.City)
+	switch {
+	case street != "" && city != "":
+		return street + ", " + city
+	case street != "":
+		return street
+	}
+	return city
+}
+
+// Remaining is how long until the window opens and closes, clamped at zero.
+func (e *TrackingETA) Remaining(now time.Time) (from, to time.Duration) {
+	if e == nil {
+		return 0, 0
+	}
+	if !e.From.IsZero() {
+		from = max(e.From.Sub(now), 0)
+	}
+	if !e.To.IsZero() {
+		to = max(e.To.Sub(now), 0)
+	}
+	return from, to
+}
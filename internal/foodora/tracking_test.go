@@ -0,0 +1,84 @@
+package foodora
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+const trackingJSON = `{"status":200,"data":{
+	"order_code":"OC-1","is_delivered":false,
+	"status_messages":{"subtitle":"","titles":[{"name":"Received","is_filled":true},{"name":"Cooking","active":true},{"name":"On the way"}]},
+	"status_history":[{"code":1,"message":"Received","timestamp":"2025-12-20T18:02:00Z"},{"code":"2","message":"Cooking","timestamp":1766253900}],
+	"eta":{"from":"2025-12-20T18:40:00Z","to":"2025-12-20T18:55:00Z"},
+	"rider":{"name":"Max","vehicle_type":"bicycle","location":{"latitude":"48.2","longitude":16.37}},
+	"vendor":{"code":"V","name":"Vendor","address":"Ring 1","location":{"latitude":48.21,"longitude":16.36}},
+	"delivery_address":{"street":"Main","building":"1","postcode":"1010","city":"Vienna","delivery_instructions":"ring twice"}
+}}`
+
+func TestOrderTracking_Decode(t *testing.T) {
+	t.Parallel()
+
+	var resp OrderStatusResponse
+	if err := json.Unmarshal([]byte(trackingJSON), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	tr := resp.Data
+	if tr.Code != "OC-1" || tr.StatusText() != "Cooking" || len(tr.Timeline) != 2 || tr.Timeline[0].Code != "1" {
+		t.Fatalf("tracking=%+v", tr)
+	}
+	if got := tr.Timeline[1].Time.UTC(); !got.Equal(time.Date(2025, 12, 20, 18, 5, 0, 0, time.UTC)) {
+		t.Fatalf("timeline time=%v", got)
+	}
+	if tr.Rider.Location.String() != "48.2,16.37" || tr.Vendor.Location.String() != "48.21,16.36" {
+		t.Fatalf("locations rider=%v vendor=%v", tr.Rider.Location, tr.Vendor.Location)
+	}
+	if tr.Address.Line() != "Main 1, 1010 Vienna" {
+		t.Fatalf("address=%q", tr.Address.Line())
+	}
+
+	from, to := tr.ETA.Remaining(time.Date(2025, 12, 20, 18, 45, 0, 0, time.UTC))
+	if from != 0 || to != 10*time.Minute {
+		t.Fatalf("from=%v to=%v", from, to)
+	}
+}
+
+func TestOrderTracking_StatusFallbacks(t *testing.T) {
+	t.Parallel()
+
+	tr := OrderTracking{CurrentStatus: &OrderHistoryStatus{Code: "DELIVERED"}}
+	if tr.StatusText() != "DELIVERED" {
+		t.Fatalf("status=%q", tr.StatusText())
+	}
+	tr = OrderTracking{Timeline: []TrackingEvent{{Message: "a"}, {Message: "b"}}}
+	if tr.StatusText() != "b" {
+		t.Fatalf("status=%q", tr.StatusText())
+	}
+	if (StatusMessages{Titles: []StatusTitle{{Name: "x"}}}).Text() != "x" {
+		t.Fatalf("first title fallback")
+	}
+	var p *TrackingPlace
+	if p.Line() != "" || (&TrackingPlace{City: "Wien"}).Line() != "Wien" || (&TrackingPlace{Formatted: "F"}).Line() != "F" {
+		t.Fatalf("place lines")
+	}
+	var g *GeoPoint
+	if g.String() != "" {
+		t.Fatalf("nil point")
+	}
+}
+
+func TestFlexibleFloat(t *testing.T) {
+	t.Parallel()
+
+	var v struct {
+		F FlexibleFloat `json:"f"`
+	}
+	for in, want := range map[string]float64{`{"f":1.5}`: 1.5, `{"f":" 2.25 "}`: 2.25, `{"f":""}`: 0, `{"f":null}`: 0} {
+		if err := json.Unmarshal([]byte(in), &v); err != nil || float64(v.F) != want {
+			t.Fatalf("%s: got %v err=%v", in, v.F, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"f":"north"}`), &v); err == nil {
+		t.Fatalf("expected error")
+	}
+}
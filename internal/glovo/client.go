@@ -3,7 +3,6 @@ package glovo
 import (
 	"context"
 	"crypto/rand"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +11,10 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/steipete/ordercli/internal/apierr"
+	"github.com/steipete/ordercli/internal/schema"
+	"github.com/steipete/ordercli/internal/transport"
 )
 
 // Client is a Glovo API client
@@ -26,6 +29,11 @@ type Client struct {
 	sessionID    string
 	latitude     float64
 	longitude    float64
+	appVersion   string
+	clientInfo   string
+	headers      map[string]string
+	schema       *schema.Recorder
+	maxBody      int64
 }
 
 // Options configures a new Glovo client
@@ -38,8 +46,27 @@ type Options struct {
 	Language    string
 	Latitude    float64
 	Longitude   float64
+	// AppVersion and ClientInfo identify the web app build (default
+	// DefaultAppVersion and DefaultClientInfo).
+	AppVersion string
+	ClientInfo string
+	// Headers are set on every request, after (and overriding) the built-in ones.
+	Headers map[string]string
+	// HTTPClient overrides the default client (20s timeout).
+	HTTPClient *http.Client
+	// Schema records how responses drift from the models (nil: not kept).
+	Schema *schema.Recorder
+	// MaxBodySize caps a response body (default transport.DefaultMaxBody);
+	// longer ones fail with a *transport.BodyTooLargeError.
+	MaxBodySize int64
 }
 
+// The web app build the built-in headers claim to be.
+const (
+	DefaultAppVersion = "v1.1782.0"
+	DefaultClientInfo = "web-customer-web-react/v1.1782.0 project:customer-web"
+)
+
 // New creates a new Glovo API client
 func New(opts Options) (*Client, error) {
 	if opts.AccessToken == "" {
@@ -71,9 +98,27 @@ func New(opts Options) (*Client, error) {
 		lang = "en"
 	}
 
+	hc := opts.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: 20 * time.Second}
+	}
+
+	maxBody := opts.MaxBodySize
+	if maxBody <= 0 {
+		maxBody = transport.DefaultMaxBody
+	}
+
+	appVersion, clientInfo := opts.AppVersion, opts.ClientInfo
+	if appVersion == "" {
+		appVersion = DefaultAppVersion
+	}
+	if clientInfo == "" {
+		clientInfo = DefaultClientInfo
+	}
+
 	return &Client{
 		baseURL:      u,
-		http:         &http.Client{Timeout: 20 * time.Second},
+		http:         hc,
 		accessToken:  opts.AccessToken,
 		deviceURN:    deviceURN,
 		cityCode:     opts.CityCode,
@@ -82,6 +127,11 @@ func New(opts Options) (*Client, error) {
 		sessionID:    sessionID,
 		latitude:     opts.Latitude,
 		longitude:    opts.Longitude,
+		appVersion:   appVersion,
+		clientInfo:   clientInfo,
+		headers:      opts.Headers,
+		schema:       opts.Schema,
+		maxBody:      maxBody,
 	}, nil
 }
 
@@ -96,8 +146,8 @@ func (c *Client) setHeaders(req *http.Request) {
 	req.Header.Set("glovo-app-development-state", "prod")
 	req.Header.Set("glovo-app-platform", "web")
 	req.Header.Set("glovo-app-type", "customer")
-	req.Header.Set("glovo-app-version", "v1.1782.0")
-	req.Header.Set("glovo-client-info", "web-customer-web-react/v1.1782.0 project:customer-web")
+	req.Header.Set("glovo-app-version", c.appVersion)
+	req.Header.Set("glovo-client-info", c.clientInfo)
 
 	// Location headers
 	if c.latitude != 0 {
@@ -132,6 +182,10 @@ func (c *Client) setHeaders(req *http.Request) {
 	// Request tracking
 	req.Header.Set("glovo-request-id", newUUID())
 	req.Header.Set("glovo-request-ttl", "7500")
+
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
 }
 
 // newUUID generates a UUIDv4-ish string
@@ -175,21 +229,26 @@ func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
-	if err != nil {
-		return err
-	}
-
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return &HTTPError{
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+		if err != nil {
+			return err
+		}
+		return apierr.Wrap("glovo", resp, body, &HTTPError{
 			Method:     req.Method,
 			URL:        req.URL.String(),
 			StatusCode: resp.StatusCode,
 			Body:       body,
-		}
+		})
 	}
 
-	if err := json.Unmarshal(body, out); err != nil {
+	drift, err := schema.Decode(transport.LimitBody(resp.Body, c.maxBody), out)
+	c.schema.Record("glovo", req.Method, path, out, drift)
+	var tooLarge *transport.BodyTooLargeError
+	if errors.As(err, &tooLarge) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err != nil {
 		return fmt.Errorf("%s: decode JSON: %w", path, err)
 	}
 	return nil
@@ -1,5 +1,7 @@
 package glovo
 
+import "encoding/json"
+
 // OrdersResponse represents the response from /v3/customer/orders-list
 type OrdersResponse struct {
 	Pagination Pagination `json:"pagination"`
@@ -24,6 +26,19 @@ type Order struct {
 	LayoutType                string  `json:"layoutType"`
 	IsNewOrderTrackingEnabled bool    `json:"isNewOrderTrackingEnabled"`
 	CourierName               *string `json:"courierName"`
+
+	// Raw is the order exactly as the API returned it.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the order and keeps a copy of the raw payload.
+func (o *Order) UnmarshalJSON(b []byte) error {
+	type plain Order
+	if err := json.Unmarshal(b, (*plain)(o)); err != nil {
+		return err
+	}
+	o.Raw = append(json.RawMessage(nil), b...)
+	return nil
 }
 
 // Image holds light/dark mode image IDs
@@ -0,0 +1,332 @@
+// Package homeassistant turns active-order snapshots into MQTT messages for
+// Home Assistant: discovery configs that create one device per order (status,
+// vendor, provider, and for providers that report one, ETA and
+// minutes-to-arrival sensors), retained JSON state
+// topics, and a summary device whose "next delivery in" sensor is stable
+// enough to drive automations.
+package homeassistant
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/mqtt"
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
+	"github.com/steipete/ordercli/internal/version"
+)
+
+const (
+	DefaultTopicPrefix     = "ordercli"
+	DefaultDiscoveryPrefix = "homeassistant"
+)
+
+// Publisher is what the bridge sends through; *mqtt.Client implements it.
+type Publisher interface {
+	Publish(mqtt.Message) error
+}
+
+type Options struct {
+	// TopicPrefix roots the state topics (default DefaultTopicPrefix).
+	TopicPrefix string
+	// DiscoveryPrefix is Home Assistant's discovery prefix (default DefaultDiscoveryPrefix).
+	DiscoveryPrefix string
+	// HasETA reports whether a provider's orders carry an ETA; the ETA
+	// sensors are left out for those that never do. Nil means all do.
+	HasETA func(provider string) bool
+	// Now is swapped in tests.
+	Now func() time.Time
+}
+
+// OrderState is the retained JSON payload of an order's state topic.
+type OrderState struct {
+	Provider  string    `json:"provider"`
+	OrderID   string    `json:"order_id"`
+	Vendor    string    `json:"vendor,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Delivered bool      `json:"delivered"`
+	ETA       time.Time `json:"eta,omitzero"`
+	// ETAMinutes is the whole minutes until ETA (0 once due); null without ETA.
+	ETAMinutes *int `json:"eta_minutes"`
+}
+
+// Summary is the retained JSON payload of the summary state topic.
+type Summary struct {
+	Active       int       `json:"active"`
+	NextProvider string    `json:"next_provider,omitempty"`
+	NextVendor   string    `json:"next_vendor,omitempty"`
+	NextETA      time.Time `json:"next_eta,omitzero"`
+	// NextETAMinutes is null when no active order has an ETA.
+	NextETAMinutes *int `json:"next_eta_minutes"`
+}
+
+// Bridge keeps track of which orders have entities in Home Assistant so
+// finished orders can be removed again. It is not safe for concurrent use;
+// poller.Run serializes its callback.
+type Bridge struct {
+	pub  Publisher
+	opts Options
+
+	known  map[string]OrderState // by objectID
+	orders map[string][]provider.Order
+}
+
+func New(pub Publisher, opts Options) *Bridge {
+	if opts.TopicPrefix == "" {
+		opts.TopicPrefix = DefaultTopicPrefix
+	}
+	if opts.DiscoveryPrefix == "" {
+		opts.DiscoveryPrefix = DefaultDiscoveryPrefix
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	opts.TopicPrefix = strings.TrimRight(opts.TopicPrefix, "/")
+	opts.DiscoveryPrefix = strings.TrimRight(opts.DiscoveryPrefix, "/")
+	return &Bridge{pub: pub, opts: opts, known: map[string]OrderState{}, orders: map[string][]provider.Order{}}
+}
+
+// AvailabilityTopic carries "online"/"offline"; use it as the MQTT will.
+func (b *Bridge) AvailabilityTopic() string { return b.opts.TopicPrefix + "/status" }
+
+// Will is the last-will message marking every entity unavailable.
+func (b *Bridge) Will() *mqtt.Message {
+	return &mqtt.Message{Topic: b.AvailabilityTopic(), Payload: []byte("offline"), Retain: true}
+}
+
+// Online announces availability and the summary entities. Call it after
+// every (re)connect.
+func (b *Bridge) Online() error {
+	if err := b.publish(b.AvailabilityTopic(), "online"); err != nil {
+		return err
+	}
+	dev := device{
+		Identifiers:  []string{"ordercli"},
+		Name:         "ordercli",
+		Manufacturer: "ordercli",
+		Model:        "Active orders",
+		SWVersion:    version.Version,
+	}
+	state := b.opts.TopicPrefix + "/summary"
+	for _, s := range []sensor{
+		{key: "active", Name: "Active orders", ValueTemplate: "{{ value_json.active }}", Icon: "mdi:moped", StateClass: "measurement"},
+		{key: "next_eta", Name: "Next delivery", ValueTemplate: "{{ value_json.next_eta | default(None) }}", DeviceClass: "timestamp"},
+		{key: "next_eta_minutes", Name: "Next delivery in", ValueTemplate: "{{ value_json.next_eta_minutes }}", Unit: "min", Icon: "mdi:timer-sand", AttributesTopic: state},
+	} {
+		if err := b.publishSensor("ordercli", s, state, dev); err != nil {
+			return err
+		}
+	}
+	// After a reconnect, refresh the orders that already have entities.
+	for id, st := range b.known {
+		if err := b.publishOrder(id, st); err != nil {
+			return err
+		}
+	}
+	return b.publishSummary()
+}
+
+// Offline marks every entity unavailable (before a clean disconnect).
+func (b *Bridge) Offline() error { return b.publish(b.AvailabilityTopic(), "offline") }
+
+// Update publishes one poll. Failed polls are ignored so entities keep their
+// last known state; orders that left the provider's list are removed.
+func (b *Bridge) Update(snap poller.Snapshot) error {
+	if snap.Err != nil {
+		return nil
+	}
+	b.orders[snap.Provider] = snap.Orders
+	now := b.opts.Now()
+	seen := map[string]bool{}
+	for _, o := range snap.Orders {
+		id := objectID(snap.Provider, o.ID)
+		seen[id] = true
+		st := stateFor(snap.Provider, o, now)
+		if _, ok := b.known[id]; !ok {
+			if err := b.publishDiscovery(id, st); err != nil {
+				return err
+			}
+		}
+		b.known[id] = st
+		if err := b.publishOrder(id, st); err != nil {
+			return err
+		}
+	}
+	for id, st := range b.known {
+		if st.Provider != snap.Provider || seen[id] {
+			continue
+		}
+		if err := b.remove(id); err != nil {
+			return err
+		}
+		delete(b.known, id)
+	}
+	return b.publishSummary()
+}
+
+func stateFor(providerName string, o provider.Order, now time.Time) OrderState {
+	st := OrderState{
+		Provider:  providerName,
+		OrderID:   o.ID,
+		Vendor:    o.Vendor,
+		Status:    o.Status,
+		Delivered: o.Delivered,
+		ETA:       o.ETA,
+	}
+	if !o.ETA.IsZero() && !o.Delivered {
+		st.ETAMinutes = minutesUntil(o.ETA, now)
+	}
+	return st
+}
+
+func minutesUntil(t, now time.Time) *int {
+	m := max(int(math.Ceil(t.Sub(now).Minutes())), 0)
+	return &m
+}
+
+func (b *Bridge) publishSummary() error {
+	now := b.opts.Now()
+	var s Summary
+	var next *provider.Order
+	providers := make([]string, 0, len(b.orders))
+	for p := range b.orders {
+		providers = append(providers, p)
+	}
+	sort.Strings(providers)
+	for _, p := range providers {
+		for _, o := range b.orders[p] {
+			if o.Delivered {
+				continue
+			}
+			s.Active++
+			if o.ETA.IsZero() || (next != nil && !o.ETA.Before(next.ETA)) {
+				continue
+			}
+			o.Provider = p
+			next = &o
+		}
+	}
+	if next != nil {
+		s.NextProvider, s.NextVendor, s.NextETA = next.Provider, next.Vendor, next.ETA
+		s.NextETAMinutes = minutesUntil(next.ETA, now)
+	}
+	return b.publishJSON(b.opts.TopicPrefix+"/summary", s)
+}
+
+func (b *Bridge) stateTopic(id string) string {
+	return b.opts.TopicPrefix + "/orders/" + id + "/state"
+}
+
+func (b *Bridge) publishOrder(id string, st OrderState) error {
+	return b.publishJSON(b.stateTopic(id), st)
+}
+
+// orderSensors are the entities of one order device.
+var orderSensors = []sensor{
+	{key: "status", Name: "Status", ValueTemplate: "{{ value_json.status }}", Icon: "mdi:food-takeout-box"},
+	{key: "vendor", Name: "Vendor", ValueTemplate: "{{ value_json.vendor }}", Icon: "mdi:storefront"},
+	{key: "provider", Name: "Provider", ValueTemplate: "{{ value_json.provider }}", Icon: "mdi:moped"},
+	{key: "eta", Name: "ETA", ValueTemplate: "{{ value_json.eta | default(None) }}", DeviceClass: "timestamp"},
+	{key: "eta_minutes", Name: "Arrives in", ValueTemplate: "{{ value_json.eta_minutes }}", Unit: "min", Icon: "mdi:timer-sand"},
+}
+
+func (b *Bridge) publishDiscovery(id string, st OrderState) error {
+	name := st.Provider + " order " + st.OrderID
+	if st.Vendor != "" {
+		name = st.Provider + ": " + st.Vendor
+	}
+	dev := device{
+		Identifiers:  []string{"ordercli_" + id},
+		Name:         name,
+		Manufacturer: "ordercli",
+		Model:        st.Provider,
+		SWVersion:    version.Version,
+		ViaDevice:    "ordercli",
+	}
+	for _, s := range orderSensors {
+		if (s.key == "eta" || s.key == "eta_minutes") && b.opts.HasETA != nil && !b.opts.HasETA(st.Provider) {
+			continue
+		}
+		if s.key == "status" {
+			s.AttributesTopic = b.stateTopic(id)
+		}
+		if err := b.publishSensor("ordercli_"+id, s, b.stateTopic(id), dev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove clears the retained discovery configs (which deletes the entities)
+// and the retained state of one order.
+func (b *Bridge) remove(id string) error {
+	for _, s := range orderSensors {
+		if err := b.publish(b.configTopic("ordercli_"+id, s.key), ""); err != nil {
+			return err
+		}
+	}
+	return b.publish(b.stateTopic(id), "")
+}
+
+type device struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Model        string   `json:"model,omitempty"`
+	SWVersion    string   `json:"sw_version,omitempty"`
+	ViaDevice    string   `json:"via_device,omitempty"`
+}
+
+// sensor is a Home Assistant MQTT sensor discovery config.
+type sensor struct {
+	key string
+
+	Name              string `json:"name"`
+	UniqueID          string `json:"unique_id"`
+	ObjectID          string `json:"object_id"`
+	StateTopic        string `json:"state_topic"`
+	ValueTemplate     string `json:"value_template"`
+	AttributesTopic   string `json:"json_attributes_topic,omitempty"`
+	AvailabilityTopic string `json:"availability_topic"`
+	DeviceClass       string `json:"device_class,omitempty"`
+	StateClass        string `json:"state_class,omitempty"`
+	Unit              string `json:"unit_of_measurement,omitempty"`
+	Icon              string `json:"icon,omitempty"`
+	Device            device `json:"device"`
+}
+
+func (b *Bridge) configTopic(node, key string) string {
+	return b.opts.DiscoveryPrefix + "/sensor/" + node + "/" + key + "/config"
+}
+
+func (b *Bridge) publishSensor(node string, s sensor, stateTopic string, dev device) error {
+	s.UniqueID = node + "_" + s.key
+	s.ObjectID = s.UniqueID
+	s.StateTopic = stateTopic
+	s.AvailabilityTopic = b.AvailabilityTopic()
+	s.Device = dev
+	return b.publishJSON(b.configTopic(node, s.key), s)
+}
+
+func (b *Bridge) publishJSON(topic string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.pub.Publish(mqtt.Message{Topic: topic, Payload: body, Retain: true})
+}
+
+func (b *Bridge) publish(topic, payload string) error {
+	return b.pub.Publish(mqtt.Message{Topic: topic, Payload: []byte(payload), Retain: true})
+}
+
+var unsafeID = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// objectID is a topic- and entity-id-safe key, e.g. "foodora_oc-1".
+func objectID(providerName, orderID string) string {
+	return unsafeID.ReplaceAllString(strings.ToLower(providerName+"_"+orderID), "_")
+}
@@ -0,0 +1,173 @@
+package homeassistant
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/mqtt"
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// retainStore mimics a broker's retained-message table.
+type retainStore struct {
+	msgs map[string]string
+	n    int
+	fail error
+}
+
+func (r *retainStore) Publish(m mqtt.Message) error {
+	if r.fail != nil {
+		return r.fail
+	}
+	r.n++
+	if len(m.Payload) == 0 {
+		delete(r.msgs, m.Topic)
+		return nil
+	}
+	r.msgs[m.Topic] = string(m.Payload)
+	return nil
+}
+
+func decode[T any](t *testing.T, s string) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return v
+}
+
+func TestBridge_Lifecycle(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	store := &retainStore{msgs: map[string]string{}}
+	b := New(store, Options{TopicPrefix: "office/", Now: func() time.Time { return now }})
+
+	if got := b.Will(); got.Topic != "office/status" || string(got.Payload) != "offline" || !got.Retain {
+		t.Fatalf("will=%+v", got)
+	}
+	if err := b.Online(); err != nil {
+		t.Fatalf("online: %v", err)
+	}
+	if store.msgs["office/status"] != "online" {
+		t.Fatalf("availability=%q", store.msgs["office/status"])
+	}
+	cfg := decode[sensor](t, store.msgs["homeassistant/sensor/ordercli/next_eta_minutes/config"])
+	if cfg.StateTopic != "office/summary" || cfg.Unit != "min" || cfg.AvailabilityTopic != "office/status" || cfg.UniqueID != "ordercli_next_eta_minutes" {
+		t.Fatalf("summary config=%+v", cfg)
+	}
+
+	// A failed poll changes nothing.
+	before := store.n
+	if err := b.Update(poller.Snapshot{Provider: "foodora", Err: errors.New("boom")}); err != nil || store.n != before {
+		t.Fatalf("failed poll published: %v", err)
+	}
+
+	if err := b.Update(poller.Snapshot{Provider: "foodora", Orders: []provider.Order{
+		{ID: "OC-1", Vendor: "Pizza Place", Status: "Cooking", ETA: now.Add(4*time.Minute + 10*time.Second)},
+		{ID: "OC-2", Status: "Accepted"},
+	}}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := b.Update(poller.Snapshot{Provider: "glovo", Orders: []provider.Order{
+		{ID: "G 9", Vendor: "Tacos", Status: "Picked up", ETA: now.Add(20 * time.Minute)},
+	}}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	st := decode[OrderState](t, store.msgs["office/orders/foodora_oc-1/state"])
+	if st.Vendor != "Pizza Place" || st.Status != "Cooking" || st.ETAMinutes == nil || *st.ETAMinutes != 5 {
+		t.Fatalf("state=%+v", st)
+	}
+	if st := decode[OrderState](t, store.msgs["office/orders/foodora_oc-2/state"]); st.ETAMinutes != nil {
+		t.Fatalf("no ETA should be null: %+v", st)
+	}
+	status := decode[sensor](t, store.msgs["homeassistant/sensor/ordercli_foodora_oc-1/status/config"])
+	if status.Device.Name != "foodora: Pizza Place" || status.AttributesTopic != "office/orders/foodora_oc-1/state" || status.Device.ViaDevice != "ordercli" {
+		t.Fatalf("status config=%+v", status)
+	}
+	if name := decode[sensor](t, store.msgs["homeassistant/sensor/ordercli_foodora_oc-2/eta/config"]).Device.Name; name != "foodora order OC-2" {
+		t.Fatalf("device name=%q", name)
+	}
+	if _, ok := store.msgs["homeassistant/sensor/ordercli_glovo_g_9/eta_minutes/config"]; !ok {
+		t.Fatalf("glovo order id not sanitized: %v", keys(store.msgs))
+	}
+	sum := decode[Summary](t, store.msgs["office/summary"])
+	if sum.Active != 3 || sum.NextVendor != "Pizza Place" || sum.NextProvider != "foodora" || *sum.NextETAMinutes != 5 {
+		t.Fatalf("summary=%+v", sum)
+	}
+
+	// Reconnect republishes availability and known order state.
+	delete(store.msgs, "office/orders/glovo_g_9/state")
+	if err := b.Online(); err != nil {
+		t.Fatalf("online: %v", err)
+	}
+	if _, ok := store.msgs["office/orders/glovo_g_9/state"]; !ok {
+		t.Fatalf("state not republished")
+	}
+
+	// OC-1 delivered, OC-2 gone: its entities and state are removed.
+	now = now.Add(5 * time.Minute)
+	if err := b.Update(poller.Snapshot{Provider: "foodora", Orders: []provider.Order{
+		{ID: "OC-1", Vendor: "Pizza Place", Status: "Delivered", Delivered: true, ETA: now},
+	}}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	for k := range store.msgs {
+		if strings.Contains(k, "oc-2") {
+			t.Fatalf("OC-2 left behind %s", k)
+		}
+	}
+	if st := decode[OrderState](t, store.msgs["office/orders/foodora_oc-1/state"]); !st.Delivered || st.ETAMinutes != nil {
+		t.Fatalf("delivered state=%+v", st)
+	}
+	sum = decode[Summary](t, store.msgs["office/summary"])
+	if sum.Active != 1 || sum.NextProvider != "glovo" || *sum.NextETAMinutes != 15 {
+		t.Fatalf("summary=%+v", sum)
+	}
+
+	if err := b.Offline(); err != nil || store.msgs["office/status"] != "offline" {
+		t.Fatalf("offline: %v", err)
+	}
+}
+
+func TestBridge_PublishErrors(t *testing.T) {
+	store := &retainStore{msgs: map[string]string{}, fail: errors.New("down")}
+	b := New(store, Options{})
+	if b.AvailabilityTopic() != "ordercli/status" {
+		t.Fatalf("default prefix")
+	}
+	if err := b.Online(); err == nil {
+		t.Fatalf("expected error")
+	}
+	if err := b.Update(poller.Snapshot{Provider: "foodora", Orders: []provider.Order{{ID: "1"}}}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func keys(m map[string]string) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+func TestBridge_NoETASensorsWithoutETA(t *testing.T) {
+	store := &retainStore{msgs: map[string]string{}}
+	b := New(store, Options{HasETA: func(p string) bool { return p != "glovo" }})
+	if err := b.Update(poller.Snapshot{Provider: "glovo", Orders: []provider.Order{{ID: "9", Status: "Picked up"}}}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, ok := store.msgs["homeassistant/sensor/ordercli_glovo_9/status/config"]; !ok {
+		t.Fatalf("missing status sensor: %v", keys(store.msgs))
+	}
+	for _, key := range []string{"eta", "eta_minutes"} {
+		if _, ok := store.msgs["homeassistant/sensor/ordercli_glovo_9/"+key+"/config"]; ok {
+			t.Fatalf("%s sensor published for a provider without ETA", key)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+// Package metrics is a small Prometheus-compatible registry: counters,
+// gauges and histograms with labels, rendered in the text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are latency buckets in seconds.
+var DefBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20}
+
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+type metric interface {
+	write(w io.Writer) error
+}
+
+func NewRegistry() *Registry { return &Registry{} }
+
+func (r *Registry) add(m metric) {
+	r.mu.Lock()
+	r.metrics = append(r.metrics, m)
+	r.mu.Unlock()
+}
+
+// WriteText renders every metric in registration order.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	ms := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+	for _, m := range ms {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.WriteText(w)
+}
+
+type desc struct {
+	name, help, kind string
+	labels           []string
+}
+
+func (d desc) header(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, escapeHelp(d.help), d.name, d.kind)
+	return err
+}
+
+func (d desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s wants %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// series renders name{labels} for one label-value key, plus extra pairs.
+func (d desc) series(suffix, key string, extra ...string) string {
+	var pairs []string
+	if len(d.labels) > 0 {
+		for i, v := range strings.Split(key, "\xff") {
+			pairs = append(pairs, d.labels[i]+`="`+escapeLabel(v)+`"`)
+		}
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabel(extra[i+1])+`"`)
+	}
+	if len(pairs) == 0 {
+		return d.name + suffix
+	}
+	return d.name + suffix + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// values is a label-keyed float map shared by counters and gauges.
+type values struct {
+	desc
+	mu sync.Mutex
+	v  map[string]float64
+}
+
+func (m *values) write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.header(w); err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(m.v) {
+		if _, err := fmt.Fprintf(w, "%s %s\n", m.series("", k), formatFloat(m.v[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Value is the current value of one series (for tests and diagnostics).
+func (m *values) Value(labelValues ...string) float64 {
+	k := m.key(labelValues)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.v[k]
+}
+
+type CounterVec struct{ values }
+
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{values{desc: desc{name: name, help: help, kind: "counter", labels: labels}, v: map[string]float64{}}}
+	r.add(c)
+	return c
+}
+
+// Add increases the counter; negative deltas are ignored.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if c == nil || delta < 0 {
+		return
+	}
+	k := c.key(labelValues)
+	c.mu.Lock()
+	c.v[k] += delta
+	c.mu.Unlock()
+}
+
+func (c *CounterVec) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+type GaugeVec struct{ values }
+
+func (r *Registry) Gauge(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{values{desc: desc{name: name, help: help, kind: "gauge", labels: labels}, v: map[string]float64{}}}
+	r.add(g)
+	return g
+}
+
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	if g == nil {
+		return
+	}
+	k := g.key(labelValues)
+	g.mu.Lock()
+	g.v[k] = v
+	g.mu.Unlock()
+}
+
+// Delete drops one series.
+func (g *GaugeVec) Delete(labelValues ...string) {
+	if g == nil {
+		return
+	}
+	k := g.key(labelValues)
+	g.mu.Lock()
+	delete(g.v, k)
+	g.mu.Unlock()
+}
+
+// Sample is one series of a GaugeFunc.
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+type gaugeFunc struct {
+	desc
+	fn func() []Sample
+}
+
+// GaugeFunc is a gauge computed at scrape time.
+func (r *Registry) GaugeFunc(name, help string, labels []string, fn func() []Sample) {
+	r.add(&gaugeFunc{desc: desc{name: name, help: help, kind: "gauge", labels: labels}, fn: fn})
+}
+
+func (g *gaugeFunc) write(w io.Writer) error {
+	if err := g.header(w); err != nil {
+		return err
+	}
+	samples := g.fn()
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].Labels, "\xff") < strings.Join(samples[j].Labels, "\xff")
+	})
+	for _, s := range samples {
+		if _, err := fmt.Fprintf(w, "%s %s\n", g.series("", g.key(s.Labels)), formatFloat(s.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	h       map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Histogram registers a histogram; buckets are upper bounds in ascending order.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		desc:    desc{name: name, help: help, kind: "histogram", labels: labels},
+		buckets: append([]float64(nil), buckets...),
+		h:       map[string]*histogram{},
+	}
+	sort.Float64s(h.buckets)
+	r.add(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	if h == nil {
+		return
+	}
+	k := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.h[k]
+	if s == nil {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.h[k] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+// Count is the number of observations (for tests and diagnostics).
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	k := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s := h.h[k]; s != nil {
+		return s.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.header(w); err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(h.h) {
+		s := h.h[k]
+		var cum uint64
+		for i, le := range h.buckets {
+			cum += s.counts[i]
+			if _, err := fmt.Fprintf(w, "%s %d\n", h.series("_bucket", k, "le", formatFloat(le)), cum); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s %d\n%s %s\n%s %d\n",
+			h.series("_bucket", k, "le", "+Inf"), s.count,
+			h.series("_sum", k), formatFloat(s.sum),
+			h.series("_count", k), s.count,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
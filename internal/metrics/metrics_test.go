@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Text(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("requests_total", "Requests.\nSecond line", "provider", "code")
+	g := r.Gauge("active", "Active orders.", "provider")
+	h := r.Histogram("latency_seconds", "Latency.", []float64{1, 0.1}, "provider")
+	r.GaugeFunc("expiry_seconds", "Expiry.", []string{"provider"}, func() []Sample {
+		return []Sample{{Labels: []string{"glovo"}, Value: 2}, {Labels: []string{"foodora"}, Value: 1.5}}
+	})
+
+	c.Inc("foodora", "200")
+	c.Add(2, "foodora", "200")
+	c.Add(-5, "foodora", "200")
+	c.Inc(`we"ird`, "403")
+	g.Set(3, "foodora")
+	g.Set(1, "glovo")
+	g.Delete("glovo")
+	h.Observe(0.05, "foodora")
+	h.Observe(0.5, "foodora")
+	h.Observe(7, "foodora")
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := `# HELP requests_total Requests.\nSecond line
+# TYPE requests_total counter
+requests_total{provider="foodora",code="200"} 3
+requests_total{provider="we\"ird",code="403"} 1
+# HELP active Active orders.
+# TYPE active gauge
+active{provider="foodora"} 3
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{provider="foodora",le="0.1"} 1
+latency_seconds_bucket{provider="foodora",le="1"} 2
+latency_seconds_bucket{provider="foodora",le="+Inf"} 3
+latency_seconds_sum{provider="foodora"} 7.55
+latency_seconds_count{provider="foodora"} 3
+# HELP expiry_seconds Expiry.
+# TYPE expiry_seconds gauge
+expiry_seconds{provider="foodora"} 1.5
+expiry_seconds{provider="glovo"} 2
+`
+	if b.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+	if c.Value("foodora", "200") != 3 || h.Count("foodora") != 3 || h.Count("glovo") != 0 {
+		t.Fatalf("accessors")
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") || rec.Body.String() != want {
+		t.Fatalf("handler: %q", rec.Body.String())
+	}
+}
+
+func TestRegistry_NilAndLabelMismatch(t *testing.T) {
+	var c *CounterVec
+	var g *GaugeVec
+	var h *HistogramVec
+	c.Inc("x")
+	g.Set(1, "x")
+	g.Delete("x")
+	h.Observe(1, "x")
+
+	r := NewRegistry()
+	u := r.Counter("plain_total", "No labels.")
+	u.Inc()
+	var b strings.Builder
+	_ = r.WriteText(&b)
+	if !strings.Contains(b.String(), "\nplain_total 1\n") || formatFloat(posInf()) != "+Inf" || formatFloat(-posInf()) != "-Inf" {
+		t.Fatalf("out=%q", b.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic on label mismatch")
+		}
+	}()
+	u.Inc("extra")
+}
+
+func posInf() float64 {
+	var zero float64
+	return 1 / zero
+}
@@ -0,0 +1,184 @@
+package mqtt
+
+import (
+	"bufio"
+	"net"
+	"sync"
+)
+
+// Broker is a minimal in-process MQTT broker for tests: it accepts
+// connections, checks credentials, records every PUBLISH, keeps retained
+// messages and fires last wills. It does not route to subscribers.
+type Broker struct {
+	// Username and Password, when set, are required from clients.
+	Username string
+	Password string
+
+	ln net.Listener
+
+	mu       sync.Mutex
+	messages []Message
+	retained map[string]Message
+	conns    map[net.Conn]struct{}
+	clients  []string
+	closing  bool
+	wg       sync.WaitGroup
+}
+
+// NewBroker listens on a random loopback port.
+func NewBroker() (*Broker, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	b := &Broker{ln: ln, retained: map[string]Message{}, conns: map[net.Conn]struct{}{}}
+	b.wg.Add(1)
+	go b.accept()
+	return b, nil
+}
+
+// URL is the tcp:// address clients dial.
+func (b *Broker) URL() string { return "tcp://" + b.ln.Addr().String() }
+
+// Close stops the listener and drops all clients (without firing wills).
+func (b *Broker) Close() error {
+	err := b.ln.Close()
+	b.mu.Lock()
+	b.closing = true
+	for c := range b.conns {
+		c.Close()
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+	return err
+}
+
+// Messages returns every message published so far, in arrival order.
+func (b *Broker) Messages() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Message(nil), b.messages...)
+}
+
+// Retained returns the retained message of every topic.
+func (b *Broker) Retained() map[string]Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make(map[string]Message, len(b.retained))
+	for k, v := range b.retained {
+		out[k] = v
+	}
+	return out
+}
+
+// Clients returns the client IDs of accepted connections, in order.
+func (b *Broker) Clients() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.clients...)
+}
+
+func (b *Broker) accept() {
+	defer b.wg.Done()
+	for {
+		c, err := b.ln.Accept()
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.conns[c] = struct{}{}
+		b.mu.Unlock()
+		b.wg.Add(1)
+		go b.serve(c)
+	}
+}
+
+func (b *Broker) serve(c net.Conn) {
+	defer b.wg.Done()
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, c)
+		b.mu.Unlock()
+		c.Close()
+	}()
+	r := bufio.NewReader(c)
+	p, err := readPacket(r)
+	if err != nil || p.typ != typeConnect {
+		return
+	}
+	id, will, code := b.connect(p)
+	_, _ = c.Write(packet{typ: typeConnack, body: []byte{0, code}}.encode())
+	if code != 0 {
+		return
+	}
+	b.mu.Lock()
+	b.clients = append(b.clients, id)
+	b.mu.Unlock()
+
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			b.mu.Lock()
+			closing := b.closing
+			b.mu.Unlock()
+			if will != nil && !closing {
+				b.store(*will)
+			}
+			return
+		}
+		switch p.typ {
+		case typePublish:
+			if m, err := decodePublish(p); err == nil {
+				b.store(m)
+			}
+		case typePingreq:
+			_, _ = c.Write(packet{typ: typePingresp}.encode())
+		case typeDisconnect:
+			return
+		}
+	}
+}
+
+// connect parses CONNECT and returns the client ID, will and return code.
+func (b *Broker) connect(p packet) (string, *Message, byte) {
+	d := decoder{b: p.body}
+	if d.string() != "MQTT" || d.byte() != 4 {
+		return "", nil, 1
+	}
+	flags := d.byte()
+	d.uint16() // keep-alive
+	id := d.string()
+	var will *Message
+	if flags&0x04 != 0 {
+		will = &Message{Topic: d.string(), Retain: flags&0x20 != 0}
+		will.Payload = []byte(d.string())
+	}
+	var user, pass string
+	if flags&0x80 != 0 {
+		user = d.string()
+	}
+	if flags&0x40 != 0 {
+		pass = d.string()
+	}
+	if d.err != nil {
+		return "", nil, 2
+	}
+	if (b.Username != "" || b.Password != "") && (user != b.Username || pass != b.Password) {
+		return "", nil, 4
+	}
+	return id, will, 0
+}
+
+func (b *Broker) store(m Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, m)
+	if !m.Retain {
+		return
+	}
+	if len(m.Payload) == 0 {
+		delete(b.retained, m.Topic)
+		return
+	}
+	b.retained[m.Topic] = m
+}
@@ -0,0 +1,237 @@
+// Package mqtt is a minimal MQTT 3.1.1 publisher (QoS 0, retained messages,
+// last will, keep-alive) plus an in-process Broker for tests. It covers what
+// the Home Assistant integration needs and nothing more.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultKeepAlive is the keep-alive interval announced to the broker.
+const DefaultKeepAlive = 60 * time.Second
+
+type Message struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+type Options struct {
+	// Broker is tcp://host[:1883] (also mqtt://) or ssl://, tls://, mqtts://
+	// host[:8883] for TLS.
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	// Will is published by the broker when the connection drops uncleanly.
+	Will *Message
+	// KeepAlive defaults to DefaultKeepAlive.
+	KeepAlive time.Duration
+	// TLSConfig is used for TLS brokers (default: system roots).
+	TLSConfig *tls.Config
+}
+
+type Client struct {
+	conn net.Conn
+
+	mu     sync.Mutex // serializes writes
+	closed chan struct{}
+	once   sync.Once
+	err    error // why the connection ended; set before closed is closed
+}
+
+// ConnackError is a refused connection.
+type ConnackError struct{ Code byte }
+
+func (e *ConnackError) Error() string {
+	reasons := map[byte]string{
+		1: "unacceptable protocol version",
+		2: "identifier rejected",
+		3: "server unavailable",
+		4: "bad user name or password",
+		5: "not authorized",
+	}
+	if r, ok := reasons[e.Code]; ok {
+		return "mqtt: connection refused: " + r
+	}
+	return fmt.Sprintf("mqtt: connection refused (code %d)", e.Code)
+}
+
+// Dial connects and waits for the broker to accept the session.
+func Dial(ctx context.Context, opts Options) (*Client, error) {
+	addr, useTLS, err := brokerAddr(opts.Broker)
+	if err != nil {
+		return nil, err
+	}
+	keepAlive := opts.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = DefaultKeepAlive
+	}
+	connect, err := encodeConnect(opts, uint16(keepAlive/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	var conn net.Conn
+	if useTLS {
+		cfg := opts.TLSConfig
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		d := &tls.Dialer{Config: cfg}
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	}
+	r := bufio.NewReader(conn)
+	if _, err := conn.Write(connect.encode()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	p, err := readPacket(r)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("mqtt: reading CONNACK: %w", err)
+	}
+	if p.typ != typeConnack || len(p.body) != 2 {
+		conn.Close()
+		return nil, fmt.Errorf("mqtt: expected CONNACK, got packet type %d", p.typ)
+	}
+	if p.body[1] != 0 {
+		conn.Close()
+		return nil, &ConnackError{Code: p.body[1]}
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	c := &Client{conn: conn, closed: make(chan struct{})}
+	go c.readLoop(r, keepAlive)
+	go c.pingLoop(keepAlive)
+	return c, nil
+}
+
+func brokerAddr(broker string) (addr string, useTLS bool, err error) {
+	broker = strings.TrimSpace(broker)
+	if broker == "" {
+		return "", false, errors.New("mqtt: missing broker")
+	}
+	if !strings.Contains(broker, "://") {
+		broker = "tcp://" + broker
+	}
+	u, err := url.Parse(broker)
+	if err != nil {
+		return "", false, fmt.Errorf("mqtt: broker: %w", err)
+	}
+	port := "1883"
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		useTLS, port = true, "8883"
+	default:
+		return "", false, fmt.Errorf("mqtt: unsupported broker scheme %q (use tcp:// or ssl://)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", false, fmt.Errorf("mqtt: broker %q has no host", broker)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	return net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
+
+// readLoop consumes PINGRESPs and notices when the broker goes away.
+func (c *Client) readLoop(r *bufio.Reader, keepAlive time.Duration) {
+	for {
+		// The broker answers every PINGREQ; silence means the link is dead.
+		_ = c.conn.SetReadDeadline(time.Now().Add(keepAlive * 3 / 2))
+		if _, err := readPacket(r); err != nil {
+			c.shutdown(fmt.Errorf("mqtt: connection lost: %w", err))
+			return
+		}
+	}
+}
+
+func (c *Client) pingLoop(keepAlive time.Duration) {
+	t := time.NewTicker(keepAlive / 2)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-t.C:
+			if err := c.write(packet{typ: typePingreq}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) write(p packet) error {
+	select {
+	case <-c.closed:
+		return c.err
+	default:
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := c.conn.Write(p.encode()); err != nil {
+		c.shutdown(fmt.Errorf("mqtt: write: %w", err))
+		return c.err
+	}
+	return nil
+}
+
+func (c *Client) shutdown(err error) {
+	c.once.Do(func() {
+		c.err = err
+		close(c.closed)
+		c.conn.Close()
+	})
+}
+
+// Publish sends m with QoS 0.
+func (c *Client) Publish(m Message) error {
+	p, err := encodePublish(m)
+	if err != nil {
+		return err
+	}
+	return c.write(p)
+}
+
+// Done is closed when the connection ends.
+func (c *Client) Done() <-chan struct{} { return c.closed }
+
+// Err is why the connection ended (nil while it is up).
+func (c *Client) Err() error {
+	select {
+	case <-c.closed:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+// Close disconnects cleanly; the broker discards the will.
+func (c *Client) Close() error {
+	err := c.write(packet{typ: typeDisconnect})
+	c.shutdown(errors.New("mqtt: client closed"))
+	return err
+}
@@ -0,0 +1,193 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newBroker(t *testing.T) *Broker {
+	t.Helper()
+	b, err := NewBroker()
+	if err != nil {
+		t.Fatalf("broker: %v", err)
+	}
+	t.Cleanup(func() { _ = b.Close() })
+	return b
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPublishRetainAndClear(t *testing.T) {
+	b := newBroker(t)
+	b.Username, b.Password = "u", "p"
+	c, err := Dial(context.Background(), Options{Broker: b.URL(), ClientID: "test", Username: "u", Password: "p"})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	big := bytes.Repeat([]byte("x"), 20_000)
+	for _, m := range []Message{
+		{Topic: "a/state", Payload: []byte(`{"status":"Cooking"}`), Retain: true},
+		{Topic: "a/big", Payload: big},
+		{Topic: "b/state", Payload: []byte("1"), Retain: true},
+		{Topic: "b/state", Retain: true}, // clears the retained message
+	} {
+		if err := c.Publish(m); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	if err := c.Publish(Message{}); err == nil {
+		t.Fatalf("expected empty topic error")
+	}
+	waitFor(t, "messages", func() bool { return len(b.Messages()) == 4 })
+	if err := c.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	<-c.Done()
+	if c.Err() == nil {
+		t.Fatalf("Err should be set after Close")
+	}
+
+	msgs := b.Messages()
+	if !bytes.Equal(msgs[1].Payload, big) || msgs[1].Retain {
+		t.Fatalf("big message mangled: %d bytes", len(msgs[1].Payload))
+	}
+	ret := b.Retained()
+	if len(ret) != 1 || string(ret["a/state"].Payload) != `{"status":"Cooking"}` {
+		t.Fatalf("retained=%v", ret)
+	}
+	if got := b.Clients(); len(got) != 1 || got[0] != "test" {
+		t.Fatalf("clients=%v", got)
+	}
+	if err := c.Publish(Message{Topic: "late"}); err == nil {
+		t.Fatalf("publish after close should fail")
+	}
+}
+
+func TestWillOnConnectionLoss(t *testing.T) {
+	b := newBroker(t)
+	c, err := Dial(context.Background(), Options{
+		Broker: strings.TrimPrefix(b.URL(), "tcp://"),
+		Will:   &Message{Topic: "ordercli/status", Payload: []byte("offline"), Retain: true},
+	})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	if c.Err() != nil {
+		t.Fatalf("Err while connected: %v", c.Err())
+	}
+	c.shutdown(errors.New("test")) // no DISCONNECT
+	waitFor(t, "will", func() bool { return string(b.Retained()["ordercli/status"].Payload) == "offline" })
+
+	// Broker shutdown is noticed by the client.
+	c2, err := Dial(context.Background(), Options{Broker: b.URL()})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	_ = b.Close()
+	select {
+	case <-c2.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatalf("client did not notice broker shutdown")
+	}
+	if err := c2.Err(); err == nil || !strings.Contains(err.Error(), "connection lost") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestDialErrors(t *testing.T) {
+	b := newBroker(t)
+	b.Username = "u"
+	_, err := Dial(context.Background(), Options{Broker: b.URL(), Username: "u", Password: "wrong"})
+	var ce *ConnackError
+	if !errors.As(err, &ce) || ce.Code != 4 || !strings.Contains(err.Error(), "bad user name") {
+		t.Fatalf("err=%v", err)
+	}
+	if (&ConnackError{Code: 9}).Error() != "mqtt: connection refused (code 9)" {
+		t.Fatalf("unknown code message")
+	}
+	for _, broker := range []string{"", "http://x", "tcp://", "tcp://%zz"} {
+		if _, err := Dial(context.Background(), Options{Broker: broker}); err == nil {
+			t.Fatalf("%q: expected error", broker)
+		}
+	}
+}
+
+func TestEncodeConnect_Credentials(t *testing.T) {
+	if _, err := encodeConnect(Options{Password: "secret"}, 60); err == nil {
+		t.Fatalf("expected error for a password without username")
+	}
+	if _, err := Dial(context.Background(), Options{Broker: "tcp://127.0.0.1:1", Password: "secret"}); err == nil || !strings.Contains(err.Error(), "without a username") {
+		t.Fatalf("Dial err=%v", err)
+	}
+	p, err := encodeConnect(Options{ClientID: "c", Username: "u", Password: "p"}, 60)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	// "MQTT", level 4, then the flags: user name, password, clean session.
+	if flags := p.body[7]; flags != 0xc2 {
+		t.Fatalf("flags=%#x", flags)
+	}
+	p, _ = encodeConnect(Options{ClientID: "c", Username: "u"}, 60)
+	if flags := p.body[7]; flags != 0x82 {
+		t.Fatalf("username-only flags=%#x", flags)
+	}
+}
+
+func TestBrokerAddr(t *testing.T) {
+	for in, want := range map[string]string{
+		"localhost":           "localhost:1883",
+		"mqtt://10.0.0.2":     "10.0.0.2:1883",
+		"tcp://broker:1884":   "broker:1884",
+		"ssl://broker":        "broker:8883 tls",
+		"mqtts://broker:9000": "broker:9000 tls",
+		"tls://[::1]":         "[::1]:8883 tls",
+	} {
+		addr, useTLS, err := brokerAddr(in)
+		got := addr
+		if useTLS {
+			got += " tls"
+		}
+		if err != nil || got != want {
+			t.Fatalf("%q: got %q err=%v want %q", in, got, err, want)
+		}
+	}
+}
+
+func TestBrokerRejectsBadConnect(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(packet{typ: typeConnect, body: appendString(nil, "MQIsdp")}.encode())
+	p, err := readPacket(bufio.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if _, _, code := (&Broker{}).connect(p); code != 1 {
+		t.Fatalf("code=%d", code)
+	}
+	if _, _, code := (&Broker{}).connect(packet{body: append(appendString(nil, "MQTT"), 4)}); code != 2 {
+		t.Fatalf("truncated connect code=%d", code)
+	}
+	if _, err := readPacket(bufio.NewReader(bytes.NewReader([]byte{0x30, 0xff, 0xff, 0xff, 0xff}))); err == nil {
+		t.Fatalf("expected malformed length error")
+	}
+	if _, err := decodePublish(packet{typ: typePublish, body: []byte{0}}); err == nil {
+		t.Fatalf("expected truncated publish error")
+	}
+	m, err := decodePublish(packet{typ: typePublish, flags: 0x02, body: append(appendString(nil, "t"), 0, 1, 'x')})
+	if err != nil || m.Topic != "t" || string(m.Payload) != "x" {
+		t.Fatalf("qos1 publish: %+v %v", m, err)
+	}
+}
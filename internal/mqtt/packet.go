@@ -0,0 +1,184 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Control packet types (MQTT 3.1.1, section 2.2.1).
+const (
+	typeConnect    = 1
+	typeConnack    = 2
+	typePublish    = 3
+	typePingreq    = 12
+	typePingresp   = 13
+	typeDisconnect = 14
+)
+
+// maxRemaining is the largest remaining length the protocol can encode.
+const maxRemaining = 268_435_455
+
+type packet struct {
+	typ   byte
+	flags byte
+	body  []byte
+}
+
+func (p packet) encode() []byte {
+	out := []byte{p.typ<<4 | p.flags&0x0f}
+	n := len(p.body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(out, p.body...)
+}
+
+func readPacket(r *bufio.Reader) (packet, error) {
+	h, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	n, mul := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return packet{}, errors.New("mqtt: malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		n += int(b&0x7f) * mul
+		mul *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	return packet{typ: h >> 4, flags: h & 0x0f, body: body}, nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// decoder walks a packet body.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) uint16() uint16 {
+	if d.err != nil || len(d.b) < 2 {
+		d.fail()
+		return 0
+	}
+	v := binary.BigEndian.Uint16(d.b)
+	d.b = d.b[2:]
+	return v
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil || len(d.b) < 1 {
+		d.fail()
+		return 0
+	}
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v
+}
+
+func (d *decoder) string() string {
+	n := int(d.uint16())
+	if d.err != nil || len(d.b) < n {
+		d.fail()
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = errors.New("mqtt: truncated packet")
+	}
+}
+
+func encodeConnect(opts Options, keepAlive uint16) (packet, error) {
+	// MQTT 3.1.1 §3.1.2.9: no password flag without the user name flag.
+	if opts.Password != "" && opts.Username == "" {
+		return packet{}, errors.New("mqtt: password set without a username")
+	}
+	var flags byte = 0x02 // clean session
+	if opts.Will != nil {
+		flags |= 0x04
+		if opts.Will.Retain {
+			flags |= 0x20
+		}
+	}
+	if opts.Username != "" {
+		flags |= 0x80
+	}
+	if opts.Password != "" {
+		flags |= 0x40
+	}
+	b := appendString(nil, "MQTT")
+	b = append(b, 4, flags)
+	b = binary.BigEndian.AppendUint16(b, keepAlive)
+	b = appendString(b, opts.ClientID)
+	if opts.Will != nil {
+		b = appendString(b, opts.Will.Topic)
+		b = appendString(b, string(opts.Will.Payload))
+	}
+	if opts.Username != "" {
+		b = appendString(b, opts.Username)
+	}
+	if opts.Password != "" {
+		b = appendString(b, opts.Password)
+	}
+	return packet{typ: typeConnect, body: b}, nil
+}
+
+func encodePublish(m Message) (packet, error) {
+	if m.Topic == "" {
+		return packet{}, errors.New("mqtt: empty topic")
+	}
+	b := appendString(nil, m.Topic)
+	b = append(b, m.Payload...)
+	if len(b) > maxRemaining {
+		return packet{}, fmt.Errorf("mqtt: payload too large (%d bytes)", len(m.Payload))
+	}
+	var flags byte
+	if m.Retain {
+		flags = 0x01
+	}
+	return packet{typ: typePublish, flags: flags, body: b}, nil
+}
+
+func decodePublish(p packet) (Message, error) {
+	d := decoder{b: p.body}
+	m := Message{Topic: d.string(), Retain: p.flags&0x01 != 0}
+	if qos := p.flags >> 1 & 0x03; qos > 0 {
+		d.uint16() // packet identifier
+	}
+	if d.err != nil {
+		return Message{}, d.err
+	}
+	m.Payload = append([]byte(nil), d.b...)
+	return m, nil
+}
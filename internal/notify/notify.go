@@ -0,0 +1,222 @@
+// Package notify pushes order status changes to external sinks: HTTP
+// webhooks, ntfy and Gotify servers, desktop notifications (notify-send) and
+// arbitrary commands.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/poller"
+)
+
+// Sink types accepted in config.NotifySink.Type.
+const (
+	Webhook    = "webhook"
+	Ntfy       = "ntfy"
+	Gotify     = "gotify"
+	NotifySend = "notify-send"
+	Exec       = "exec"
+)
+
+// DefaultTimeout bounds a single delivery to a single sink.
+const DefaultTimeout = 10 * time.Second
+
+// notifySendBin is swapped in tests.
+var notifySendBin = "notify-send"
+
+type Options struct {
+	// HTTPClient is used by the webhook, ntfy and gotify sinks
+	// (default: http.DefaultClient).
+	HTTPClient *http.Client
+	// Timeout per delivery (default DefaultTimeout).
+	Timeout time.Duration
+}
+
+// Notifier fans events out to the configured sinks.
+type Notifier struct {
+	sinks   []config.NotifySink
+	http    *http.Client
+	timeout time.Duration
+}
+
+// New validates cfg. A nil or empty config yields a Notifier with no sinks.
+func New(cfg *config.NotifyConfig, opts Options) (*Notifier, error) {
+	n := &Notifier{http: opts.HTTPClient, timeout: opts.Timeout}
+	if n.http == nil {
+		n.http = http.DefaultClient
+	}
+	if n.timeout <= 0 {
+		n.timeout = DefaultTimeout
+	}
+	if cfg == nil {
+		return n, nil
+	}
+	for i, s := range cfg.Sinks {
+		s.Type = strings.ToLower(strings.TrimSpace(s.Type))
+		if err := validate(s); err != nil {
+			return nil, fmt.Errorf("notify: sink %d: %w", i+1, err)
+		}
+		n.sinks = append(n.sinks, s)
+	}
+	return n, nil
+}
+
+func validate(s config.NotifySink) error {
+	switch s.Type {
+	case Webhook, Ntfy, Gotify:
+		if strings.TrimSpace(s.URL) == "" {
+			return fmt.Errorf("%s needs url", s.Type)
+		}
+	case Exec:
+		if len(s.Command) == 0 {
+			return errors.New("exec needs command")
+		}
+	case NotifySend:
+	case "":
+		return errors.New("missing type")
+	default:
+		return fmt.Errorf("unknown type %q (use webhook, ntfy, gotify, notify-send or exec)", s.Type)
+	}
+	return nil
+}
+
+// Len is the number of configured sinks.
+func (n *Notifier) Len() int {
+	if n == nil {
+		return 0
+	}
+	return len(n.sinks)
+}
+
+// Send delivers ev to every sink that accepts its provider. Sinks are tried
+// in order and a failing sink does not stop the others; the returned error
+// joins all failures.
+func (n *Notifier) Send(ctx context.Context, ev poller.Event) error {
+	if n == nil {
+		return nil
+	}
+	var errs []error
+	for _, s := range n.sinks {
+		if len(s.Providers) > 0 && !slices.Contains(s.Providers, ev.Provider) {
+			continue
+		}
+		sctx, cancel := context.WithTimeout(ctx, n.timeout)
+		err := n.send(sctx, s, ev)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Type, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) send(ctx context.Context, s config.NotifySink, ev poller.Event) error {
+	switch s.Type {
+	case Webhook:
+		body, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		h := map[string]string{"Content-Type": "application/json"}
+		for k, v := range s.Headers {
+			h[k] = v
+		}
+		return n.post(ctx, s.URL, h, body)
+	case Ntfy:
+		h := map[string]string{"Title": Title(ev), "Tags": tag(ev)}
+		if s.Priority > 0 {
+			h["Priority"] = strconv.Itoa(s.Priority)
+		}
+		if s.Token != "" {
+			h["Authorization"] = "Bearer " + s.Token
+		}
+		return n.post(ctx, s.URL, h, []byte(Message(ev)))
+	case Gotify:
+		body, err := json.Marshal(map[string]any{"title": Title(ev), "message": Message(ev), "priority": s.Priority})
+		if err != nil {
+			return err
+		}
+		h := map[string]string{"Content-Type": "application/json"}
+		if s.Token != "" {
+			h["X-Gotify-Key"] = s.Token
+		}
+		return n.post(ctx, strings.TrimRight(s.URL, "/")+"/message", h, body)
+	case NotifySend:
+		return run(exec.CommandContext(ctx, notifySendBin, "--app-name=ordercli", Title(ev), Message(ev)))
+	case Exec:
+		body, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		cmd := exec.CommandContext(ctx, s.Command[0], s.Command[1:]...)
+		cmd.Stdin = bytes.NewReader(append(body, '\n'))
+		return run(cmd)
+	}
+	return fmt.Errorf("unknown type %q", s.Type)
+}
+
+func (n *Notifier) post(ctx context.Context, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := n.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("POST %s: HTTP %d: %s", url, res.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+func run(cmd *exec.Cmd) error {
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// Title is the notification headline, e.g. "foodora: Pizza Place".
+func Title(ev poller.Event) string {
+	if ev.Vendor == "" {
+		return ev.Provider + " order " + ev.OrderID
+	}
+	return ev.Provider + ": " + ev.Vendor
+}
+
+// Message is the notification body, e.g. "OC-1: Cooking -> On the way".
+func Message(ev poller.Event) string {
+	return ev.OrderID + ": " + ev.Summary()
+}
+
+func tag(ev poller.Event) string {
+	switch {
+	case ev.Delivered:
+		return "white_check_mark"
+	case ev.Gone:
+		return "warning"
+	}
+	return "takeout_box"
+}
@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/poller"
+)
+
+var testEvent = poller.Event{
+	Provider: "foodora",
+	OrderID:  "OC-1",
+	Vendor:   "Pizza",
+	From:     "Cooking",
+	To:       "On the way",
+	At:       time.Date(2025, 12, 20, 18, 0, 0, 0, time.UTC),
+}
+
+type captured struct {
+	path   string
+	header http.Header
+	body   string
+}
+
+func captureServer(t *testing.T) (*httptest.Server, func() []captured) {
+	t.Helper()
+	var mu sync.Mutex
+	var got []captured
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got = append(got, captured{path: r.URL.Path, header: r.Header.Clone(), body: string(b)})
+		mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/fail") {
+			http.Error(w, "nope", http.StatusBadGateway)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []captured {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]captured(nil), got...)
+	}
+}
+
+func TestNotifier_HTTPSinks(t *testing.T) {
+	srv, got := captureServer(t)
+	n, err := New(&config.NotifyConfig{Sinks: []config.NotifySink{
+		{Type: "Webhook", URL: srv.URL + "/hook", Headers: map[string]string{"X-Secret": "s"}},
+		{Type: Ntfy, URL: srv.URL + "/orders", Token: "tk", Priority: 4},
+		{Type: Gotify, URL: srv.URL + "/", Token: "app"},
+		{Type: Webhook, URL: srv.URL + "/glovo-only", Providers: []string{"glovo"}},
+	}}, Options{})
+	if err != nil || n.Len() != 4 {
+		t.Fatalf("New: %v len=%d", err, n.Len())
+	}
+	if err := n.Send(context.Background(), testEvent); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	reqs := got()
+	if len(reqs) != 3 {
+		t.Fatalf("requests=%+v", reqs)
+	}
+	var ev poller.Event
+	if err := json.Unmarshal([]byte(reqs[0].body), &ev); err != nil || ev != testEvent || reqs[0].header.Get("X-Secret") != "s" {
+		t.Fatalf("webhook=%+v err=%v", reqs[0], err)
+	}
+	if reqs[1].body != "OC-1: Cooking -> On the way" || reqs[1].header.Get("Title") != "foodora: Pizza" ||
+		reqs[1].header.Get("Priority") != "4" || reqs[1].header.Get("Authorization") != "Bearer tk" {
+		t.Fatalf("ntfy=%+v", reqs[1])
+	}
+	if reqs[2].path != "/message" || reqs[2].header.Get("X-Gotify-Key") != "app" || !strings.Contains(reqs[2].body, `"title":"foodora: Pizza"`) {
+		t.Fatalf("gotify=%+v", reqs[2])
+	}
+}
+
+func TestNotifier_FailuresAreJoined(t *testing.T) {
+	srv, got := captureServer(t)
+	n, err := New(&config.NotifyConfig{Sinks: []config.NotifySink{
+		{Type: Webhook, URL: srv.URL + "/fail"},
+		{Type: Webhook, URL: srv.URL + "/ok"},
+	}}, Options{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	err = n.Send(context.Background(), testEvent)
+	if err == nil || !strings.Contains(err.Error(), "webhook: POST") || !strings.Contains(err.Error(), "HTTP 502: nope") {
+		t.Fatalf("err=%v", err)
+	}
+	if len(got()) != 2 {
+		t.Fatalf("second sink must still run")
+	}
+}
+
+func TestNotifier_Commands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	stdinFile := filepath.Join(dir, "event.json")
+	argsFile := filepath.Join(dir, "args.txt")
+
+	stub := filepath.Join(dir, "notify-send")
+	if err := os.WriteFile(stub, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\" > "+argsFile+"\n"), 0o755); err != nil {
+		t.Fatalf("stub: %v", err)
+	}
+	old := notifySendBin
+	notifySendBin = stub
+	t.Cleanup(func() { notifySendBin = old })
+
+	n, err := New(&config.NotifyConfig{Sinks: []config.NotifySink{
+		{Type: Exec, Command: []string{"sh", "-c", "cat > " + stdinFile}},
+		{Type: NotifySend},
+	}}, Options{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := n.Send(context.Background(), poller.Event{Provider: "glovo", OrderID: "7", To: "Delivered", Delivered: true}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	b, _ := os.ReadFile(stdinFile)
+	if !strings.Contains(string(b), `"order_id":"7"`) || !strings.HasSuffix(string(b), "\n") {
+		t.Fatalf("stdin=%q", b)
+	}
+	b, _ = os.ReadFile(argsFile)
+	if string(b) != "--app-name=ordercli\nglovo order 7\n7: Delivered (delivered)\n" {
+		t.Fatalf("args=%q", b)
+	}
+
+	n, _ = New(&config.NotifyConfig{Sinks: []config.NotifySink{{Type: Exec, Command: []string{"sh", "-c", "echo broken >&2; exit 2"}}}}, Options{})
+	if err := n.Send(context.Background(), testEvent); err == nil || !strings.Contains(err.Error(), "exec: exit status 2: broken") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if n, err := New(nil, Options{}); err != nil || n.Len() != 0 || n.Send(context.Background(), testEvent) != nil {
+		t.Fatalf("nil config: %v", err)
+	}
+	var nilN *Notifier
+	if nilN.Len() != 0 || nilN.Send(context.Background(), testEvent) != nil {
+		t.Fatalf("nil notifier")
+	}
+	for _, s := range []config.NotifySink{
+		{},
+		{Type: "pager"},
+		{Type: Ntfy},
+		{Type: Exec},
+	} {
+		if _, err := New(&config.NotifyConfig{Sinks: []config.NotifySink{s}}, Options{}); err == nil {
+			t.Fatalf("expected error for %+v", s)
+		}
+	}
+}
+
+func TestTitleAndTag(t *testing.T) {
+	if Title(poller.Event{Provider: "glovo", OrderID: "1"}) != "glovo order 1" {
+		t.Fatalf("title")
+	}
+	if tag(poller.Event{Gone: true}) != "warning" || tag(poller.Event{Delivered: true}) != "white_check_mark" || tag(testEvent) != "takeout_box" {
+		t.Fatalf("tags")
+	}
+}
@@ -0,0 +1,116 @@
+// Package poller polls active orders for several providers concurrently,
+// each on its own schedule.
+package poller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+const (
+	DefaultInterval = 30 * time.Second
+	MinInterval     = 5 * time.Second
+)
+
+// Snapshot is the result of one poll of one provider.
+type Snapshot struct {
+	Provider string
+	Orders   []provider.Order
+	Err      error
+	At       time.Time
+	// Next is how long until this provider is polled again.
+	Next time.Duration
+}
+
+type Poller struct {
+	Providers []provider.Provider
+	// Interval is used when a provider gives no poll hint (default 30s).
+	Interval time.Duration
+	// Min caps how aggressively a provider hint may poll (default 5s).
+	Min time.Duration
+
+	now func() time.Time
+}
+
+// Once polls every provider concurrently and returns snapshots in provider order.
+func (p *Poller) Once(ctx context.Context) []Snapshot {
+	out := make([]Snapshot, len(p.Providers))
+	var wg sync.WaitGroup
+	for i, pr := range p.Providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out[i] = p.poll(ctx, pr)
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// Run polls until ctx is done. fn is called for every snapshot; calls are
+// serialized so fn need not lock. Each provider is rescheduled using its own
+// poll hint, or Interval when it has none (or the poll failed).
+func (p *Poller) Run(ctx context.Context, fn func(Snapshot)) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, pr := range p.Providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				snap := p.poll(ctx, pr)
+				if ctx.Err() != nil {
+					return
+				}
+				mu.Lock()
+				fn(snap)
+				mu.Unlock()
+
+				t := time.NewTimer(snap.Next)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return
+				case <-t.C:
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Poller) poll(ctx context.Context, pr provider.Provider) Snapshot {
+	now := time.Now
+	if p.now != nil {
+		now = p.now
+	}
+	res, err := pr.ActiveOrders(ctx)
+	snap := Snapshot{
+		Provider: pr.Name(),
+		Orders:   res.Orders,
+		Err:      err,
+		At:       now(),
+		Next:     p.interval(),
+	}
+	if err == nil && res.PollInterval > 0 {
+		snap.Next = max(res.PollInterval, p.min())
+	}
+	return snap
+}
+
+func (p *Poller) interval() time.Duration {
+	if p.Interval > 0 {
+		return max(p.Interval, p.min())
+	}
+	return DefaultInterval
+}
+
+func (p *Poller) min() time.Duration {
+	if p.Min > 0 {
+		return p.Min
+	}
+	return MinInterval
+}
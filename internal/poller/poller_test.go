@@ -0,0 +1,90 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+type fakeProvider struct {
+	name  string
+	hint  time.Duration
+	err   error
+	calls atomic.Int32
+}
+
+func (f *fakeProvider) Name() string                      { return f.name }
+func (f *fakeProvider) Capabilities() provider.Capability { return provider.CapActiveOrders }
+func (f *fakeProvider) History(context.Context, provider.HistoryRequest) (provider.HistoryPage, error) {
+	return provider.HistoryPage{}, provider.ErrUnsupported
+}
+
+func (f *fakeProvider) ActiveOrders(context.Context) (provider.ActiveOrders, error) {
+	f.calls.Add(1)
+	if f.err != nil {
+		return provider.ActiveOrders{}, f.err
+	}
+	return provider.ActiveOrders{
+		Orders:       []provider.Order{{Provider: f.name, ID: "1", Active: true}},
+		PollInterval: f.hint,
+	}, nil
+}
+
+func (f *fakeProvider) OrderDetail(context.Context, string) (provider.Order, error) {
+	return provider.Order{}, provider.ErrUnsupported
+}
+
+func (f *fakeProvider) Me(context.Context) (provider.Account, error) {
+	return provider.Account{}, provider.ErrUnsupported
+}
+
+func TestOnceKeepsProviderOrder(t *testing.T) {
+	a := &fakeProvider{name: "a"}
+	b := &fakeProvider{name: "b", err: errors.New("boom")}
+	p := &Poller{Providers: []provider.Provider{a, b}}
+
+	snaps := p.Once(context.Background())
+	if len(snaps) != 2 || snaps[0].Provider != "a" || snaps[1].Provider != "b" {
+		t.Fatalf("snaps=%+v", snaps)
+	}
+	if snaps[0].Err != nil || len(snaps[0].Orders) != 1 || snaps[1].Err == nil {
+		t.Fatalf("snaps=%+v", snaps)
+	}
+	if snaps[0].Next != DefaultInterval {
+		t.Fatalf("next=%s", snaps[0].Next)
+	}
+}
+
+func TestRunHonoursProviderHints(t *testing.T) {
+	fast := &fakeProvider{name: "fast", hint: 10 * time.Millisecond}
+	slow := &fakeProvider{name: "slow"}
+	p := &Poller{
+		Providers: []provider.Provider{fast, slow},
+		Interval:  time.Hour,
+		Min:       time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	seen := map[string]int{}
+	p.Run(ctx, func(s Snapshot) { seen[s.Provider]++ })
+
+	if seen["slow"] != 1 {
+		t.Fatalf("slow polled %d times", seen["slow"])
+	}
+	if seen["fast"] < 3 {
+		t.Fatalf("fast polled %d times", seen["fast"])
+	}
+}
+
+func TestHintIsClampedToMin(t *testing.T) {
+	p := &Poller{Providers: []provider.Provider{&fakeProvider{name: "x", hint: time.Second}}, Min: 10 * time.Second}
+	if got := p.Once(context.Background())[0].Next; got != 10*time.Second {
+		t.Fatalf("next=%s", got)
+	}
+}
@@ -0,0 +1,117 @@
+package poller
+
+import (
+	"sort"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// Event is a status transition of one active order.
+type Event struct {
+	Provider string `json:"provider"`
+	OrderID  string `json:"order_id"`
+	Vendor   string `json:"vendor,omitempty"`
+	// From is empty when the order is first seen.
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+	Delivered bool   `json:"delivered"`
+	// Gone means the order dropped off the provider's active list without
+	// being reported delivered (finished, cancelled or expired).
+	Gone bool      `json:"gone,omitempty"`
+	At   time.Time `json:"at"`
+}
+
+// Tracker diffs successive active-order snapshots into Events.
+type Tracker struct {
+	orders map[string]*tracked
+	order  []string
+}
+
+type tracked struct {
+	provider.Order
+	provider string
+	gone     bool
+}
+
+// Update records a successful poll of one provider and returns what changed:
+// new orders, status changes, deliveries and orders that left the list.
+func (t *Tracker) Update(providerName string, orders []provider.Order, at time.Time) []Event {
+	if t.orders == nil {
+		t.orders = map[string]*tracked{}
+	}
+	var events []Event
+	seen := map[string]bool{}
+	for _, o := range orders {
+		k := providerName + "/" + o.ID
+		seen[k] = true
+		prev, ok := t.orders[k]
+		if !ok {
+			t.orders[k] = &tracked{Order: o, provider: providerName}
+			t.order = append(t.order, k)
+			events = append(events, eventFor(providerName, o, "", at))
+			continue
+		}
+		if prev.Status != o.Status || prev.Delivered != o.Delivered || prev.gone {
+			events = append(events, eventFor(providerName, o, prev.Status, at))
+		}
+		prev.Order, prev.gone = o, false
+	}
+	for _, k := range t.order {
+		prev := t.orders[k]
+		if prev.provider != providerName || seen[k] || prev.gone || prev.Delivered {
+			continue
+		}
+		prev.gone = true
+		ev := eventFor(providerName, prev.Order, prev.Status, at)
+		ev.To, ev.Gone = "", true
+		events = append(events, ev)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].OrderID < events[j].OrderID })
+	return events
+}
+
+func eventFor(providerName string, o provider.Order, from string, at time.Time) Event {
+	return Event{
+		Provider:  providerName,
+		OrderID:   o.ID,
+		Vendor:    o.Vendor,
+		From:      from,
+		To:        o.Status,
+		Delivered: o.Delivered,
+		At:        at,
+	}
+}
+
+// Summary is a one-line description, e.g. "Cooking -> On the way".
+func (e Event) Summary() string {
+	line := e.To
+	switch {
+	case e.Gone:
+		line = e.From + " -> (no longer active)"
+	case e.From != "":
+		line = e.From + " -> " + e.To
+	}
+	if e.Delivered {
+		line += " (delivered)"
+	}
+	return line
+}
+
+// Finished reports whether every order seen so far is delivered or gone;
+// undelivered counts the gone ones. It is false until an order was seen.
+func (t *Tracker) Finished() (done bool, undelivered int) {
+	if len(t.orders) == 0 {
+		return false, 0
+	}
+	for _, o := range t.orders {
+		switch {
+		case o.Delivered:
+		case o.gone:
+			undelivered++
+		default:
+			return false, 0
+		}
+	}
+	return true, undelivered
+}
@@ -0,0 +1,46 @@
+package poller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func TestTracker(t *testing.T) {
+	var tr Tracker
+	at := time.Date(2025, 12, 20, 18, 0, 0, 0, time.UTC)
+	if done, _ := tr.Finished(); done {
+		t.Fatalf("empty tracker must not be finished")
+	}
+
+	ev := tr.Update("foodora", []provider.Order{{ID: "B", Status: "Cooking"}, {ID: "A", Status: "Received"}}, at)
+	if len(ev) != 2 || ev[0].OrderID != "A" || ev[0].From != "" || ev[0].To != "Received" || !ev[0].At.Equal(at) {
+		t.Fatalf("first=%+v", ev)
+	}
+	if ev := tr.Update("foodora", []provider.Order{{ID: "B", Status: "Cooking"}, {ID: "A", Status: "Received"}}, at); len(ev) != 0 {
+		t.Fatalf("unchanged poll produced %+v", ev)
+	}
+	// Other providers' polls never mark foodora orders gone.
+	if ev := tr.Update("glovo", nil, at); len(ev) != 0 {
+		t.Fatalf("glovo=%+v", ev)
+	}
+
+	ev = tr.Update("foodora", []provider.Order{{ID: "A", Status: "Delivered", Delivered: true}}, at)
+	if len(ev) != 2 || ev[0].From != "Received" || !ev[0].Delivered || !ev[1].Gone || ev[1].From != "Cooking" || ev[1].To != "" {
+		t.Fatalf("second=%+v", ev)
+	}
+	done, undelivered := tr.Finished()
+	if !done || undelivered != 1 {
+		t.Fatalf("done=%v undelivered=%d", done, undelivered)
+	}
+
+	// A gone order that comes back is active again.
+	ev = tr.Update("foodora", []provider.Order{{ID: "A", Status: "Delivered", Delivered: true}, {ID: "B", Status: "Cooking"}}, at)
+	if len(ev) != 1 || ev[0].OrderID != "B" || ev[0].Gone {
+		t.Fatalf("back=%+v", ev)
+	}
+	if done, _ := tr.Finished(); done {
+		t.Fatalf("B is active again")
+	}
+}
@@ -0,0 +1,174 @@
+// Package profiles resolves the header profile, i.e. the app identity (user
+// agent, app name, web build) a provider client presents. Built-in profiles
+// cover the known apps; the header_profiles config section overrides them
+// or adds new ones without waiting for a release when an app update changes
+// what the APIs expect.
+package profiles
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/glovo"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// Built-in profile names.
+const (
+	FoodoraAndroid = "foodora-android"
+	MjamAndroid    = "mjam-android"
+	GlovoWeb       = "glovo-web"
+)
+
+// Profile sources.
+const (
+	SourceBuiltin  = "builtin"
+	SourceConfig   = "config"
+	SourceOverride = "override" // config fields over a built-in profile
+)
+
+func builtin() map[string]config.HeaderProfile {
+	return map[string]config.HeaderProfile{
+		FoodoraAndroid: {Provider: provider.Foodora, FPAPIKey: "android"},
+		// From the at.mjam APKM (v25.3.0 / build 250300134).
+		MjamAndroid: {Provider: provider.Foodora, FPAPIKey: "android", AppName: "at.mjam", UserAgent: "Android-app-25.3.0(250300134)"},
+		GlovoWeb:    {Provider: provider.Glovo, AppVersion: glovo.DefaultAppVersion, ClientInfo: glovo.DefaultClientInfo},
+	}
+}
+
+type Profile struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	config.HeaderProfile
+}
+
+// Get returns the named profile with config fields layered over a built-in
+// profile of the same name.
+func Get(cfg *config.HeaderProfilesConfig, name string) (Profile, bool) {
+	base, isBuiltin := builtin()[name]
+	var own config.HeaderProfile
+	hasOwn := false
+	if cfg != nil {
+		own, hasOwn = cfg.Profiles[name]
+	}
+	switch {
+	case isBuiltin && hasOwn:
+		return Profile{Name: name, Source: SourceOverride, HeaderProfile: merge(base, own)}, true
+	case isBuiltin:
+		return Profile{Name: name, Source: SourceBuiltin, HeaderProfile: base}, true
+	case hasOwn:
+		return Profile{Name: name, Source: SourceConfig, HeaderProfile: merge(config.HeaderProfile{}, own)}, true
+	}
+	return Profile{}, false
+}
+
+func merge(base, over config.HeaderProfile) config.HeaderProfile {
+	for _, f := range []struct{ dst, src *string }{
+		{&base.Provider, &over.Provider},
+		{&base.UserAgent, &over.UserAgent},
+		{&base.AppName, &over.AppName},
+		{&base.FPAPIKey, &over.FPAPIKey},
+		{&base.AppVersion, &over.AppVersion},
+		{&base.ClientInfo, &over.ClientInfo},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+	if len(over.Headers) > 0 {
+		h := maps.Clone(base.Headers)
+		if h == nil {
+			h = map[string]string{}
+		}
+		maps.Copy(h, over.Headers)
+		base.Headers = h
+	}
+	return base
+}
+
+// List returns every built-in and configured profile, sorted by name.
+func List(cfg *config.HeaderProfilesConfig) []Profile {
+	names := map[string]bool{}
+	for name := range builtin() {
+		names[name] = true
+	}
+	if cfg != nil {
+		for name := range cfg.Profiles {
+			names[name] = true
+		}
+	}
+	out := make([]Profile, 0, len(names))
+	for name := range names {
+		p, _ := Get(cfg, name)
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Target is what rules are matched against.
+type Target struct {
+	Provider string
+	Country  string
+	Account  string
+}
+
+func matches(r config.HeaderProfileRule, t Target) bool {
+	return (r.Provider == "" || r.Provider == t.Provider) &&
+		(r.Country == "" || strings.EqualFold(r.Country, t.Country)) &&
+		(r.Account == "" || r.Account == t.Account)
+}
+
+// Select returns the profile of the first rule matching t (rules naming a
+// profile of another provider are skipped), else the fallback profile.
+func Select(cfg *config.HeaderProfilesConfig, t Target, fallback string) (Profile, error) {
+	if cfg != nil {
+		for _, r := range cfg.Rules {
+			if !matches(r, t) {
+				continue
+			}
+			p, ok := Get(cfg, r.Profile)
+			if !ok {
+				return Profile{}, fmt.Errorf("header profile %q not found", r.Profile)
+			}
+			if p.Provider != "" && p.Provider != t.Provider {
+				continue
+			}
+			return p, nil
+		}
+	}
+	p, ok := Get(cfg, fallback)
+	if !ok {
+		return Profile{}, fmt.Errorf("header profile %q not found", fallback)
+	}
+	return p, nil
+}
+
+// Supported reports whether provider name has header profiles.
+func Supported(name string) bool { return name == provider.Foodora || name == provider.Glovo }
+
+// Validate reports rules naming unknown profiles and profiles for a
+// provider without header profiles.
+func Validate(cfg *config.HeaderProfilesConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	for _, p := range List(cfg) {
+		if !Supported(p.Provider) {
+			return fmt.Errorf("config: header_profiles.profiles.%s: provider must be foodora or glovo, not %q", p.Name, p.Provider)
+		}
+	}
+	for i, r := range cfg.Rules {
+		p, ok := Get(cfg, r.Profile)
+		if !ok {
+			return fmt.Errorf("config: header_profiles.rules[%d]: unknown profile %q", i, r.Profile)
+		}
+		if r.Provider != "" && r.Provider != p.Provider {
+			return fmt.Errorf("config: header_profiles.rules[%d]: profile %q is for %s, not %s", i, r.Profile, p.Provider, r.Provider)
+		}
+	}
+	return nil
+}
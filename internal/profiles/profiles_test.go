@@ -0,0 +1,79 @@
+package profiles
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func TestGet_LayersConfigOverBuiltin(t *testing.T) {
+	cfg := &config.HeaderProfilesConfig{Profiles: map[string]config.HeaderProfile{
+		MjamAndroid: {UserAgent: "Android-app-26.1.0(260100001)", Headers: map[string]string{"X-Extra": "1"}},
+		"custom":    {Provider: provider.Foodora, AppName: "com.example"},
+	}}
+	p, ok := Get(cfg, MjamAndroid)
+	if !ok || p.Source != SourceOverride || p.UserAgent != "Android-app-26.1.0(260100001)" || p.AppName != "at.mjam" || p.Headers["X-Extra"] != "1" {
+		t.Fatalf("override: %#v", p)
+	}
+	if p, _ := Get(nil, MjamAndroid); p.Source != SourceBuiltin || p.UserAgent != "Android-app-25.3.0(250300134)" || p.Headers != nil {
+		t.Fatalf("builtin changed: %#v", p)
+	}
+	if _, ok := Get(cfg, "nope"); ok {
+		t.Fatalf("unknown profile found")
+	}
+
+	var names []string
+	for _, p := range List(cfg) {
+		names = append(names, p.Name+"/"+p.Source)
+	}
+	want := "custom/config foodora-android/builtin glovo-web/builtin mjam-android/override"
+	if got := strings.Join(names, " "); got != want {
+		t.Fatalf("list=%s", got)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	cfg := &config.HeaderProfilesConfig{
+		Profiles: map[string]config.HeaderProfile{"beta": {Provider: provider.Foodora, AppName: "beta"}},
+		Rules: []config.HeaderProfileRule{
+			{Account: "hu.fd-api.com/42", Profile: "beta"},
+			{Provider: provider.Foodora, Country: "at", Profile: MjamAndroid},
+		},
+	}
+	for _, tc := range []struct {
+		t    Target
+		want string
+	}{
+		{Target{Provider: provider.Foodora, Country: "HU", Account: "hu.fd-api.com/42"}, "beta"},
+		{Target{Provider: provider.Foodora, Country: "AT"}, MjamAndroid},
+		{Target{Provider: provider.Foodora, Country: "SE"}, FoodoraAndroid},
+		// beta is a foodora profile; the account rule does not apply to glovo.
+		{Target{Provider: provider.Glovo, Account: "hu.fd-api.com/42"}, GlovoWeb},
+	} {
+		fallback := FoodoraAndroid
+		if tc.t.Provider == provider.Glovo {
+			fallback = GlovoWeb
+		}
+		p, err := Select(cfg, tc.t, fallback)
+		if err != nil || p.Name != tc.want {
+			t.Fatalf("%+v: %s %v", tc.t, p.Name, err)
+		}
+	}
+	if _, err := Select(nil, Target{Provider: provider.Glovo}, "gone"); err == nil {
+		t.Fatalf("expected missing fallback error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(&config.HeaderProfilesConfig{Profiles: map[string]config.HeaderProfile{"x": {AppName: "a"}}}); err == nil {
+		t.Fatalf("expected missing provider error")
+	}
+	if err := Validate(&config.HeaderProfilesConfig{Profiles: map[string]config.HeaderProfile{"x": {Provider: provider.Deliveroo}}}); err == nil {
+		t.Fatalf("expected unsupported provider error")
+	}
+	if err := Validate(nil); err != nil {
+		t.Fatalf("nil: %v", err)
+	}
+}
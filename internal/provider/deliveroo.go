@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/deliveroo"
+)
+
+type deliverooProvider struct {
+	c *deliveroo.Client
+}
+
+func NewDeliveroo(c *deliveroo.Client) Provider {
+	return &deliverooProvider{c: c}
+}
+
+func (p *deliverooProvider) Name() string { return Deliveroo }
+
+func (p *deliverooProvider) Capabilities() Capability {
+	return CapHistory | CapActiveOrders | CapETA
+}
+
+func (p *deliverooProvider) History(ctx context.Context, req HistoryRequest) (HistoryPage, error) {
+	offset, err := offsetCursor(req.Cursor)
+	if err != nil {
+		return HistoryPage{}, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	resp, err := p.c.OrderHistory(ctx, deliveroo.OrderHistoryParams{Offset: offset, Limit: limit})
+	if err != nil {
+		return HistoryPage{}, err
+	}
+	page := HistoryPage{Orders: make([]Order, 0, len(resp.Orders))}
+	for _, o := range resp.Orders {
+		page.Orders = append(page.Orders, DeliverooOrder(o))
+	}
+	next := offset + len(resp.Orders)
+	if len(resp.Orders) == limit && (resp.Count <= 0 || next < resp.Count) {
+		page.Next = strconv.Itoa(next)
+	}
+	return page, nil
+}
+
+func (p *deliverooProvider) ActiveOrders(ctx context.Context) (ActiveOrders, error) {
+	resp, err := p.c.OrderHistory(ctx, deliveroo.OrderHistoryParams{State: "active"})
+	if err != nil {
+		return ActiveOrders{}, err
+	}
+	out := ActiveOrders{Orders: make([]Order, 0, len(resp.Orders))}
+	for _, o := range resp.Orders {
+		n := DeliverooOrder(o)
+		n.Active = !n.Delivered
+		out.Orders = append(out.Orders, n)
+	}
+	return out, nil
+}
+
+func (p *deliverooProvider) OrderDetail(context.Context, string) (Order, error) {
+	return Order{}, ErrUnsupported
+}
+
+func (p *deliverooProvider) Me(context.Context) (Account, error) {
+	return Account{}, ErrUnsupported
+}
+
+// DeliverooOrder normalizes a Deliveroo order-history entry.
+func DeliverooOrder(o deliveroo.Order) Order {
+	n := Order{
+		Provider: Deliveroo,
+		ID:       o.ID,
+		Status:   o.Status,
+		Currency: strings.ToUpper(o.CurrencyCode),
+		Raw:      o.Raw,
+	}
+	if n.ID == "" {
+		n.ID = string(o.OrderNumber)
+	}
+	if o.Restaurant != nil {
+		n.Vendor = o.Restaurant.Name
+	}
+	if o.Total != nil {
+		n.Total = *o.Total
+	}
+	if len(n.Raw) == 0 {
+		n.Raw, _ = json.Marshal(o)
+	}
+	n.Time = parseTime(o.SubmittedAt)
+	if n.Time.IsZero() {
+		n.Time = parseTime(o.DeliveredAt)
+	}
+	n.ETA = parseTime(o.EstimatedDeliveryAt)
+
+	switch strings.ToLower(o.Status) {
+	case "delivered", "completed":
+		n.Delivered = true
+	case "cancelled", "canceled", "rejected", "failed":
+	default:
+		n.Active = o.DeliveredAt == ""
+	}
+	return n
+}
+
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/deliveroo"
+)
+
+func TestDeliverooProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("state") == "active" {
+			_, _ = w.Write([]byte(`{"orders":[{"id":"live","status":"in_transit","estimated_delivery_at":"2025-12-20T12:30:00Z","restaurant":{"name":"Wok"}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"count":1,"orders":[{"id":"o1","order_number":123,"status":"delivered","submitted_at":"2025-12-20T12:00:00Z","total":18.5,"currency_code":"gbp","restaurant":{"name":"Wok"}}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := deliveroo.NewClient(deliveroo.ClientOptions{BaseURL: srv.URL, BearerToken: "tok"})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	p := NewDeliveroo(c)
+
+	page, err := p.History(context.Background(), HistoryRequest{Limit: 10})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if page.Next != "" || len(page.Orders) != 1 {
+		t.Fatalf("page=%+v", page)
+	}
+	o := page.Orders[0]
+	if o.ID != "o1" || o.Total != 18.5 || o.Currency != "GBP" || !o.Delivered || o.Active {
+		t.Fatalf("o=%+v", o)
+	}
+	if !o.Time.Equal(time.Date(2025, 12, 20, 12, 0, 0, 0, time.UTC)) || len(o.Raw) == 0 {
+		t.Fatalf("o=%+v", o)
+	}
+
+	active, err := p.ActiveOrders(context.Background())
+	if err != nil {
+		t.Fatalf("ActiveOrders: %v", err)
+	}
+	if len(active.Orders) != 1 || !active.Orders[0].Active || active.Orders[0].ETA.IsZero() {
+		t.Fatalf("active=%+v", active)
+	}
+
+	if _, err := p.OrderDetail(context.Background(), "o1"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("err=%v", err)
+	}
+}
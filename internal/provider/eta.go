@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ETALookup is implemented by providers with CapETALookup: their
+// active-orders list has no ETA, and fetching one costs a request per order.
+type ETALookup interface {
+	ETA(ctx context.Context, id string) (time.Time, error)
+}
+
+// WithETA makes ActiveOrders of a provider with CapETALookup fill Order.ETA,
+// asking for each active order at most once per ttl. Other providers are
+// returned as they are.
+func WithETA(p Provider, ttl time.Duration) Provider {
+	l, ok := p.(ETALookup)
+	if !ok {
+		return p
+	}
+	return &etaProvider{Provider: p, lookup: l, ttl: ttl, now: time.Now, cache: map[string]etaEntry{}}
+}
+
+type etaProvider struct {
+	Provider
+	lookup ETALookup
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]etaEntry
+}
+
+type etaEntry struct {
+	eta     time.Time
+	fetched time.Time
+}
+
+func (p *etaProvider) Capabilities() Capability {
+	c := p.Provider.Capabilities()
+	if c.Has(CapETALookup) {
+		c |= CapETA
+	}
+	return c
+}
+
+func (p *etaProvider) ActiveOrders(ctx context.Context) (ActiveOrders, error) {
+	out, err := p.Provider.ActiveOrders(ctx)
+	if err != nil || !p.Provider.Capabilities().Has(CapETALookup) {
+		return out, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	seen := map[string]bool{}
+	for i, o := range out.Orders {
+		if !o.Active || !o.ETA.IsZero() {
+			continue
+		}
+		seen[o.ID] = true
+		e, ok := p.cache[o.ID]
+		if !ok || p.now().Sub(e.fetched) >= p.ttl {
+			// A failed lookup only costs the ETA, not the order; it is
+			// retried after ttl like a successful one.
+			eta, _ := p.lookup.ETA(ctx, o.ID)
+			e = etaEntry{eta: eta, fetched: p.now()}
+			p.cache[o.ID] = e
+		}
+		out.Orders[i].ETA = e.eta
+	}
+	for id := range p.cache {
+		if !seen[id] {
+			delete(p.cache, id)
+		}
+	}
+	return out, nil
+}
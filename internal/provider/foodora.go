@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/foodora"
+)
+
+type foodoraProvider struct {
+	c        *foodora.Client
+	currency string
+}
+
+// NewFoodora adapts a foodora client. currency is the ISO code of the
+// configured country (the API reports amounts without one).
+func NewFoodora(c *foodora.Client, currency string) Provider {
+	return &foodoraProvider{c: c, currency: currency}
+}
+
+func (p *foodoraProvider) Name() string { return Foodora }
+
+func (p *foodoraProvider) Capabilities() Capability {
+	return CapHistory | CapActiveOrders | CapOrderDetail | CapETALookup
+}
+
+func (p *foodoraProvider) History(ctx context.Context, req HistoryRequest) (HistoryPage, error) {
+	offset, err := offsetCursor(req.Cursor)
+	if err != nil {
+		return HistoryPage{}, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	resp, err := p.c.OrderHistoryRaw(ctx, foodora.OrderHistoryRequest{Offset: offset, Limit: limit})
+	if err != nil {
+		return HistoryPage{}, err
+	}
+
+	page := HistoryPage{Orders: make([]Order, 0, len(resp.Data.Items))}
+	for _, raw := range resp.Data.Items {
+		o, err := p.historyOrder(raw)
+		if err != nil {
+			return HistoryPage{}, err
+		}
+		page.Orders = append(page.Orders, o)
+	}
+
+	next := offset + len(resp.Data.Items)
+	total := int(resp.Data.TotalCount)
+	if len(resp.Data.Items) == limit && (total <= 0 || next < total) {
+		page.Next = strconv.Itoa(next)
+	}
+	return page, nil
+}
+
+func (p *foodoraProvider) ActiveOrders(ctx context.Context) (ActiveOrders, error) {
+	resp, err := p.c.ActiveOrders(ctx)
+	if err != nil {
+		return ActiveOrders{}, err
+	}
+	out := ActiveOrders{Orders: make([]Order, 0, len(resp.Data.ActiveOrders))}
+	if resp.Data.PollInSeconds != nil && *resp.Data.PollInSeconds > 0 {
+		out.PollInterval = time.Duration(*resp.Data.PollInSeconds) * time.Second
+	}
+	for _, o := range resp.Data.ActiveOrders {
+		raw, _ := json.Marshal(o)
+		n := Order{
+			Provider:  Foodora,
+			ID:        o.Code,
+			Vendor:    o.Vendor.Name,
+			Status:    FoodoraActiveStatus(o),
+			Active:    !o.IsDelivered,
+			Delivered: o.IsDelivered,
+			Currency:  p.currency,
+			Raw:       raw,
+		}
+		out.Orders = append(out.Orders, n)
+	}
+	return out, nil
+}
+
+// ETA is the start of the promised delivery window from the order's
+// tracking view (the active-orders list has none).
+func (p *foodoraProvider) ETA(ctx context.Context, code string) (time.Time, error) {
+	resp, err := p.c.OrderStatus(ctx, code)
+	if err != nil || resp.Data.ETA == nil {
+		return time.Time{}, err
+	}
+	if !resp.Data.ETA.From.IsZero() {
+		return resp.Data.ETA.From.Time, nil
+	}
+	return resp.Data.ETA.To.Time, nil
+}
+
+func (p *foodoraProvider) OrderDetail(ctx context.Context, id string) (Order, error) {
+	resp, err := p.c.OrderHistoryByCode(ctx, foodora.OrderHistoryByCodeRequest{OrderCode: id})
+	if err != nil {
+		return Order{}, err
+	}
+	if len(resp.Data.Items) == 0 {
+		return Order{}, fmt.Errorf("foodora: order %s not found", id)
+	}
+	return p.historyOrder(resp.Data.Items[0])
+}
+
+func (p *foodoraProvider) Me(context.Context) (Account, error) {
+	return Account{}, ErrUnsupported
+}
+
+func (p *foodoraProvider) historyOrder(raw map[string]any) (Order, error) {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return Order{}, err
+	}
+	var item foodora.OrderHistoryItem
+	if err := json.Unmarshal(b, &item); err != nil {
+		// Keep the order even if a nested field drifted; the raw payload has everything.
+		item = foodora.OrderHistoryItem{}
+		var head struct {
+			OrderCode string `json:"order_code"`
+		}
+		if json.Unmarshal(b, &head) != nil || head.OrderCode == "" {
+			return Order{}, fmt.Errorf("foodora: decode history item: %w", err)
+		}
+		item.OrderCode = head.OrderCode
+	}
+
+	o := Order{
+		Provider: Foodora,
+		ID:       item.OrderCode,
+		Total:    item.TotalValue,
+		Currency: p.currency,
+		Raw:      b,
+	}
+	if item.Vendor != nil {
+		o.Vendor = item.Vendor.Name
+	}
+	if item.CurrentStatus != nil {
+		o.Status = foodoraHistoryStatus(item.CurrentStatus)
+		o.Delivered = isDeliveredStatus(o.Status)
+	}
+	if item.ConfirmedDeliveryTime != nil {
+		o.Time = item.ConfirmedDeliveryTime.Date.Time
+	}
+	for _, pr := range item.Products {
+		o.Items = append(o.Items, Item{Name: pr.Name, Quantity: int(pr.Quantity), Price: pr.TotalPrice})
+	}
+	return o, nil
+}
+
+// FoodoraActiveStatus is the human status line of an active order.
+func FoodoraActiveStatus(o foodora.ActiveOrder) string {
+	return o.Status.Text()
+}
+
+func foodoraHistoryStatus(s *foodora.OrderHistoryStatus) string {
+	if s.Message != "" {
+		return s.Message
+	}
+	if s.Code != "" {
+		return string(s.Code)
+	}
+	return string(s.InternalStatusCode)
+}
+
+func isDeliveredStatus(s string) bool {
+	s = strings.ToLower(s)
+	return strings.Contains(s, "delivered") || strings.Contains(s, "picked up") || strings.Contains(s, "completed")
+}
+
+func offsetCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(cursor)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid history cursor " + strconv.Quote(cursor))
+	}
+	return n, nil
+}
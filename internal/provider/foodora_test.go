@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/foodora"
+)
+
+func newFoodoraTestProvider(t *testing.T, h http.HandlerFunc) Provider {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c, err := foodora.New(foodora.Options{BaseURL: srv.URL + "/", AccessToken: "tok"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return NewFoodora(c, "EUR")
+}
+
+func TestFoodoraHistoryPaging(t *testing.T) {
+	p := newFoodoraTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orders/order_history" {
+			t.Fatalf("path=%s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("offset") {
+		case "0":
+			_, _ = w.Write([]byte(`{"status":200,"data":{"total_count":3,"items":[
+				{"order_code":"A","current_status":{"message":"Delivered"},"confirmed_delivery_time":{"date":"2025-12-20T12:00:00Z"},"vendor":{"name":"Pho"},"total_value":12.5,"order_products":[{"name":"Soup","quantity":"2","total_price":10}],"extra_field":true},
+				{"order_code":"B","vendor":{"name":"Pizza"},"total_value":20}
+			]}}`))
+		case "2":
+			_, _ = w.Write([]byte(`{"status":200,"data":{"total_count":3,"items":[{"order_code":"C","total_value":5}]}}`))
+		default:
+			t.Fatalf("unexpected offset %q", r.URL.Query().Get("offset"))
+		}
+	})
+
+	if !p.Capabilities().Has(CapHistory|CapOrderDetail) || p.Capabilities().Has(CapMe) {
+		t.Fatalf("caps=%b", p.Capabilities())
+	}
+
+	orders, err := Collect(context.Background(), p, 0, 2)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(orders) != 3 {
+		t.Fatalf("orders=%d", len(orders))
+	}
+	a := orders[0]
+	if a.Provider != Foodora || a.ID != "A" || a.Vendor != "Pho" || a.Total != 12.5 || a.Currency != "EUR" {
+		t.Fatalf("a=%+v", a)
+	}
+	if !a.Delivered || !a.Time.Equal(time.Date(2025, 12, 20, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("a=%+v", a)
+	}
+	if len(a.Items) != 1 || a.Items[0].Quantity != 2 || a.Items[0].Name != "Soup" {
+		t.Fatalf("items=%+v", a.Items)
+	}
+	if len(a.Raw) == 0 || !strings.Contains(string(a.Raw), "extra_field") {
+		t.Fatalf("raw=%s", a.Raw)
+	}
+
+	if _, err := p.Me(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Me err=%v", err)
+	}
+}
+
+func TestFoodoraActiveOrders(t *testing.T) {
+	lookups := 0
+	p := newFoodoraTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/tracking/active-orders":
+			_, _ = w.Write([]byte(`{"status":200,"data":{"count":1,"poll_in_sec":15,"active_orders":[{"code":"OC-1","is_delivered":false,"vendor":{"name":"Vendor"},"status_messages":{"titles":[{"name":"Accepted"},{"name":"Cooking","active":true}]}}]}}`))
+		case "/tracking/orders/OC-1":
+			lookups++
+			_, _ = w.Write([]byte(`{"status":200,"data":{"order_code":"OC-1","status_messages":{"subtitle":"Cooking"},"eta":{"from":"2025-12-20T12:10:00Z","to":"2025-12-20T12:20:00Z"}}}`))
+		default:
+			t.Fatalf("path=%s", r.URL.Path)
+		}
+	})
+	if p.Capabilities().Has(CapETA) || !p.Capabilities().Has(CapETALookup) {
+		t.Fatalf("caps=%b", p.Capabilities())
+	}
+	got, err := p.ActiveOrders(context.Background())
+	if err != nil {
+		t.Fatalf("ActiveOrders: %v", err)
+	}
+	if got.PollInterval != 15*time.Second {
+		t.Fatalf("poll=%s", got.PollInterval)
+	}
+	if len(got.Orders) != 1 || got.Orders[0].Status != "Cooking" || !got.Orders[0].Active {
+		t.Fatalf("orders=%+v", got.Orders)
+	}
+	if !got.Orders[0].ETA.IsZero() || lookups != 0 {
+		t.Fatalf("ETA looked up without WithETA: eta=%v lookups=%d", got.Orders[0].ETA, lookups)
+	}
+
+	now := time.Date(2025, 12, 20, 12, 0, 0, 0, time.UTC)
+	wp := WithETA(p, time.Minute)
+	wp.(*etaProvider).now = func() time.Time { return now }
+	if !wp.Capabilities().Has(CapETA) {
+		t.Fatalf("caps=%b", wp.Capabilities())
+	}
+	for range 2 {
+		got, err = wp.ActiveOrders(context.Background())
+		if err != nil {
+			t.Fatalf("ActiveOrders: %v", err)
+		}
+		if eta := got.Orders[0].ETA; !eta.Equal(time.Date(2025, 12, 20, 12, 10, 0, 0, time.UTC)) {
+			t.Fatalf("eta=%v", eta)
+		}
+	}
+	if lookups != 1 {
+		t.Fatalf("lookups=%d, want one per ttl", lookups)
+	}
+	now = now.Add(time.Minute)
+	if _, err := wp.ActiveOrders(context.Background()); err != nil || lookups != 2 {
+		t.Fatalf("lookups=%d err=%v after ttl", lookups, err)
+	}
+}
+
+func TestFoodoraOrderDetail(t *testing.T) {
+	p := newFoodoraTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("order_code") != "X" {
+			_, _ = w.Write([]byte(`{"status":200,"data":{"items":[]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":200,"data":{"items":[{"order_code":"X","vendor":{"name":"V"}}]}}`))
+	})
+	o, err := p.OrderDetail(context.Background(), "X")
+	if err != nil || o.Vendor != "V" {
+		t.Fatalf("o=%+v err=%v", o, err)
+	}
+	if _, err := p.OrderDetail(context.Background(), "missing"); err == nil {
+		t.Fatalf("expected not found")
+	}
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/steipete/ordercli/internal/glovo"
+)
+
+func TestGlovoProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v3/customer/orders-list":
+			if r.URL.Query().Get("offset") == "2" {
+				_, _ = w.Write([]byte(`{"pagination":{"next":null},"orders":[{"orderId":3,"layoutType":"INACTIVE_ORDER","style":"CANCELLED","footer":{"right":{"type":"TEXT","data":"Cancelled"}}}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"pagination":{"currentLimit":2,"next":"2"},"orders":[
+				{"orderId":1,"layoutType":"ACTIVE_ORDER","content":{"title":"Burger Bar","body":[{"type":"TEXT","data":"2x Burger\nFries"}]},"footer":{"left":{"type":"TEXT","data":"12,34 €"}},"unknown":1},
+				{"orderId":2,"layoutType":"INACTIVE_ORDER","content":{"title":"Sushi"}}
+			]}`))
+		case "/v3/me":
+			_, _ = w.Write([]byte(`{"id":42,"name":"Ana","email":"ana@example.com"}`))
+		default:
+			t.Fatalf("path=%s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := glovo.New(glovo.Options{BaseURL: srv.URL, AccessToken: "tok"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	p := NewGlovo(c)
+
+	orders, err := Collect(context.Background(), p, 0, 2)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(orders) != 3 {
+		t.Fatalf("orders=%+v", orders)
+	}
+	o := orders[0]
+	if o.ID != "1" || o.Vendor != "Burger Bar" || o.Total != 12.34 || o.Currency != "EUR" || !o.Active {
+		t.Fatalf("o=%+v", o)
+	}
+	if len(o.Items) != 2 || o.Items[0].Quantity != 2 || o.Items[0].Name != "Burger" || o.Items[1].Name != "Fries" {
+		t.Fatalf("items=%+v", o.Items)
+	}
+	if !strings.Contains(string(o.Raw), `"unknown":1`) {
+		t.Fatalf("raw=%s", o.Raw)
+	}
+	if o.Delivered || !o.Time.IsZero() {
+		t.Fatalf("active order: %+v", o)
+	}
+	if orders[1].Active || !orders[1].Delivered {
+		t.Fatalf("past order: %+v", orders[1])
+	}
+	if orders[2].Delivered || orders[2].Status != "CANCELLED" {
+		t.Fatalf("cancelled order: %+v", orders[2])
+	}
+
+	me, err := p.Me(context.Background())
+	if err != nil || me.ID != "42" || me.Email != "ana@example.com" {
+		t.Fatalf("me=%+v err=%v", me, err)
+	}
+
+	if _, err := p.OrderDetail(context.Background(), "abc"); err == nil {
+		t.Fatalf("expected invalid id error")
+	}
+}
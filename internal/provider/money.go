@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var currencySymbols = []struct {
+	Symbol string
+	Code   string
+}{
+	{"€", "EUR"},
+	{"£", "GBP"},
+	{"$", "USD"},
+	{"zł", "PLN"},
+	{"kč", "CZK"},
+	{"ft", "HUF"},
+	{"kr", "SEK"},
+}
+
+// ParseMoney parses display prices such as "12,34 €", "€12.34", "1.234,50 EUR"
+// or "£9.99" into an amount and ISO currency code (empty when unknown).
+func ParseMoney(s string) (float64, string, bool) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\u00a0", " "))
+	start := strings.IndexFunc(s, unicode.IsDigit)
+	if start < 0 {
+		return 0, "", false
+	}
+	end := start
+	for end < len(s) {
+		c := s[end]
+		if c >= '0' && c <= '9' || c == '.' || c == ',' {
+			end++
+			continue
+		}
+		// "1 234,50": a single space between digit groups.
+		if c == ' ' && end+1 < len(s) && s[end+1] >= '0' && s[end+1] <= '9' {
+			end++
+			continue
+		}
+		break
+	}
+
+	amount, ok := parseAmount(strings.ReplaceAll(s[start:end], " ", ""))
+	if !ok {
+		return 0, "", false
+	}
+	prefix := s[:start]
+	if strings.Contains(prefix, "-") {
+		amount = -amount
+	}
+	return amount, currencyCode(strings.Trim(prefix, "- ") + s[end:]), true
+}
+
+func parseAmount(num string) (float64, bool) {
+	num = strings.TrimRight(num, ".,")
+	lastDot := strings.LastIndex(num, ".")
+	lastComma := strings.LastIndex(num, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// Whichever separator comes last is the decimal one.
+		if lastComma > lastDot {
+			num = strings.ReplaceAll(num, ".", "")
+			num = strings.Replace(num, ",", ".", 1)
+		} else {
+			num = strings.ReplaceAll(num, ",", "")
+		}
+	case strings.Count(num, ",") > 1:
+		num = strings.ReplaceAll(num, ",", "")
+	case lastComma >= 0:
+		num = strings.Replace(num, ",", ".", 1)
+	case strings.Count(num, ".") > 1:
+		num = strings.ReplaceAll(num, ".", "")
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+func currencyCode(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	if isISOCode(s) {
+		return s
+	}
+	lower := strings.ToLower(s)
+	for _, c := range currencySymbols {
+		if strings.Contains(lower, c.Symbol) {
+			return c.Code
+		}
+	}
+	return ""
+}
+
+func isISOCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
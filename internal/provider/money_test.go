@@ -0,0 +1,33 @@
+package provider
+
+import "testing"
+
+func TestParseMoney(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		in       string
+		amount   float64
+		currency string
+	}{
+		{"12,34 €", 12.34, "EUR"},
+		{"€12.34", 12.34, "EUR"},
+		{"1.234,50 EUR", 1234.5, "EUR"},
+		{"1,234.50 USD", 1234.5, "USD"},
+		{"£9.99", 9.99, "GBP"},
+		{"1 990 Ft", 1990, "HUF"},
+		{"129 kr", 129, "SEK"},
+		{"-3,00 €", -3, "EUR"},
+		{"7.5", 7.5, ""},
+	}
+	for _, tc := range cases {
+		amount, currency, ok := ParseMoney(tc.in)
+		if !ok || amount != tc.amount || currency != tc.currency {
+			t.Fatalf("ParseMoney(%q)=%v %q %v, want %v %q", tc.in, amount, currency, ok, tc.amount, tc.currency)
+		}
+	}
+
+	if _, _, ok := ParseMoney("free"); ok {
+		t.Fatalf("expected no amount")
+	}
+}
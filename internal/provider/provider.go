@@ -0,0 +1,132 @@
+// Package provider puts the foodora, Deliveroo and Glovo clients behind one
+// interface so callers can treat "an order" the same regardless of where it
+// came from.
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	Foodora   = "foodora"
+	Deliveroo = "deliveroo"
+	Glovo     = "glovo"
+)
+
+// Names lists the supported providers in display order.
+var Names = []string{Foodora, Deliveroo, Glovo}
+
+// Capability is a set of optional Provider features.
+type Capability uint
+
+const (
+	CapHistory Capability = 1 << iota
+	CapActiveOrders
+	CapOrderDetail
+	CapMe
+	// CapETA: ActiveOrders fills Order.ETA for orders on their way.
+	CapETA
+	// CapETALookup: the provider implements ETALookup; see WithETA.
+	CapETALookup
+)
+
+func (c Capability) Has(want Capability) bool { return c&want == want }
+
+// ErrUnsupported is returned by methods whose capability the provider lacks.
+var ErrUnsupported = errors.New("not supported by this provider")
+
+type Provider interface {
+	Name() string
+	Capabilities() Capability
+	History(ctx context.Context, req HistoryRequest) (HistoryPage, error)
+	ActiveOrders(ctx context.Context) (ActiveOrders, error)
+	OrderDetail(ctx context.Context, id string) (Order, error)
+	Me(ctx context.Context) (Account, error)
+}
+
+// HistoryRequest asks for one page of past orders. Cursor is opaque; pass ""
+// for the first page and HistoryPage.Next afterwards.
+type HistoryRequest struct {
+	Cursor string
+	Limit  int
+}
+
+type HistoryPage struct {
+	Orders []Order
+	// Next is the cursor for the following page, or "" when there is none.
+	Next string
+}
+
+type ActiveOrders struct {
+	Orders []Order
+	// PollInterval is the provider's hint for the next poll; zero means no hint.
+	PollInterval time.Duration
+}
+
+type Account struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// Order is the provider-neutral view of an order.
+type Order struct {
+	Provider  string          `json:"provider"`
+	ID        string          `json:"id"`
+	Vendor    string          `json:"vendor,omitempty"`
+	Status    string          `json:"status,omitempty"`
+	Active    bool            `json:"active"`
+	Delivered bool            `json:"delivered"`
+	Time      time.Time       `json:"time,omitzero"`
+	ETA       time.Time       `json:"eta,omitzero"`
+	Total     float64         `json:"total,omitempty"`
+	Currency  string          `json:"currency,omitempty"`
+	Items     []Item          `json:"items,omitempty"`
+	Raw       json.RawMessage `json:"raw,omitempty"`
+}
+
+// Cancelled reports whether the order's status says it was cancelled,
+// rejected or refunded, so it is no spending.
+func (o Order) Cancelled() bool {
+	s := strings.ToLower(o.Status)
+	return strings.Contains(s, "cancel") || strings.Contains(s, "reject") || strings.Contains(s, "refund")
+}
+
+type Item struct {
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity,omitempty"`
+	Price    float64 `json:"price,omitempty"`
+}
+
+// Collect pages through p's history until limit orders were seen or the
+// provider runs out of pages. limit <= 0 means no limit.
+func Collect(ctx context.Context, p Provider, limit, pageSize int) ([]Order, error) {
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	var out []Order
+	cursor := ""
+	for limit <= 0 || len(out) < limit {
+		reqLimit := pageSize
+		if limit > 0 {
+			reqLimit = min(pageSize, limit-len(out))
+		}
+		page, err := p.History(ctx, HistoryRequest{Cursor: cursor, Limit: reqLimit})
+		if err != nil {
+			return out, err
+		}
+		out = append(out, page.Orders...)
+		if page.Next == "" || page.Next == cursor || len(page.Orders) == 0 {
+			break
+		}
+		cursor = page.Next
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
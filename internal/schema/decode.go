@@ -0,0 +1,476 @@
+// Package schema decodes provider API responses into their Go models in a
+// single pass and reports how they drift: fields the API sends that no
+// model field takes, values of an unexpected JSON type, and model fields the
+// API stopped sending. A Recorder keeps the drift per endpoint across runs
+// for `ordercli doctor schema`.
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type Kind string
+
+const (
+	// Unknown: a field in the response that no model field takes.
+	Unknown Kind = "unknown"
+	// Mismatch: a value whose JSON type the model field cannot hold.
+	Mismatch Kind = "type_mismatch"
+	// Missing: a model field without omitempty that the response lacks.
+	Missing Kind = "missing"
+)
+
+// Drift is one difference between a response and its model. Paths use dots
+// for object keys, [] for array elements and * for map values:
+// "items[].vendor.name".
+type Drift struct {
+	Kind Kind   `json:"kind"`
+	Path string `json:"path"`
+	// Want and Got are JSON types, for mismatches.
+	Want string `json:"want,omitempty"`
+	Got  string `json:"got,omitempty"`
+}
+
+var (
+	jsonUnmarshaler = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// Decode reads one JSON value from r into v, which must be a non-nil
+// pointer, parsing the input once. Unknown fields are skipped and reported
+// instead of failing the decode. A value of the wrong JSON type is
+// reported, left zero and, as with json.Unmarshal, returned as a
+// *json.UnmarshalTypeError once the rest is decoded. Read and syntax errors
+// stop the decode.
+//
+// Types with their own UnmarshalJSON (flexible numbers, timestamps,
+// json.RawMessage) and interface fields are decoded by encoding/json and
+// not looked into.
+func Decode(r io.Reader, v any) ([]Drift, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil, fmt.Errorf("schema: decode into non-pointer %T", v)
+	}
+	src := &source{r: r}
+	d := &decoder{dec: json.NewDecoder(src), src: src, seen: map[Drift]bool{}}
+	if err := d.value("", rv.Elem()); err != nil {
+		return d.sorted(), err
+	}
+	if _, err := d.dec.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("schema: unexpected data after top-level value")
+		}
+		return d.sorted(), err
+	}
+	return d.sorted(), d.err
+}
+
+type decoder struct {
+	dec   *json.Decoder
+	src   *source
+	drift []Drift
+	seen  map[Drift]bool // array elements report each drift once
+	// err is the first type mismatch; decoding goes on past it.
+	err error
+}
+
+func (d *decoder) add(x Drift) {
+	if !d.seen[x] {
+		d.seen[x] = true
+		d.drift = append(d.drift, x)
+	}
+}
+
+func (d *decoder) sorted() []Drift {
+	sort.Slice(d.drift, func(i, j int) bool {
+		if d.drift[i].Path != d.drift[j].Path {
+			return d.drift[i].Path < d.drift[j].Path
+		}
+		return d.drift[i].Kind < d.drift[j].Kind
+	})
+	return d.drift
+}
+
+func (d *decoder) mismatch(path string, t reflect.Type, want, got string) {
+	d.add(Drift{Kind: Mismatch, Path: path, Want: want, Got: got})
+	if d.err == nil {
+		d.err = &json.UnmarshalTypeError{Value: got, Type: t, Field: path}
+	}
+}
+
+// value decodes the next JSON value into v (settable).
+func (d *decoder) value(path string, v reflect.Value) error {
+	if leaf(v.Type()) {
+		return d.leaf(path, v)
+	}
+	tok, err := d.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil { // null leaves v as it is, like encoding/json
+		return nil
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if tok != json.Delim('{') {
+			d.mismatch(path, v.Type(), "object", tokenType(tok))
+			return d.skipRest(tok)
+		}
+		return d.object(path, v)
+	case reflect.Map:
+		if tok != json.Delim('{') {
+			d.mismatch(path, v.Type(), "object", tokenType(tok))
+			return d.skipRest(tok)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for d.dec.More() {
+			key, err := d.dec.Token()
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(join(path, "*"), elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key.(string)).Convert(v.Type().Key()), elem)
+		}
+		_, err := d.dec.Token() // }
+		return err
+	default: // slice
+		if tok != json.Delim('[') {
+			d.mismatch(path, v.Type(), "array", tokenType(tok))
+			return d.skipRest(tok)
+		}
+		s := reflect.MakeSlice(v.Type(), 0, 0)
+		for d.dec.More() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(path+"[]", elem); err != nil {
+				return err
+			}
+			s = reflect.Append(s, elem)
+		}
+		v.Set(s)
+		_, err := d.dec.Token() // ]
+		return err
+	}
+}
+
+func (d *decoder) object(path string, v reflect.Value) error {
+	fields := fieldsOf(v.Type())
+	present := map[string]bool{}
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+		f, ok := lookup(fields, key)
+		if !ok {
+			d.add(Drift{Kind: Unknown, Path: join(path, key)})
+			var skip json.RawMessage
+			if err := d.dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+		present[f.name] = true
+		fv, err := fieldByIndex(v, f.index)
+		if err != nil {
+			return err
+		}
+		if f.quoted {
+			err = d.quoted(join(path, key), fv)
+		} else {
+			err = d.value(join(path, key), fv)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, f := range fields {
+		if !f.omitempty && !present[f.name] {
+			d.add(Drift{Kind: Missing, Path: join(path, f.name)})
+		}
+	}
+	_, err := d.dec.Token() // }
+	return err
+}
+
+// leaf lets encoding/json decode a scalar, a custom type or an interface.
+func (d *decoder) leaf(path string, v reflect.Value) error {
+	err := d.dec.Decode(v.Addr().Interface())
+	var te *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &te):
+		d.add(Drift{Kind: Mismatch, Path: path, Want: jsonKind(v.Type()), Got: valueType(te.Value)})
+		if d.err == nil {
+			te.Field = path
+			d.err = te
+		}
+		return nil
+	case d.src.err != nil:
+		return d.src.err
+	case errors.As(err, new(*json.SyntaxError)), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return err
+	}
+	// An UnmarshalJSON error; the value was consumed, so go on.
+	if d.err == nil {
+		d.err = fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// quoted decodes a field tagged ",string": a JSON string holding the value.
+func (d *decoder) quoted(path string, v reflect.Value) error {
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		d.mismatch(path, v.Type(), "string", rawType(raw))
+		return nil
+	}
+	if err := json.Unmarshal([]byte(s), v.Addr().Interface()); err != nil && d.err == nil {
+		d.err = fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// skipRest consumes the rest of a value whose first token was tok.
+func (d *decoder) skipRest(tok json.Token) error {
+	if tok != json.Delim('{') && tok != json.Delim('[') {
+		return nil
+	}
+	for depth := 1; depth > 0; {
+		t, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+// source remembers read errors, which encoding/json passes through as they
+// are and which end the decode.
+type source struct {
+	r   io.Reader
+	err error
+}
+
+func (s *source) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil && err != io.EOF {
+		s.err = err
+	}
+	return n, err
+}
+
+// leaf reports types decoded as a whole by encoding/json.
+func leaf(t reflect.Type) bool {
+	for ; t.Kind() == reflect.Pointer; t = t.Elem() {
+		if custom(t) {
+			return true
+		}
+	}
+	if custom(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return false
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8 // base64
+	case reflect.Map:
+		return t.Key().Kind() != reflect.String
+	}
+	return true
+}
+
+func custom(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+	return t.Implements(jsonUnmarshaler) || p.Implements(jsonUnmarshaler) ||
+		t.Implements(textUnmarshaler) || p.Implements(textUnmarshaler)
+}
+
+func jsonKind(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return "array"
+	case reflect.Interface:
+		return "any"
+	}
+	return "number"
+}
+
+// valueType maps json.UnmarshalTypeError.Value to a JSON type; it holds
+// "number 1.5" for numbers that do not fit.
+func valueType(v string) string {
+	switch {
+	case v == "bool":
+		return "boolean"
+	case strings.HasPrefix(v, "number"):
+		return "number"
+	}
+	return v
+}
+
+func tokenType(tok json.Token) string {
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			return "object"
+		}
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return "number"
+}
+
+func rawType(raw json.RawMessage) string {
+	s := strings.TrimSpace(string(raw))
+	switch {
+	case s == "":
+		return "null"
+	case s[0] == '{':
+		return "object"
+	case s[0] == '[':
+		return "array"
+	case s[0] == '"':
+		return "string"
+	case s == "true" || s == "false":
+		return "boolean"
+	case s == "null":
+		return "null"
+	}
+	return "number"
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+type field struct {
+	name      string
+	index     []int
+	omitempty bool
+	quoted    bool // ",string" option
+}
+
+// fieldsOf lists the JSON fields of a struct the way encoding/json sees
+// them, including promoted fields of embedded structs.
+func fieldsOf(t reflect.Type) []field {
+	var out []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			et := sf.Type
+			if et.Kind() == reflect.Pointer {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct && !custom(et) {
+				for _, f := range fieldsOf(et) {
+					f.index = append([]int{i}, f.index...)
+					out = append(out, f)
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		opts = "," + opts + ","
+		out = append(out, field{
+			name:      name,
+			index:     []int{i},
+			omitempty: strings.Contains(opts, ",omitempty,") || strings.Contains(opts, ",omitzero,"),
+			quoted:    strings.Contains(opts, ",string,"),
+		})
+	}
+	return out
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex, allocating nil embedded
+// pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("schema: cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// lookup matches a key like encoding/json: exact name first, then case
+// insensitively.
+func lookup(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
@@ -0,0 +1,208 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report is what a Recorder keeps on disk.
+type Report struct {
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+type Endpoint struct {
+	Provider string `json:"provider"`
+	// Name is the method and path with ids replaced: "GET tracking/orders/{id}".
+	Name string `json:"endpoint"`
+	// Type is the Go type the responses were decoded into.
+	Type     string    `json:"type"`
+	Findings []Finding `json:"findings"`
+}
+
+type Finding struct {
+	Drift
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Recorder collects drift per endpoint and writes it to Path whenever a
+// response drifts. It is safe for concurrent use; a nil Recorder ignores
+// everything.
+type Recorder struct {
+	// Path is the JSON file findings are kept in ("" keeps them in memory).
+	Path string
+	// Log gets a line for every endpoint showing new drift (nil: silent).
+	Log io.Writer
+
+	mu     sync.Mutex
+	loaded bool
+	report Report
+}
+
+func NewRecorder(path string, log io.Writer) *Recorder {
+	return &Recorder{Path: path, Log: log}
+}
+
+// Record keeps the drift Decode found in a response of provider's
+// method+path, decoded into v.
+func (r *Recorder) Record(provider, method, path string, v any, drift []Drift) {
+	if r == nil || len(drift) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.loaded {
+		// A damaged file only loses history; start over.
+		r.report, _ = Load(r.Path)
+		r.loaded = true
+	}
+	name := EndpointName(method, path)
+	added := r.report.add(provider, name, typeName(v), drift, time.Now().UTC())
+	if added > 0 && r.Log != nil {
+		fmt.Fprintf(r.Log, "schema drift: %s %s: %d new finding(s) (see `ordercli doctor schema`)\n", provider, name, added)
+	}
+	if r.Path != "" {
+		if err := save(r.Path, r.report); err != nil && r.Log != nil {
+			fmt.Fprintf(r.Log, "schema drift: %v\n", err)
+		}
+	}
+}
+
+func (rep *Report) add(provider, name, typ string, drift []Drift, now time.Time) (added int) {
+	var ep *Endpoint
+	for i := range rep.Endpoints {
+		e := &rep.Endpoints[i]
+		if e.Provider == provider && e.Name == name && e.Type == typ {
+			ep = e
+			break
+		}
+	}
+	if ep == nil {
+		rep.Endpoints = append(rep.Endpoints, Endpoint{Provider: provider, Name: name, Type: typ})
+		ep = &rep.Endpoints[len(rep.Endpoints)-1]
+	}
+	idx := map[Drift]int{}
+	for i, f := range ep.Findings {
+		idx[f.Drift] = i
+	}
+	for _, d := range drift {
+		if i, ok := idx[d]; ok {
+			ep.Findings[i].Count++
+			ep.Findings[i].LastSeen = now
+			continue
+		}
+		ep.Findings = append(ep.Findings, Finding{Drift: d, Count: 1, FirstSeen: now, LastSeen: now})
+		added++
+	}
+	rep.sort()
+	return added
+}
+
+// kindOrder lists the most actionable drift first.
+var kindOrder = map[Kind]int{Mismatch: 0, Missing: 1, Unknown: 2}
+
+func (rep *Report) sort() {
+	sort.Slice(rep.Endpoints, func(i, j int) bool {
+		a, b := rep.Endpoints[i], rep.Endpoints[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Type < b.Type
+	})
+	for _, e := range rep.Endpoints {
+		sort.Slice(e.Findings, func(i, j int) bool {
+			a, b := e.Findings[i], e.Findings[j]
+			if a.Kind != b.Kind {
+				return kindOrder[a.Kind] < kindOrder[b.Kind]
+			}
+			return a.Path < b.Path
+		})
+	}
+}
+
+// Load reads a report; a missing file is an empty report.
+func Load(path string) (Report, error) {
+	if path == "" {
+		return Report{}, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Report{}, nil
+	}
+	if err != nil {
+		return Report{}, err
+	}
+	var rep Report
+	if err := json.Unmarshal(b, &rep); err != nil {
+		return Report{}, fmt.Errorf("schema drift %s: %w", path, err)
+	}
+	return rep, nil
+}
+
+// Reset forgets every finding kept in path.
+func Reset(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func save(path string, rep Report) error {
+	b, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+var (
+	versionSegment = regexp.MustCompile(`^v\d+$`)
+	hasDigit       = regexp.MustCompile(`\d`)
+)
+
+// EndpointName is method and path without the query, with segments that
+// look like ids or order codes (any digit, except version segments like
+// "v3") replaced by {id}.
+func EndpointName(method, path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segs {
+		if hasDigit.MatchString(s) && !versionSegment.MatchString(s) {
+			segs[i] = "{id}"
+		}
+	}
+	return strings.ToUpper(method) + " " + strings.Join(segs, "/")
+}
+
+func typeName(v any) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
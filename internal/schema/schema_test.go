@@ -0,0 +1,170 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+type flexible string
+
+func (f *flexible) UnmarshalJSON(b []byte) error { *f = flexible(b); return nil }
+
+type vendor struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type base struct {
+	ID int `json:"id"`
+}
+
+type item struct {
+	base
+	Vendor  *vendor           `json:"vendor"`
+	Total   float64           `json:"total_value"`
+	Status  flexible          `json:"status"`
+	Raw     json.RawMessage   `json:"raw,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Note    string            `json:"note,omitempty"`
+	Quoted  int               `json:"quoted,string,omitempty"`
+	Ignored string            `json:"-"`
+}
+
+type page struct {
+	Data struct {
+		Items []item `json:"items"`
+	} `json:"data"`
+}
+
+func TestDecode(t *testing.T) {
+	body := `{"data":{"items":[
+		{"id":1,"vendor":{"code":"V","name":"A","rating":4.5},"total_value":"12.30","status":17,"raw":{"x":1},"tags":{"a":1,"b":"x"},"quoted":"5","extra":{"deep":[1,{"x":2}]}},
+		{"id":2,"vendor":{"code":"W"},"total_value":3,"status":"x","Ignored":"y"},
+		{"id":3,"vendor":null,"total_value":1,"status":null}
+	],"next":null}}`
+	var got page
+	drift, err := Decode(strings.NewReader(body), &got)
+	var te *json.UnmarshalTypeError
+	if !errors.As(err, &te) || te.Field != "data.items[].total_value" {
+		t.Fatalf("want the first type mismatch as error, got %v", err)
+	}
+	want := []Drift{
+		{Kind: Unknown, Path: "data.items[].Ignored"},
+		{Kind: Unknown, Path: "data.items[].extra"},
+		{Kind: Mismatch, Path: "data.items[].tags.*", Want: "string", Got: "number"},
+		{Kind: Mismatch, Path: "data.items[].total_value", Want: "number", Got: "string"},
+		{Kind: Missing, Path: "data.items[].vendor.name"},
+		{Kind: Unknown, Path: "data.items[].vendor.rating"},
+		{Kind: Unknown, Path: "data.next"},
+	}
+	if !reflect.DeepEqual(drift, want) {
+		t.Fatalf("drift mismatch\n got: %+v\nwant: %+v", drift, want)
+	}
+
+	// Everything else decodes as json.Unmarshal would.
+	items := got.Data.Items
+	if len(items) != 3 || items[0].ID != 1 || items[0].Vendor.Name != "A" || items[0].Total != 0 || items[0].Status != "17" ||
+		string(items[0].Raw) != `{"x":1}` || items[0].Tags["b"] != "x" || items[0].Quoted != 5 ||
+		items[1].Total != 3 || items[1].Ignored != "" || items[2].Vendor != nil || items[2].Status != "null" {
+		t.Fatalf("decoded %+v", items)
+	}
+	var ref page
+	_ = json.Unmarshal([]byte(body), &ref)
+	if !reflect.DeepEqual(ref.Data.Items[1], items[1]) {
+		t.Fatalf("differs from encoding/json:\n got: %+v\nwant: %+v", items[1], ref.Data.Items[1])
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	var p page
+	if drift, err := Decode(strings.NewReader(`{"data":{"items":{"a":[1]}},"x":1}`), &p); err == nil || len(drift) != 2 || drift[0].Want != "array" || drift[0].Got != "object" {
+		t.Fatalf("want array mismatch and decoding to go on, got %+v, %v", drift, err)
+	}
+	if _, err := Decode(strings.NewReader(`<html>`), &p); err == nil {
+		t.Fatalf("expected error for non-JSON body")
+	}
+	if _, err := Decode(strings.NewReader(`{"data":{"items":[{"id":1`), &p); err == nil {
+		t.Fatalf("expected error for truncated body")
+	}
+	if _, err := Decode(strings.NewReader(`{} {}`), &p); err == nil {
+		t.Fatalf("expected error for trailing data")
+	}
+	readErr := errors.New("connection reset")
+	if _, err := Decode(io.MultiReader(strings.NewReader(`{"data":{"items":[{"id":`), iotest.ErrReader(readErr)), &p); !errors.Is(err, readErr) {
+		t.Fatalf("want read error, got %v", err)
+	}
+	if _, err := Decode(strings.NewReader(`{}`), p); err == nil {
+		t.Fatalf("expected error for non-pointer")
+	}
+}
+
+func TestEndpointName(t *testing.T) {
+	cases := map[[2]string]string{
+		{"get", "tracking/orders/x7yz-2ab3"}:      "GET tracking/orders/{id}",
+		{"GET", "/v3/customer/orders-list?x=1"}:   "GET v3/customer/orders-list",
+		{"POST", "orders/ab1c-d2ef/reorder"}:      "POST orders/{id}/reorder",
+		{"GET", "v3/customer/orders/123456789/x"}: "GET v3/customer/orders/{id}/x",
+	}
+	for in, want := range cases {
+		if got := EndpointName(in[0], in[1]); got != want {
+			t.Errorf("EndpointName(%q, %q) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
+
+func TestRecorder_PersistsAndCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "drift.json")
+	var log bytes.Buffer
+	observe := func(r *Recorder, path, body string) {
+		var p page
+		drift, _ := Decode(strings.NewReader(body), &p)
+		r.Record("foodora", "GET", path, &p, drift)
+	}
+	r := NewRecorder(path, &log)
+	observe(r, "tracking/orders/OC-1", `{"data":{"items":[{"id":1,"vendor":{"code":"V","name":"A"},"total_value":1,"status":1,"new":1}]}}`)
+	observe(r, "tracking/orders/OC-2", `{"data":{"items":[{"id":1,"vendor":{"code":"V","name":"A"},"total_value":1,"status":1,"new":1}]}}`)
+	observe(r, "tracking/orders/OC-3", `{"data":{"items":[]}}`)
+
+	if strings.Count(log.String(), "schema drift:") != 1 || !strings.Contains(log.String(), "foodora GET tracking/orders/{id}: 1 new finding(s)") {
+		t.Fatalf("log=%q", log.String())
+	}
+
+	// A new process picks up where the last one stopped.
+	r2 := NewRecorder(path, nil)
+	observe(r2, "tracking/orders/OC-4", `{"data":{"items":[{"id":"x","vendor":{"code":"V","name":"A"},"total_value":1,"status":1}]}}`)
+	rep, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(rep.Endpoints) != 1 {
+		t.Fatalf("endpoints=%+v", rep.Endpoints)
+	}
+	e := rep.Endpoints[0]
+	if e.Provider != "foodora" || e.Name != "GET tracking/orders/{id}" || e.Type != "schema.page" || len(e.Findings) != 2 {
+		t.Fatalf("endpoint=%+v", e)
+	}
+	// Mismatches sort before unknown fields.
+	if f := e.Findings[0]; f.Kind != Mismatch || f.Path != "data.items[].id" || f.Count != 1 {
+		t.Fatalf("first finding=%+v", f)
+	}
+	if f := e.Findings[1]; f.Kind != Unknown || f.Path != "data.items[].new" || f.Count != 2 || f.LastSeen.Before(f.FirstSeen) || time.Since(f.FirstSeen) > time.Minute {
+		t.Fatalf("second finding=%+v", f)
+	}
+
+	if err := Reset(path); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if rep, _ := Load(path); len(rep.Endpoints) != 0 {
+		t.Fatalf("reset kept %+v", rep)
+	}
+	var nilRecorder *Recorder
+	nilRecorder.Record("foodora", "GET", "x", &page{}, []Drift{{Kind: Unknown, Path: "a"}})
+}
@@ -0,0 +1,255 @@
+// Package server is the HTTP side of `ordercli serve`: it keeps the latest
+// active-order snapshot of every provider and exposes it as JSON endpoints
+// and a Server-Sent Events stream of status changes.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+const (
+	// DefaultAddr binds to loopback only; the API has no authentication.
+	DefaultAddr = "127.0.0.1:8787"
+	// DefaultHistory is how many recent transitions /api/events keeps.
+	DefaultHistory = 100
+
+	keepAlive = 15 * time.Second
+)
+
+type Options struct {
+	// History caps the recent-events buffer (default DefaultHistory).
+	History int
+	// OnEvent is called for every transition, e.g. to notify sinks.
+	OnEvent func(context.Context, poller.Event)
+	// OnSnapshot is called after every poll, before subscribers are told.
+	OnSnapshot func(poller.Snapshot)
+}
+
+// ProviderStatus is the latest poll of one provider.
+type ProviderStatus struct {
+	Provider string           `json:"provider"`
+	PolledAt time.Time        `json:"polled_at,omitzero"`
+	NextPoll time.Time        `json:"next_poll,omitzero"`
+	Error    string           `json:"error,omitempty"`
+	Orders   []provider.Order `json:"orders"`
+}
+
+// Status is the body of GET /api/orders.
+type Status struct {
+	Providers []ProviderStatus `json:"providers"`
+	Active    int              `json:"active"`
+}
+
+type Server struct {
+	p    *poller.Poller
+	opts Options
+
+	mu      sync.RWMutex
+	status  map[string]ProviderStatus
+	order   []string
+	tracker poller.Tracker
+	events  []poller.Event
+	subs    map[chan sseMessage]struct{}
+}
+
+type sseMessage struct {
+	event string
+	data  []byte
+}
+
+func New(p *poller.Poller, opts Options) *Server {
+	if opts.History <= 0 {
+		opts.History = DefaultHistory
+	}
+	s := &Server{
+		p:      p,
+		opts:   opts,
+		status: map[string]ProviderStatus{},
+		subs:   map[chan sseMessage]struct{}{},
+	}
+	for _, pr := range p.Providers {
+		s.order = append(s.order, pr.Name())
+		s.status[pr.Name()] = ProviderStatus{Provider: pr.Name(), Orders: []provider.Order{}}
+	}
+	return s
+}
+
+// Run polls until ctx is done.
+func (s *Server) Run(ctx context.Context) {
+	s.p.Run(ctx, func(snap poller.Snapshot) { s.observe(ctx, snap) })
+}
+
+func (s *Server) observe(ctx context.Context, snap poller.Snapshot) {
+	if s.opts.OnSnapshot != nil {
+		s.opts.OnSnapshot(snap)
+	}
+	st := ProviderStatus{
+		Provider: snap.Provider,
+		PolledAt: snap.At,
+		NextPoll: snap.At.Add(snap.Next),
+		Orders:   make([]provider.Order, len(snap.Orders)),
+	}
+	copy(st.Orders, snap.Orders)
+	for i := range st.Orders {
+		st.Orders[i].Raw = nil
+	}
+
+	s.mu.Lock()
+	var events []poller.Event
+	if snap.Err != nil {
+		// Keep the last good orders; a failed poll says nothing about them.
+		st.Error = snap.Err.Error()
+		st.Orders = s.status[snap.Provider].Orders
+	} else {
+		events = s.tracker.Update(snap.Provider, snap.Orders, snap.At)
+		s.events = append(s.events, events...)
+		if n := len(s.events) - s.opts.History; n > 0 {
+			s.events = append([]poller.Event(nil), s.events[n:]...)
+		}
+	}
+	s.status[snap.Provider] = st
+	s.mu.Unlock()
+
+	for _, ev := range events {
+		if s.opts.OnEvent != nil {
+			s.opts.OnEvent(ctx, ev)
+		}
+		s.broadcast("transition", ev)
+	}
+	s.broadcast("snapshot", st)
+}
+
+// Status returns the latest snapshot of every provider, in provider order.
+func (s *Server) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := Status{Providers: make([]ProviderStatus, 0, len(s.order))}
+	for _, name := range s.order {
+		st := s.status[name]
+		out.Providers = append(out.Providers, st)
+		for _, o := range st.Orders {
+			if !o.Delivered {
+				out.Active++
+			}
+		}
+	}
+	return out
+}
+
+// Events returns the recent transitions, oldest first.
+func (s *Server) Events() []poller.Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]poller.Event{}, s.events...)
+}
+
+func (s *Server) broadcast(event string, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for ch := range s.subs {
+		select {
+		case ch <- sseMessage{event: event, data: b}:
+		default: // slow client; it will catch up from the next snapshot
+		}
+	}
+}
+
+// Handler serves:
+//
+//	GET /healthz                   {"ok":true}
+//	GET /api/orders                every provider's latest snapshot
+//	GET /api/orders/{provider}     one provider's latest snapshot
+//	GET /api/events                recent transitions
+//	GET /api/events/stream         SSE: "snapshot" and "transition" events
+func (s *Server) Handler() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+	})
+	mux.HandleFunc("GET /api/orders", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.Status())
+	})
+	mux.HandleFunc("GET /api/orders/{provider}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		st, ok := s.status[r.PathValue("provider")]
+		s.mu.RUnlock()
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown provider " + r.PathValue("provider")})
+			return
+		}
+		writeJSON(w, http.StatusOK, st)
+	})
+	mux.HandleFunc("GET /api/events", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.Events())
+	})
+	mux.HandleFunc("GET /api/events/stream", s.serveSSE)
+	return mux
+}
+
+func (s *Server) serveSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	ch := make(chan sseMessage, 32)
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, ch)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Start every client from the current state.
+	for _, st := range s.Status().Providers {
+		b, _ := json.Marshal(st)
+		writeSSE(w, sseMessage{event: "snapshot", data: b})
+	}
+	flusher.Flush()
+
+	tick := time.NewTicker(keepAlive)
+	defer tick.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg := <-ch:
+			writeSSE(w, msg)
+			flusher.Flush()
+		case <-tick.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, msg sseMessage) {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.event, msg.data)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
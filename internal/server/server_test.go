@@ -0,0 +1,189 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/poller"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+type stepProvider struct {
+	name string
+	mu   sync.Mutex
+	// steps are returned in turn; the last one repeats.
+	steps []step
+}
+
+type step struct {
+	orders []provider.Order
+	err    error
+}
+
+func (p *stepProvider) Name() string                      { return p.name }
+func (p *stepProvider) Capabilities() provider.Capability { return provider.CapActiveOrders }
+func (p *stepProvider) History(context.Context, provider.HistoryRequest) (provider.HistoryPage, error) {
+	return provider.HistoryPage{}, provider.ErrUnsupported
+}
+
+func (p *stepProvider) ActiveOrders(context.Context) (provider.ActiveOrders, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.steps[0]
+	if len(p.steps) > 1 {
+		p.steps = p.steps[1:]
+	}
+	return provider.ActiveOrders{Orders: s.orders}, s.err
+}
+
+func (p *stepProvider) OrderDetail(context.Context, string) (provider.Order, error) {
+	return provider.Order{}, provider.ErrUnsupported
+}
+
+func (p *stepProvider) Me(context.Context) (provider.Account, error) {
+	return provider.Account{}, provider.ErrUnsupported
+}
+
+func newTestServer(opts Options, providers ...provider.Provider) *Server {
+	return New(&poller.Poller{Providers: providers, Interval: time.Millisecond, Min: time.Millisecond}, opts)
+}
+
+func getJSON(t *testing.T, h http.Handler, path string, out any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s: %v body=%s", path, err, rec.Body.String())
+		}
+	}
+	return rec.Code
+}
+
+func TestServer_Endpoints(t *testing.T) {
+	fd := &stepProvider{name: "foodora", steps: []step{
+		{orders: []provider.Order{{Provider: "foodora", ID: "OC-1", Status: "Cooking", Raw: json.RawMessage(`{}`)}}},
+		{orders: []provider.Order{{Provider: "foodora", ID: "OC-1", Status: "On the way"}}},
+	}}
+	gl := &stepProvider{name: "glovo", steps: []step{{err: errors.New("expired")}}}
+
+	var mu sync.Mutex
+	var notified []poller.Event
+	s := newTestServer(Options{History: 1, OnEvent: func(_ context.Context, ev poller.Event) {
+		mu.Lock()
+		notified = append(notified, ev)
+		mu.Unlock()
+	}}, fd, gl)
+	h := s.Handler()
+
+	var st Status
+	if getJSON(t, h, "/api/orders", &st) != http.StatusOK || len(st.Providers) != 2 || st.Active != 0 {
+		t.Fatalf("before polling: %+v", st)
+	}
+
+	now := time.Now()
+	s.observe(context.Background(), poller.Snapshot{Provider: "foodora", Orders: fd.steps[0].orders, At: now, Next: time.Minute})
+	s.observe(context.Background(), poller.Snapshot{Provider: "glovo", Err: errors.New("expired"), At: now})
+	s.observe(context.Background(), poller.Snapshot{Provider: "foodora", Orders: fd.steps[1].orders, At: now})
+
+	if getJSON(t, h, "/api/orders", &st) != http.StatusOK || st.Active != 1 {
+		t.Fatalf("status=%+v", st)
+	}
+	if st.Providers[0].Orders[0].Status != "On the way" || st.Providers[0].Orders[0].Raw != nil || st.Providers[1].Error != "expired" {
+		t.Fatalf("providers=%+v", st.Providers)
+	}
+
+	var one ProviderStatus
+	if getJSON(t, h, "/api/orders/glovo", &one) != http.StatusOK || one.Error != "expired" || len(one.Orders) != 0 {
+		t.Fatalf("glovo=%+v", one)
+	}
+	if code := getJSON(t, h, "/api/orders/nope", nil); code != http.StatusNotFound {
+		t.Fatalf("code=%d", code)
+	}
+
+	var events []poller.Event
+	if getJSON(t, h, "/api/events", &events); len(events) != 1 || events[0].To != "On the way" {
+		t.Fatalf("events=%+v (history is capped at 1)", events)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(notified) != 2 {
+		t.Fatalf("notified=%+v", notified)
+	}
+
+	var ok map[string]bool
+	if getJSON(t, h, "/healthz", &ok); !ok["ok"] {
+		t.Fatalf("healthz=%v", ok)
+	}
+}
+
+func TestServer_SSE(t *testing.T) {
+	fd := &stepProvider{name: "foodora", steps: []step{
+		{orders: []provider.Order{{Provider: "foodora", ID: "OC-1", Status: "Cooking"}}},
+		{orders: []provider.Order{{Provider: "foodora", ID: "OC-1", Status: "Delivered", Delivered: true}}},
+	}}
+	s := newTestServer(Options{}, fd)
+	hs := httptest.NewServer(s.Handler())
+	defer hs.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, hs.URL+"/api/events/stream", nil)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content-type=%q", ct)
+	}
+
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(res.Body)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+	next := func() string {
+		t.Helper()
+		for {
+			select {
+			case l, ok := <-lines:
+				if !ok {
+					t.Fatalf("stream closed")
+				}
+				if strings.HasPrefix(l, "event: ") {
+					data := <-lines
+					return strings.TrimPrefix(l, "event: ") + " " + strings.TrimPrefix(data, "data: ")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timeout waiting for event")
+			}
+		}
+	}
+
+	if got := next(); !strings.HasPrefix(got, `snapshot {"provider":"foodora"`) {
+		t.Fatalf("initial=%q", got)
+	}
+	go s.Run(ctx)
+
+	var transitions []string
+	for len(transitions) < 2 {
+		if got := next(); strings.HasPrefix(got, "transition ") {
+			transitions = append(transitions, got)
+		}
+	}
+	if !strings.Contains(transitions[0], `"to":"Cooking"`) || !strings.Contains(transitions[1], `"delivered":true`) {
+		t.Fatalf("transitions=%q", transitions)
+	}
+}
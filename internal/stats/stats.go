@@ -0,0 +1,202 @@
+// Package stats summarizes order spending per provider: totals and counts per
+// month or ISO week, average basket, and top vendors.
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+type Period string
+
+const (
+	Month Period = "month"
+	Week  Period = "week"
+)
+
+// ParsePeriod accepts "month"/"monthly" and "week"/"weekly".
+func ParsePeriod(s string) (Period, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "month", "monthly":
+		return Month, nil
+	case "week", "weekly":
+		return Week, nil
+	}
+	return "", fmt.Errorf("unknown period %q (use month or week)", s)
+}
+
+type Options struct {
+	Period Period
+	// Top is how many vendors to list per provider (default 5).
+	Top int
+	// Location buckets order times (default time.Local).
+	Location *time.Location
+}
+
+type Report struct {
+	Period    Period    `json:"period"`
+	Providers []Summary `json:"providers"`
+}
+
+// Summary covers one provider in one currency; a provider used in several
+// currencies gets one Summary each, since amounts cannot be added up.
+type Summary struct {
+	Provider string `json:"provider"`
+	Currency string `json:"currency,omitempty"`
+	Orders   int    `json:"orders"`
+	// Cancelled orders are counted here and left out of every total.
+	Cancelled int `json:"cancelled,omitempty"`
+	// Undated orders (Glovo reports no order date) are in the totals but
+	// in no period.
+	Undated       int      `json:"undated,omitempty"`
+	Total         float64  `json:"total"`
+	AverageBasket float64  `json:"average_basket"`
+	Periods       []Bucket `json:"periods"`
+	TopVendors    []Vendor `json:"top_vendors"`
+}
+
+type Bucket struct {
+	// Key is "2025-12" for months and "2025-W51" for ISO weeks.
+	Key    string  `json:"key"`
+	Orders int     `json:"orders"`
+	Total  float64 `json:"total"`
+}
+
+type Vendor struct {
+	Name   string  `json:"name"`
+	Orders int     `json:"orders"`
+	Total  float64 `json:"total"`
+}
+
+// Compute builds the report. Undated orders count towards the totals but not
+// towards any period; orders without a total still count as orders but are
+// left out of the average basket.
+func Compute(orders []provider.Order, opts Options) Report {
+	if opts.Period == "" {
+		opts.Period = Month
+	}
+	if opts.Top <= 0 {
+		opts.Top = 5
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	type acc struct {
+		sum     Summary
+		priced  int
+		buckets map[string]*Bucket
+		vendors map[string]*Vendor
+	}
+	groups := map[string]*acc{}
+	var order []string
+	for _, o := range orders {
+		k := o.Provider + "\x00" + o.Currency
+		g := groups[k]
+		if g == nil {
+			g = &acc{
+				sum:     Summary{Provider: o.Provider, Currency: o.Currency},
+				buckets: map[string]*Bucket{},
+				vendors: map[string]*Vendor{},
+			}
+			groups[k] = g
+			order = append(order, k)
+		}
+		if o.Cancelled() {
+			g.sum.Cancelled++
+			continue
+		}
+		g.sum.Orders++
+		g.sum.Total += o.Total
+		if o.Total != 0 {
+			g.priced++
+		}
+		if o.Time.IsZero() {
+			g.sum.Undated++
+		} else {
+			key := bucketKey(o.Time.In(loc), opts.Period)
+			b := g.buckets[key]
+			if b == nil {
+				b = &Bucket{Key: key}
+				g.buckets[key] = b
+			}
+			b.Orders++
+			b.Total += o.Total
+		}
+		if name := strings.TrimSpace(o.Vendor); name != "" {
+			v := g.vendors[name]
+			if v == nil {
+				v = &Vendor{Name: name}
+				g.vendors[name] = v
+			}
+			v.Orders++
+			v.Total += o.Total
+		}
+	}
+
+	slices.SortStableFunc(order, func(a, b string) int {
+		return cmp.Compare(providerRank(groups[a].sum.Provider), providerRank(groups[b].sum.Provider))
+	})
+
+	rep := Report{Period: opts.Period, Providers: []Summary{}}
+	for _, k := range order {
+		g := groups[k]
+		s := g.sum
+		if g.priced > 0 {
+			s.AverageBasket = round2(s.Total / float64(g.priced))
+		}
+		s.Total = round2(s.Total)
+
+		s.Periods = []Bucket{}
+		for _, b := range g.buckets {
+			b.Total = round2(b.Total)
+			s.Periods = append(s.Periods, *b)
+		}
+		// Newest period first, like history.
+		slices.SortFunc(s.Periods, func(a, b Bucket) int { return cmp.Compare(b.Key, a.Key) })
+
+		s.TopVendors = []Vendor{}
+		for _, v := range g.vendors {
+			v.Total = round2(v.Total)
+			s.TopVendors = append(s.TopVendors, *v)
+		}
+		slices.SortFunc(s.TopVendors, func(a, b Vendor) int {
+			if c := cmp.Compare(b.Total, a.Total); c != 0 {
+				return c
+			}
+			if c := cmp.Compare(b.Orders, a.Orders); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Name, b.Name)
+		})
+		if len(s.TopVendors) > opts.Top {
+			s.TopVendors = s.TopVendors[:opts.Top]
+		}
+		rep.Providers = append(rep.Providers, s)
+	}
+	return rep
+}
+
+func bucketKey(t time.Time, p Period) string {
+	if p == Week {
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", y, w)
+	}
+	return t.Format("2006-01")
+}
+
+func providerRank(name string) int {
+	if i := slices.Index(provider.Names, name); i >= 0 {
+		return i
+	}
+	return len(provider.Names)
+}
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func day(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 12, 0, 0, 0, time.UTC) }
+
+func sample() []provider.Order {
+	return []provider.Order{
+		{Provider: "glovo", ID: "g1", Vendor: "Burger", Total: 10, Currency: "EUR", Time: day(2025, 12, 1)},
+		{Provider: "foodora", ID: "f1", Vendor: "Pizza", Total: 20, Currency: "EUR", Time: day(2025, 11, 30)},
+		{Provider: "foodora", ID: "f2", Vendor: "Pizza", Total: 10.555, Currency: "EUR", Time: day(2025, 12, 2)},
+		{Provider: "foodora", ID: "f3", Vendor: "Sushi", Total: 25, Currency: "EUR", Time: day(2025, 12, 3)},
+		{Provider: "foodora", ID: "f4", Vendor: "Sushi", Total: 99, Currency: "EUR", Status: "Cancelled", Time: day(2025, 12, 4)},
+		{Provider: "foodora", ID: "f5", Vendor: "Kebab", Currency: "EUR"},
+		{Provider: "foodora", ID: "f6", Vendor: "Langos", Total: 3000, Currency: "HUF", Time: day(2025, 12, 5)},
+	}
+}
+
+func TestComputeMonthly(t *testing.T) {
+	rep := Compute(sample(), Options{Location: time.UTC, Top: 2})
+	if rep.Period != Month || len(rep.Providers) != 3 {
+		t.Fatalf("rep=%+v", rep)
+	}
+	fd := rep.Providers[0]
+	if fd.Provider != "foodora" || fd.Currency != "EUR" {
+		t.Fatalf("first group=%+v", fd)
+	}
+	if fd.Orders != 4 || fd.Cancelled != 1 || fd.Undated != 1 || fd.Total != 55.56 {
+		t.Fatalf("fd=%+v", fd)
+	}
+	// f5 has no total, so the average is over three orders.
+	if fd.AverageBasket != 18.52 {
+		t.Fatalf("avg=%v", fd.AverageBasket)
+	}
+	if len(fd.Periods) != 2 || fd.Periods[0].Key != "2025-12" || fd.Periods[0].Orders != 2 || fd.Periods[0].Total != 35.56 || fd.Periods[1].Key != "2025-11" {
+		t.Fatalf("periods=%+v", fd.Periods)
+	}
+	if len(fd.TopVendors) != 2 || fd.TopVendors[0].Name != "Pizza" || fd.TopVendors[0].Orders != 2 || fd.TopVendors[1].Name != "Sushi" {
+		t.Fatalf("vendors=%+v", fd.TopVendors)
+	}
+	if rep.Providers[1].Currency != "HUF" || rep.Providers[2].Provider != "glovo" {
+		t.Fatalf("groups=%+v", rep.Providers)
+	}
+}
+
+func TestComputeWeekly(t *testing.T) {
+	rep := Compute(sample(), Options{Period: Week, Location: time.UTC})
+	fd := rep.Providers[0]
+	// 2025-11-30 is a Sunday (W48); Dec 2 and 3 fall in W49.
+	if len(fd.Periods) != 2 || fd.Periods[0].Key != "2025-W49" || fd.Periods[1].Key != "2025-W48" {
+		t.Fatalf("periods=%+v", fd.Periods)
+	}
+}
+
+func TestComputeEmpty(t *testing.T) {
+	if rep := Compute(nil, Options{}); rep.Providers == nil || len(rep.Providers) != 0 {
+		t.Fatalf("rep=%+v", rep)
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	for in, want := range map[string]Period{"": Month, "Monthly": Month, "week": Week, "weekly": Week} {
+		if got, err := ParsePeriod(in); err != nil || got != want {
+			t.Fatalf("%q: got %q err=%v", in, got, err)
+		}
+	}
+	if _, err := ParsePeriod("year"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
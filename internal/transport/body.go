@@ -0,0 +1,56 @@
+package transport
+
+import (
+	"fmt"
+	"io"
+)
+
+// DefaultMaxBody caps a response body the clients decode.
+const DefaultMaxBody = 32 << 20
+
+// BodyTooLargeError is returned by a LimitBody reader once the body turned
+// out longer than Limit bytes.
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	if e.Limit >= 1<<20 && e.Limit%(1<<20) == 0 {
+		return fmt.Sprintf("response body larger than %d MiB", e.Limit>>20)
+	}
+	return fmt.Sprintf("response body larger than %d bytes", e.Limit)
+}
+
+// LimitBody reads up to limit bytes of r and then fails with a
+// *BodyTooLargeError if r has more, where io.LimitReader would end the body
+// early as if it were complete.
+func LimitBody(r io.Reader, limit int64) io.Reader {
+	return &limitedBody{r: r, left: limit, limit: limit}
+}
+
+type limitedBody struct {
+	r     io.Reader
+	left  int64
+	limit int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if l.left <= 0 {
+		// Tell a body of exactly limit bytes from a longer one.
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			return 0, &BodyTooLargeError{Limit: l.limit}
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.left {
+		p = p[:l.left]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	return n, err
+}
@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLimitBody(t *testing.T) {
+	b, err := io.ReadAll(LimitBody(strings.NewReader("12345"), 5))
+	if err != nil || string(b) != "12345" {
+		t.Fatalf("exact limit: %q, %v", b, err)
+	}
+
+	b, err = io.ReadAll(LimitBody(strings.NewReader("123456"), 5))
+	var tooLarge *BodyTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 5 || string(b) != "12345" {
+		t.Fatalf("over limit: %q, %v", b, err)
+	}
+	if got := (&BodyTooLargeError{Limit: DefaultMaxBody}).Error(); got != "response body larger than 32 MiB" {
+		t.Fatalf("message=%q", got)
+	}
+}
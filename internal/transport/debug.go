@@ -0,0 +1,183 @@
+package transport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mask replaces secrets in Debug traces.
+const Mask = "***"
+
+// DefaultDebugBody is how much of each body Debug prints.
+const DefaultDebugBody = 2048
+
+// maskedHeaders never have their value printed.
+var maskedHeaders = map[string]bool{
+	"Authorization":                      true,
+	"Proxy-Authorization":                true,
+	"Cookie":                             true,
+	"Set-Cookie":                         true,
+	"X-Mfa-Token":                        true,
+	"X-Otp":                              true,
+	"X-Goog-Api-Key":                     true,
+	"X-Goog-Firebase-Installations-Auth": true,
+}
+
+// secretParam matches credential parameters in form bodies, query strings
+// and JSON, also when a body was cut off mid-way. key (Firebase's API key)
+// only counts as a whole query or form parameter; as a JSON field name it
+// is too common.
+var (
+	secretNames = `(?:client_secret|refresh_token|access_token|id_token|mfa_token|password|otp)`
+	secretForm  = regexp.MustCompile(`(?i)(^|[?&])((?:key|` + secretNames + `)=)[^&\s]*`)
+	secretJSON  = regexp.MustCompile(`(?i)("` + secretNames + `"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+)
+
+// Debug writes every request and response it passes through to Out: method,
+// URL, headers and the first MaxBody bytes of each body, with credentials
+// masked. Bodies are passed on unchanged.
+type Debug struct {
+	// Next sends the request (default: http.DefaultTransport at call time).
+	Next http.RoundTripper
+	Out  io.Writer
+	// MaxBody caps the printed part of a body (default DefaultDebugBody).
+	MaxBody int
+
+	mu sync.Mutex
+}
+
+func (t *Debug) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	limit := t.MaxBody
+	if limit <= 0 {
+		limit = DefaultDebugBody
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "> %s %s\n", req.Method, MaskSecrets(req.URL.String()))
+	writeHeaders(&b, "> ", req.Header)
+	req, head, more, err := peekRequest(req, limit)
+	if err != nil {
+		return nil, err
+	}
+	writeBody(&b, "> ", head, more)
+	t.write(b.String())
+
+	start := time.Now()
+	res, err := next.RoundTrip(req)
+	b.Reset()
+	took := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(&b, "< %s %s failed after %s: %v\n", req.Method, req.URL.Host, took, err)
+		t.write(b.String())
+		return nil, err
+	}
+	fmt.Fprintf(&b, "< %s (%s)\n", res.Status, took)
+	writeHeaders(&b, "< ", res.Header)
+	head, more, err = peekPrefix(&res.Body, limit)
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	writeBody(&b, "< ", head, more)
+	t.write(b.String())
+	return res, nil
+}
+
+// write keeps the traces of concurrent requests from interleaving.
+func (t *Debug) write(s string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, _ = io.WriteString(t.Out, s)
+}
+
+// MaskSecrets masks credential parameters in a URL or body.
+func MaskSecrets(s string) string {
+	s = secretForm.ReplaceAllString(s, "${1}${2}"+Mask)
+	return secretJSON.ReplaceAllString(s, `${1}"`+Mask+`"`)
+}
+
+func writeHeaders(b *strings.Builder, prefix string, h http.Header) {
+	keys := make([]string, 0, len(h))
+	for k, vs := range h {
+		if vs != nil { // marker headers (MarkIdempotent) are never sent
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			if maskedHeaders[http.CanonicalHeaderKey(k)] {
+				v = Mask
+			}
+			fmt.Fprintf(b, "%s%s: %s\n", prefix, k, v)
+		}
+	}
+}
+
+func writeBody(b *strings.Builder, prefix string, head []byte, more bool) {
+	if len(head) == 0 {
+		return
+	}
+	b.WriteString(prefix + "\n")
+	for _, line := range strings.Split(strings.TrimRight(MaskSecrets(string(head)), "\n"), "\n") {
+		b.WriteString(prefix + line + "\n")
+	}
+	if more {
+		b.WriteString(prefix + "[truncated]\n")
+	}
+}
+
+// peekRequest reads up to limit bytes of the request body. A RoundTripper
+// must not modify the request, so the bytes come from GetBody when it is set
+// and otherwise from a clone, which is what has to be sent on.
+func peekRequest(req *http.Request, limit int) (*http.Request, []byte, bool, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil, false, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, nil, false, err
+		}
+		defer body.Close()
+		head, more, err := peekPrefix(&body, limit)
+		return req, head, more, err
+	}
+	clone := req.Clone(req.Context())
+	head, more, err := peekPrefix(&clone.Body, limit)
+	return clone, head, more, err
+}
+
+// peekPrefix reads up to limit bytes of *body and puts an equivalent reader
+// back, without buffering the rest.
+func peekPrefix(body *io.ReadCloser, limit int) ([]byte, bool, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, false, nil
+	}
+	buf := make([]byte, limit+1)
+	n, err := io.ReadFull(*body, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, false, err
+	}
+	buf = buf[:n]
+	rest := *body
+	*body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), rest), rest}
+	if n > limit {
+		return buf[:limit], true, nil
+	}
+	return buf, false, nil
+}
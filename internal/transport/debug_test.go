@@ -0,0 +1,130 @@
+package transport
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebug_TracesAndMasks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "grant_type=refresh_token&refresh_token=r3fr3sh&client_secret=s3cr3t" {
+			t.Errorf("body changed: %q", body)
+		}
+		w.Header().Set("Set-Cookie", "session=c00kie")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"access_token":"acc3ss","refresh_token":"r3fr3sh2","expires_in":3600,"pad":"`+strings.Repeat("x", 100)+`"}`)
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	c := &http.Client{Transport: &Debug{Out: &out, MaxBody: 90}}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/oauth2/token?client_secret=q5ecret&country=at", strings.NewReader("grant_type=refresh_token&refresh_token=r3fr3sh&client_secret=s3cr3t"))
+	req.Header.Set("Authorization", "Bearer t0ken")
+	req.Header.Set("Cookie", "cf_clearance=cl34r")
+	req.Header.Set("X-Mfa-Token", "mf4")
+	req.Header.Set("User-Agent", "ordercli-test")
+	MarkIdempotent(req)
+	res, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.HasSuffix(string(body), strings.Repeat("x", 100)+`"}`) {
+		t.Fatalf("response body changed: %q", body)
+	}
+
+	got := out.String()
+	for _, secret := range []string{"t0ken", "cl34r", "mf4", "s3cr3t", "q5ecret", "r3fr3sh", "acc3ss", "c00kie"} {
+		if strings.Contains(got, secret) {
+			t.Fatalf("%q leaked:\n%s", secret, got)
+		}
+	}
+	for _, want := range []string{
+		"> POST " + srv.URL + "/oauth2/token?client_secret=***&country=at\n",
+		"> Authorization: ***\n",
+		"> Cookie: ***\n",
+		"> X-Mfa-Token: ***\n",
+		"> User-Agent: ordercli-test\n",
+		"> grant_type=refresh_token&refresh_token=***&client_secret=***\n",
+		"< 200 OK (",
+		"< Set-Cookie: ***\n",
+		`< {"access_token":"***","refresh_token":"***","expires_in":3600`,
+		"< [truncated]\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "X-Idempotency-Key") {
+		t.Fatalf("marker header printed:\n%s", got)
+	}
+}
+
+func TestDebug_ReportsErrors(t *testing.T) {
+	var out bytes.Buffer
+	failing := roundTripFunc(func(*http.Request) (*http.Response, error) { return nil, io.ErrUnexpectedEOF })
+	c := &http.Client{Transport: &Debug{Next: failing, Out: &out}}
+	if _, err := c.Get("http://example.invalid/x"); err == nil {
+		t.Fatalf("expected error")
+	}
+	if !strings.Contains(out.String(), "> GET http://example.invalid/x\n") || !strings.Contains(out.String(), "< GET example.invalid failed after") {
+		t.Fatalf("out=%s", out.String())
+	}
+}
+
+func TestDebug_LeavesRequestAlone(t *testing.T) {
+	var sent []string
+	next := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		sent = append(sent, string(b))
+		return &http.Response{StatusCode: 204, Status: "204 No Content", Body: http.NoBody, Request: r}, nil
+	})
+	d := &Debug{Next: next, Out: io.Discard}
+
+	// With GetBody the body is peeked from a fresh copy and req goes on as is.
+	req, _ := http.NewRequest(http.MethodPost, "http://example.invalid/", strings.NewReader("a=1"))
+	body := req.Body
+	if _, err := d.RoundTrip(req); err != nil {
+		t.Fatalf("round trip: %v", err)
+	}
+	if req.Body != body {
+		t.Fatalf("request body replaced")
+	}
+
+	// Without GetBody a clone carries the peeked body.
+	req, _ = http.NewRequest(http.MethodPost, "http://example.invalid/", io.MultiReader(strings.NewReader("b=2")))
+	body = req.Body
+	res, err := d.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("round trip: %v", err)
+	}
+	if req.Body != body || res.Request == req {
+		t.Fatalf("request modified instead of cloned")
+	}
+	if strings.Join(sent, ",") != "a=1,b=2" {
+		t.Fatalf("sent=%q", sent)
+	}
+}
+
+func TestMaskSecrets(t *testing.T) {
+	for in, want := range map[string]string{
+		`{"refresh_token":"a\"b","x":1}`: `{"refresh_token":"***","x":1}`,
+		`{"access_token":"cut off`:       `{"access_token":"***"`,
+		"password=p&username=u":          "password=***&username=u",
+		"no secrets here":                "no secrets here",
+		"/v1/installations?key=AIza&x=1": "/v1/installations?key=***&x=1",
+		"a=1&key=AIza":                   "a=1&key=***",
+		"?monkey=1&api_key_id=2":         "?monkey=1&api_key_id=2",
+		`{"key":"not a credential"}`:     `{"key":"not a credential"}`,
+	} {
+		if got := MaskSecrets(in); got != want {
+			t.Fatalf("MaskSecrets(%q)=%q want %q", in, got, want)
+		}
+	}
+}
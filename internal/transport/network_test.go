@@ -0,0 +1,127 @@
+package transport
+
+import (
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTransport_Proxy(t *testing.T) {
+	var seen string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.URL.String()
+		_, _ = io.WriteString(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	u, err := ParseProxy(proxy.URL)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	c := &http.Client{Transport: NewTransport(Network{Proxy: u})}
+	res, err := c.Get("http://api.example.invalid/orders?x=1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "via proxy" || seen != "http://api.example.invalid/orders?x=1" {
+		t.Fatalf("body=%q seen=%q", body, seen)
+	}
+}
+
+func TestParseProxy(t *testing.T) {
+	for _, ok := range []string{"http://proxy:3128", "https://proxy", "socks5://u:p@127.0.0.1:1080", "socks5h://proxy:1080"} {
+		if _, err := ParseProxy(ok); err != nil {
+			t.Fatalf("%s: %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"ftp://proxy", "proxy:3128", "http://", "://"} {
+		if _, err := ParseProxy(bad); err == nil {
+			t.Fatalf("%s: expected error", bad)
+		}
+	}
+}
+
+func TestLoadRootCAs(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	if _, err := (&http.Client{Transport: NewTransport(Network{})}).Get(srv.URL); err == nil {
+		t.Fatalf("expected unknown authority error")
+	}
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caPath, pemBytes, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	pool, err := LoadRootCAs([]string{caPath})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	res, err := (&http.Client{Transport: NewTransport(Network{RootCAs: pool})}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get with CA: %v", err)
+	}
+	res.Body.Close()
+
+	junk := filepath.Join(dir, "junk.pem")
+	_ = os.WriteFile(junk, []byte("nope"), 0o600)
+	if _, err := LoadRootCAs([]string{junk}); err == nil || !strings.Contains(err.Error(), "no PEM") {
+		t.Fatalf("junk: %v", err)
+	}
+	if _, err := LoadRootCAs([]string{filepath.Join(dir, "missing.pem")}); err == nil {
+		t.Fatalf("expected missing file error")
+	}
+}
+
+func TestLocalAddr(t *testing.T) {
+	var remote string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote = r.RemoteAddr
+	}))
+	defer srv.Close()
+
+	addr, err := LocalAddr("127.0.0.1")
+	if err != nil {
+		t.Fatalf("ip: %v", err)
+	}
+	c := &http.Client{Transport: NewTransport(Network{LocalAddr: addr, ConnectTimeout: time.Second})}
+	res, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	res.Body.Close()
+	if host, _, _ := net.SplitHostPort(remote); host != "127.0.0.1" {
+		t.Fatalf("remote=%q", remote)
+	}
+
+	ifaces, _ := net.Interfaces()
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+		addr, err := LocalAddr(iface.Name)
+		if err != nil {
+			t.Fatalf("%s: %v", iface.Name, err)
+		}
+		if ip := addr.(*net.TCPAddr).IP; !ip.IsLoopback() {
+			t.Fatalf("%s: %v", iface.Name, ip)
+		}
+		break
+	}
+	if _, err := LocalAddr("no-such-iface0"); err == nil {
+		t.Fatalf("expected unknown interface error")
+	}
+}
@@ -0,0 +1,130 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate is a token bucket: Burst requests at once, refilled at PerSecond.
+// A zero Rate means unlimited.
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+// DefaultRate keeps paging and multi-account syncs well below what trips
+// bot protection while leaving single commands unaffected.
+var DefaultRate = Rate{PerSecond: 2, Burst: 6}
+
+// Limiter holds one token bucket per host. A single Limiter is meant to be
+// shared by every client of the process.
+type Limiter struct {
+	// RateFor returns the bucket settings of a host (default: DefaultRate
+	// for every host). It is consulted once per host.
+	RateFor func(host string) Rate
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+type bucket struct {
+	rate   Rate
+	tokens float64
+	last   time.Time
+}
+
+// Wait blocks until host may send one request and returns how long it
+// waited. It returns early with ctx's error.
+func (l *Limiter) Wait(ctx context.Context, host string) (time.Duration, error) {
+	host = strings.ToLower(host)
+	now := time.Now
+	if l.now != nil {
+		now = l.now
+	}
+
+	l.mu.Lock()
+	if l.buckets == nil {
+		l.buckets = map[string]*bucket{}
+	}
+	b := l.buckets[host]
+	if b == nil {
+		r := DefaultRate
+		if l.RateFor != nil {
+			r = l.RateFor(host)
+		}
+		b = &bucket{rate: r, tokens: float64(max(r.Burst, 1)), last: now()}
+		l.buckets[host] = b
+	}
+	if b.rate.PerSecond <= 0 {
+		l.mu.Unlock()
+		return 0, nil
+	}
+	t := now()
+	b.tokens = min(b.tokens+t.Sub(b.last).Seconds()*b.rate.PerSecond, float64(max(b.rate.Burst, 1)))
+	b.last = t
+	// Reserve the token now, even if it is not there yet, so concurrent
+	// callers queue up behind each other instead of all waking at once.
+	b.tokens--
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate.PerSecond * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return 0, nil
+	}
+	sleep := Sleep
+	if l.sleep != nil {
+		sleep = l.sleep
+	}
+	if err := sleep(ctx, wait); err != nil {
+		l.mu.Lock()
+		b.tokens++ // hand the reservation back
+		l.mu.Unlock()
+		return 0, err
+	}
+	return wait, nil
+}
+
+// RateLimited waits for the Limiter before every request.
+type RateLimited struct {
+	// Next sends the request (default: http.DefaultTransport at call time).
+	Next    http.RoundTripper
+	Limiter *Limiter
+	// OnDelay is called after a request had to wait.
+	OnDelay func(req *http.Request, waited time.Duration)
+}
+
+func (t *RateLimited) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	waited, err := t.Limiter.Wait(req.Context(), req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	if waited > 0 && t.OnDelay != nil {
+		t.OnDelay(req, waited)
+	}
+	return next.RoundTrip(req)
+}
+
+// Sleep waits d or until ctx is done, returning ctx.Err() in that case.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
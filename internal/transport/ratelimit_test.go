@@ -0,0 +1,114 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock advances when the limiter sleeps.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) sleep(_ context.Context, d time.Duration) error {
+	c.t = c.t.Add(d)
+	return nil
+}
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	clk := &fakeClock{t: time.Unix(0, 0)}
+	l := &Limiter{
+		RateFor: func(host string) Rate {
+			if host == "free.example" {
+				return Rate{}
+			}
+			return Rate{PerSecond: 2, Burst: 3}
+		},
+		now:   clk.now,
+		sleep: clk.sleep,
+	}
+	ctx := context.Background()
+	var waits []time.Duration
+	for range 5 {
+		w, err := l.Wait(ctx, "API.example")
+		if err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+		waits = append(waits, w)
+	}
+	// Burst of 3, then one token every 500ms.
+	want := []time.Duration{0, 0, 0, 500 * time.Millisecond, 500 * time.Millisecond}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Fatalf("waits=%v want %v", waits, want)
+		}
+	}
+	// Idle time refills up to the burst.
+	clk.t = clk.t.Add(time.Minute)
+	for range 3 {
+		if w, _ := l.Wait(ctx, "api.example"); w != 0 {
+			t.Fatalf("refill: waited %v", w)
+		}
+	}
+	// Hosts have separate buckets; a zero Rate is unlimited.
+	for range 10 {
+		if w, _ := l.Wait(ctx, "free.example"); w != 0 {
+			t.Fatalf("unlimited host waited %v", w)
+		}
+	}
+	if w, _ := l.Wait(ctx, "other.example"); w != 0 {
+		t.Fatalf("new host waited %v", w)
+	}
+}
+
+func TestLimiter_CancelReturnsReservation(t *testing.T) {
+	l := &Limiter{RateFor: func(string) Rate { return Rate{PerSecond: 1, Burst: 1} }}
+	ctx := context.Background()
+	if w, err := l.Wait(ctx, "h"); w != 0 || err != nil {
+		t.Fatalf("first: %v %v", w, err)
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := l.Wait(cctx, "h"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err=%v", err)
+	}
+	if tokens := l.buckets["h"].tokens; tokens > 0.1 || tokens < -0.1 {
+		t.Fatalf("reservation not returned: %v", tokens)
+	}
+}
+
+func TestRateLimited_ReportsDelay(t *testing.T) {
+	clk := &fakeClock{t: time.Unix(0, 0)}
+	var sent atomic.Int32
+	rt := &RateLimited{
+		Next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			sent.Add(1)
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+		Limiter: &Limiter{now: clk.now, sleep: clk.sleep},
+	}
+	var delays []time.Duration
+	rt.OnDelay = func(req *http.Request, d time.Duration) { delays = append(delays, d) }
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example/x", nil)
+	for range DefaultRate.Burst + 1 {
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Fatalf("roundtrip: %v", err)
+		}
+	}
+	if int(sent.Load()) != DefaultRate.Burst+1 || len(delays) != 1 || delays[0] != 500*time.Millisecond {
+		t.Fatalf("sent=%d delays=%v", sent.Load(), delays)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rt.Limiter = &Limiter{RateFor: func(string) Rate { return Rate{PerSecond: 1, Burst: 1} }}
+	req = req.WithContext(ctx)
+	_, _ = rt.RoundTrip(req)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err=%v", err)
+	}
+}
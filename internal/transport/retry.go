@@ -0,0 +1,188 @@
+// Package transport holds the http.RoundTripper layers shared by every
+// provider client.
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy controls retries. Zero fields take the DefaultPolicy values.
+type Policy struct {
+	// MaxAttempts counts the first try; 1 disables retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles per retry.
+	BaseDelay time.Duration
+	// MaxDelay caps a single wait. A Retry-After asking for longer is not
+	// waited for; the response is returned as is.
+	MaxDelay time.Duration
+}
+
+var DefaultPolicy = Policy{MaxAttempts: 3, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second}
+
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultPolicy.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultPolicy.MaxDelay
+	}
+	return p
+}
+
+// Retry retries idempotent requests that failed with a network error, 429 or
+// a 5xx (except 501), with jittered exponential backoff. A Retry-After header
+// on 429/503 replaces the backoff.
+//
+// Only GET, HEAD, OPTIONS and TRACE are retried, plus requests carrying an
+// Idempotency-Key or X-Idempotency-Key header (see MarkIdempotent), the same
+// rule net/http uses. Everything else, e.g. placing a reorder, is sent once.
+type Retry struct {
+	// Next sends each attempt (default: http.DefaultTransport at call time).
+	Next   http.RoundTripper
+	Policy Policy
+	// OnRetry is called before waiting for attempt+1.
+	OnRetry func(req *http.Request, attempt int, wait time.Duration, reason string)
+
+	sleep  func(context.Context, time.Duration) error
+	jitter func() float64
+}
+
+// MarkIdempotent flags a request with a body (e.g. a read-only POST) as safe
+// to retry. The marker header is not sent on the wire.
+func MarkIdempotent(req *http.Request) {
+	req.Header["X-Idempotency-Key"] = nil
+}
+
+// Idempotent reports whether req may be sent more than once.
+func Idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	if _, ok := req.Header["Idempotency-Key"]; ok {
+		return true
+	}
+	_, ok := req.Header["X-Idempotency-Key"]
+	return ok
+}
+
+func (t *Retry) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	pol := t.Policy.withDefaults()
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if pol.MaxAttempts == 1 || !Idempotent(req) || !replayable {
+		return next.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 {
+			r = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+		res, err := next.RoundTrip(r)
+		if attempt >= pol.MaxAttempts || req.Context().Err() != nil {
+			return res, err
+		}
+		wait, reason, retry := t.decide(pol, attempt, res, err)
+		if !retry {
+			return res, err
+		}
+		if res != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			res.Body.Close()
+		}
+		if t.OnRetry != nil {
+			t.OnRetry(req, attempt, wait, reason)
+		}
+		if err := t.wait(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// decide returns how long to wait before the next attempt, and why.
+func (t *Retry) decide(pol Policy, attempt int, res *http.Response, err error) (time.Duration, string, bool) {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return 0, "", false
+		}
+		return t.backoff(pol, attempt), err.Error(), true
+	}
+	if !RetryableStatus(res.StatusCode) {
+		return 0, "", false
+	}
+	reason := "HTTP " + strconv.Itoa(res.StatusCode)
+	if d, ok := RetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+		if d > pol.MaxDelay {
+			return 0, "", false
+		}
+		return d, reason, true
+	}
+	return t.backoff(pol, attempt), reason, true
+}
+
+// RetryableStatus is true for 429 and 5xx except 501 Not Implemented.
+func RetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || (code >= 500 && code != http.StatusNotImplemented)
+}
+
+// backoff is BaseDelay*2^(attempt-1), capped at MaxDelay, scaled by a random
+// factor in [0.5, 1) so concurrent clients spread out.
+func (t *Retry) backoff(pol Policy, attempt int) time.Duration {
+	d := pol.BaseDelay
+	for i := 1; i < attempt && d < pol.MaxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, pol.MaxDelay)
+	j := rand.Float64
+	if t.jitter != nil {
+		j = t.jitter
+	}
+	return time.Duration(float64(d) * (0.5 + j()/2))
+}
+
+func (t *Retry) wait(ctx context.Context, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(ctx, d)
+	}
+	return Sleep(ctx, d)
+}
+
+// RetryAfter parses a Retry-After value: delay seconds or an HTTP date.
+func RetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(v); err == nil {
+		if n < 0 {
+			return 0, false
+		}
+		return time.Duration(n) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return max(t.Sub(now), 0), true
+}
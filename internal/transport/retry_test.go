@@ -0,0 +1,194 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type waits struct{ got []time.Duration }
+
+func (w *waits) sleep(_ context.Context, d time.Duration) error {
+	w.got = append(w.got, d)
+	return nil
+}
+
+func newRetry(w *waits, pol Policy) *Retry {
+	return &Retry{Policy: pol, sleep: w.sleep, jitter: func() float64 { return 1 }}
+}
+
+func TestRetry_RetriesGETOnServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = io.WriteString(w, "ok")
+		}
+	}))
+	defer srv.Close()
+
+	w := &waits{}
+	rt := newRetry(w, Policy{MaxAttempts: 4, BaseDelay: 100 * time.Millisecond})
+	var reasons []string
+	rt.OnRetry = func(_ *http.Request, attempt int, _ time.Duration, reason string) {
+		reasons = append(reasons, reason)
+	}
+	res, err := (&http.Client{Transport: rt}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "ok" || calls.Load() != 3 {
+		t.Fatalf("body=%q calls=%d", body, calls.Load())
+	}
+	// Backoff (jitter factor 1) for the 502, Retry-After for the 429.
+	if len(w.got) != 2 || w.got[0] != 100*time.Millisecond || w.got[1] != 2*time.Second {
+		t.Fatalf("waits=%v", w.got)
+	}
+	if strings.Join(reasons, ",") != "HTTP 502,HTTP 429" {
+		t.Fatalf("reasons=%v", reasons)
+	}
+}
+
+func TestRetry_NeverRetriesMutatingRequests(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if _, ok := r.Header["X-Idempotency-Key"]; ok {
+			t.Errorf("idempotency marker leaked onto the wire")
+		}
+		b, _ := io.ReadAll(r.Body)
+		if string(b) != `{"q":1}` {
+			t.Errorf("body=%q", b)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	w := &waits{}
+	c := &http.Client{Transport: newRetry(w, Policy{})}
+	res, err := c.Post(srv.URL, "application/json", strings.NewReader(`{"q":1}`))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	res.Body.Close()
+	if calls.Load() != 1 || res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("POST retried: calls=%d", calls.Load())
+	}
+
+	// A read-only POST can opt in; its body is replayed.
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"q":1}`))
+	MarkIdempotent(req)
+	res, err = c.Do(req)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	res.Body.Close()
+	if int(calls.Load()) != 1+DefaultPolicy.MaxAttempts {
+		t.Fatalf("idempotent POST calls=%d", calls.Load())
+	}
+	if len(w.got) != 2 || w.got[1] != 2*DefaultPolicy.BaseDelay {
+		t.Fatalf("waits=%v", w.got)
+	}
+}
+
+func TestRetry_NetworkErrorsAndLimits(t *testing.T) {
+	var calls atomic.Int32
+	fail := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return nil, errors.New("connection reset")
+	})
+	w := &waits{}
+	rt := newRetry(w, Policy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 1500 * time.Millisecond})
+	rt.Next = fail
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	if _, err := rt.RoundTrip(req); err == nil || calls.Load() != 3 {
+		t.Fatalf("err=%v calls=%d", err, calls.Load())
+	}
+	if w.got[1] != 1500*time.Millisecond {
+		t.Fatalf("backoff not capped: %v", w.got)
+	}
+
+	// Retry-After beyond MaxDelay returns the response instead of waiting.
+	calls.Store(0)
+	rt.Next = roundTripFunc(func(*http.Request) (*http.Response, error) {
+		calls.Add(1)
+		h := http.Header{"Retry-After": []string{"3600"}}
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Header: h, Body: http.NoBody}, nil
+	})
+	res, err := rt.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Fatalf("res=%v err=%v calls=%d", res, err, calls.Load())
+	}
+
+	// 4xx and 501 are final; MaxAttempts 1 disables retries.
+	for _, code := range []int{http.StatusForbidden, http.StatusNotImplemented} {
+		calls.Store(0)
+		rt.Next = roundTripFunc(func(*http.Request) (*http.Response, error) {
+			calls.Add(1)
+			return &http.Response{StatusCode: code, Body: http.NoBody}, nil
+		})
+		if _, _ = rt.RoundTrip(req); calls.Load() != 1 {
+			t.Fatalf("%d retried", code)
+		}
+	}
+	rt.Policy.MaxAttempts = 1
+	rt.Next = fail
+	calls.Store(0)
+	if _, _ = rt.RoundTrip(req); calls.Load() != 1 {
+		t.Fatalf("MaxAttempts=1 retried")
+	}
+}
+
+func TestRetry_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rt := &Retry{Next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+	})}
+	rt.OnRetry = func(*http.Request, int, time.Duration, string) { cancel() }
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.invalid", nil)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err=%v", err)
+	}
+
+	rt.Next = roundTripFunc(func(*http.Request) (*http.Response, error) { return nil, context.DeadlineExceeded })
+	rt.OnRetry = func(*http.Request, int, time.Duration, string) { t.Fatalf("deadline retried") }
+	req, _ = http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Duration{
+		"5":                             5 * time.Second,
+		"Thu, 01 Jan 2026 12:00:30 GMT": 30 * time.Second,
+		"Thu, 01 Jan 2026 11:00:00 GMT": 0,
+	} {
+		if got, ok := RetryAfter(in, now); !ok || got != want {
+			t.Fatalf("%q: %v %v", in, got, ok)
+		}
+	}
+	for _, in := range []string{"", "-1", "soon"} {
+		if _, ok := RetryAfter(in, now); ok {
+			t.Fatalf("%q should not parse", in)
+		}
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
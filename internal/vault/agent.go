@@ -0,0 +1,132 @@
+package vault
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SocketPath is where the agent listens: $ORDERCLI_VAULT_SOCK, else a
+// per-user directory under $XDG_RUNTIME_DIR or the temp dir.
+func SocketPath() string {
+	if p := strings.TrimSpace(os.Getenv("ORDERCLI_VAULT_SOCK")); p != "" {
+		return p
+	}
+	if d := strings.TrimSpace(os.Getenv("XDG_RUNTIME_DIR")); d != "" {
+		return filepath.Join(d, "ordercli", "agent.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("ordercli-%d", os.Getuid()), "agent.sock")
+}
+
+// Listen opens the agent socket in a directory only the user can enter,
+// replacing a stale socket left by an agent that died.
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return net.Listen("unix", path)
+}
+
+// Serve hands key out to clients asking for vault id until ttl has passed,
+// ctx is done or a client sends stop. It closes ln.
+//
+// The protocol is one line per connection: "key <id>" answered by
+// "ok <base64 key>" or "err <reason>", and "stop" answered by "ok".
+func Serve(ctx context.Context, ln net.Listener, id string, key []byte, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, ttl)
+	defer cancel()
+	var once sync.Once
+	closeLn := func() { once.Do(func() { _ = ln.Close() }) }
+	go func() {
+		<-ctx.Done()
+		closeLn()
+	}()
+	defer closeLn()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if stop := serveConn(conn, id, key); stop {
+			cancel()
+		}
+	}
+}
+
+func serveConn(conn net.Conn, id string, key []byte) (stop bool) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	cmd, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	switch cmd {
+	case "key":
+		if arg != id {
+			fmt.Fprintf(conn, "err key is for another vault\n")
+			return false
+		}
+		fmt.Fprintf(conn, "ok %s\n", base64.StdEncoding.EncodeToString(key))
+	case "stop":
+		fmt.Fprintf(conn, "ok\n")
+		return true
+	default:
+		fmt.Fprintf(conn, "err unknown command\n")
+	}
+	return false
+}
+
+// AgentKey asks the agent at socket for the key of vault id.
+func AgentKey(socket, id string) ([]byte, error) {
+	reply, err := ask(socket, "key "+id)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(reply)
+}
+
+// StopAgent makes the agent at socket forget its key and exit.
+func StopAgent(socket string) error {
+	_, err := ask(socket, "stop")
+	return err
+}
+
+func ask(socket, req string) (string, error) {
+	conn, err := net.DialTimeout("unix", socket, time.Second)
+	if err != nil {
+		return "", fmt.Errorf("vault agent: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := fmt.Fprintf(conn, "%s\n", req); err != nil {
+		return "", fmt.Errorf("vault agent: %w", err)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("vault agent: %w", err)
+	}
+	status, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+	if status != "ok" {
+		return "", fmt.Errorf("vault agent: %s", rest)
+	}
+	return rest, nil
+}
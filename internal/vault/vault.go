@@ -0,0 +1,122 @@
+// Package vault encrypts config secrets with a key derived from a
+// passphrase (scrypt, AES-256-GCM) and caches that key in a short-lived
+// agent process so not every command has to ask for it.
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDF is the only key derivation function so far.
+const KDF = "scrypt"
+
+// Default scrypt cost: 32 MiB and well under a second per derivation.
+const (
+	DefaultN = 1 << 15
+	DefaultR = 8
+	DefaultP = 1
+)
+
+// prefix marks a sealed value; the version allows another scheme later.
+const prefix = "vault:v1:"
+
+var ErrWrongKey = errors.New("wrong passphrase")
+
+// Params are the key derivation settings stored next to the sealed values.
+type Params struct {
+	KDF  string `json:"kdf"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+// NewParams returns the default settings with a fresh salt.
+func NewParams() (Params, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return Params{}, err
+	}
+	return Params{KDF: KDF, N: DefaultN, R: DefaultR, P: DefaultP, Salt: salt}, nil
+}
+
+// DeriveKey turns a passphrase into a 32-byte key.
+func (p Params) DeriveKey(passphrase string) ([]byte, error) {
+	if p.KDF != KDF {
+		return nil, fmt.Errorf("vault: unsupported kdf %q", p.KDF)
+	}
+	// The parameters come from a file; refuse ones that would take
+	// gigabytes or hours.
+	if p.N < 2 || p.N > 1<<20 || p.R < 1 || p.P < 1 || p.R*p.P > 64 || len(p.Salt) < 8 {
+		return nil, fmt.Errorf("vault: bad scrypt parameters (n=%d r=%d p=%d)", p.N, p.R, p.P)
+	}
+	return scrypt.Key([]byte(passphrase), p.Salt, p.N, p.R, p.P, 32)
+}
+
+// ID names the vault the params belong to without revealing anything; the
+// agent only hands out its key for a matching ID.
+func (p Params) ID() string {
+	sum := sha256.Sum256(p.Salt)
+	return hex.EncodeToString(sum[:8])
+}
+
+// IsSealed reports whether s is a value produced by Seal.
+func IsSealed(s string) bool { return strings.HasPrefix(s, prefix) }
+
+// Seal encrypts plaintext. name is bound to the result as additional data,
+// so a value only opens under the same name (a refresh token cannot be
+// moved into the access token field).
+func Seal(key []byte, name, plaintext string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	out := aead.Seal(nonce, nonce, []byte(plaintext), []byte(name))
+	return prefix + base64.RawStdEncoding.EncodeToString(out), nil
+}
+
+// Open decrypts a value produced by Seal under the same name; a wrong key
+// or a tampered value is ErrWrongKey.
+func Open(key []byte, name, sealed string) (string, error) {
+	if !IsSealed(sealed) {
+		return "", errors.New("vault: value is not sealed")
+	}
+	b, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(sealed, prefix))
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	if len(b) < aead.NonceSize() {
+		return "", errors.New("vault: sealed value too short")
+	}
+	out, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return "", ErrWrongKey
+	}
+	return string(out), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,98 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSealOpen(t *testing.T) {
+	p, err := NewParams()
+	if err != nil {
+		t.Fatalf("params: %v", err)
+	}
+	p.N = 1 << 10 // keep the test fast
+	key, err := p.DeriveKey("correct horse")
+	if err != nil {
+		t.Fatalf("derive: %v", err)
+	}
+
+	sealed, err := Seal(key, "providers.foodora.access_token", "tok")
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "tok") {
+		t.Fatalf("sealed=%q", sealed)
+	}
+	if again, _ := Seal(key, "providers.foodora.access_token", "tok"); again == sealed {
+		t.Fatalf("expected a fresh nonce per seal")
+	}
+	if got, err := Open(key, "providers.foodora.access_token", sealed); err != nil || got != "tok" {
+		t.Fatalf("open: %q, %v", got, err)
+	}
+	if _, err := Open(key, "providers.foodora.refresh_token", sealed); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("other name: %v", err)
+	}
+	other, _ := p.DeriveKey("wrong")
+	if _, err := Open(other, "providers.foodora.access_token", sealed); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("other key: %v", err)
+	}
+	if _, err := Open(key, "x", "vault:v1:AAAA"); err == nil {
+		t.Fatalf("expected error for a short value")
+	}
+
+	same, _ := p.DeriveKey("correct horse")
+	if !bytes.Equal(key, same) || p.ID() == "" {
+		t.Fatalf("derivation not deterministic")
+	}
+	p.N = 1 << 30
+	if _, err := p.DeriveKey("x"); err == nil {
+		t.Fatalf("expected refusal of huge N")
+	}
+	p.KDF = "argon2id"
+	if _, err := p.DeriveKey("x"); err == nil {
+		t.Fatalf("expected unsupported kdf")
+	}
+}
+
+func TestAgent(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	ln, err := Listen(sock)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- Serve(context.Background(), ln, "id1", []byte("k3y"), time.Minute) }()
+
+	if key, err := AgentKey(sock, "id1"); err != nil || string(key) != "k3y" {
+		t.Fatalf("key: %q, %v", key, err)
+	}
+	if _, err := AgentKey(sock, "id2"); err == nil || !strings.Contains(err.Error(), "another vault") {
+		t.Fatalf("other vault: %v", err)
+	}
+	if err := StopAgent(sock); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+	if _, err := AgentKey(sock, "id1"); err == nil {
+		t.Fatalf("expected no agent after stop")
+	}
+
+	// The key is gone once the TTL has passed.
+	ln, err = Listen(sock)
+	if err != nil {
+		t.Fatalf("listen again: %v", err)
+	}
+	if err := Serve(context.Background(), ln, "id1", []byte("k3y"), 50*time.Millisecond); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+	if _, err := AgentKey(sock, "id1"); err == nil {
+		t.Fatalf("expected expired agent")
+	}
+}